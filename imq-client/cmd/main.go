@@ -2,10 +2,10 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
-	"os"
-	"strconv"
 
+	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/admin"
 	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/publisher"
 	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/subscriber"
 	"github.com/WinnersonKharsunai/GraduationProject/client/config"
@@ -24,11 +24,6 @@ func main() {
 		log.Fatalf("main: failed to get configs: %v", err)
 	}
 
-	port := getDailerEndpoint()
-	if port > 0 {
-		cfgs.ImqClientDailerPort = port
-	}
-
 	log.Infof("main: dailing for server connection")
 	clientSvc, addr, err := connectToServer(cfgs)
 	if err != nil {
@@ -36,7 +31,7 @@ func main() {
 	}
 	log.Infof("main: connected to server")
 
-	consoleSvc := initializeConsole(clientSvc, cfgs.ImqClientDailerPort)
+	consoleSvc := initializeConsole(clientSvc)
 
 	err = consoleSvc.Start(context.Background())
 	if err != nil {
@@ -46,14 +41,6 @@ func main() {
 	gracefulShutdown(log, addr, consoleSvc)
 }
 
-func getDailerEndpoint() int {
-	if len(os.Args) > 1 {
-		port, _ := strconv.Atoi(os.Args[1])
-		return port
-	}
-	return 0
-}
-
 func initializeLogger() *logrus.Logger {
 	log := logrus.New()
 	log.SetFormatter(&logrus.JSONFormatter{})
@@ -71,18 +58,33 @@ func loadConfigurations() (config.Settings, error) {
 func connectToServer(cfgs config.Settings) (client.Service, string, error) {
 	addr := fmt.Sprintf("%s:%d", cfgs.ImqClientHost, cfgs.ImqClientPort)
 
-	c := client.NewClient(addr, cfgs.ImqClientDailerHost, cfgs.ImqClientDailerPort)
+	var tlsConfig *tls.Config
+	if cfgs.ImqClientTLS {
+		serverName := cfgs.ImqClientTLSServerName
+		if serverName == "" {
+			serverName = cfgs.ImqClientHost
+		}
+
+		cfg, err := client.NewTLSConfig(cfgs.ImqClientTLSCAFile, cfgs.ImqClientTLSCertFile, cfgs.ImqClientTLSKeyFile, serverName)
+		if err != nil {
+			return nil, addr, err
+		}
+		tlsConfig = cfg
+	}
+
+	c := client.NewClient(addr, cfgs.ImqClientDailerHost, cfgs.ImqClientDailerPort, cfgs.ImqClientAPIKey, tlsConfig)
 	if err := c.Dial(); err != nil {
 		return nil, addr, err
 	}
 	return c, addr, nil
 }
 
-func initializeConsole(clientSvc client.Service, clientID int) *console.Console {
+func initializeConsole(clientSvc client.Service) *console.Console {
 	messagefactorySvc := messagefactory.NewMessageFactory()
 	publisherSvc := publisher.NewPublisher(clientSvc, messagefactorySvc)
 	subscriberSvc := subscriber.NewSubscriber(clientSvc, messagefactorySvc)
-	return console.NewConsole(clientID, clientSvc, publisherSvc, subscriberSvc)
+	adminSvc := admin.NewAdmin(clientSvc, messagefactorySvc)
+	return console.NewConsole(clientSvc.GetID(), clientSvc, publisherSvc, subscriberSvc, adminSvc)
 }
 
 func gracefulShutdown(log *logrus.Logger, addr string, consoleSvc *console.Console) {
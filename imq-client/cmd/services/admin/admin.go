@@ -0,0 +1,337 @@
+package admin
+
+import (
+	"context"
+
+	messagefactory "github.com/WinnersonKharsunai/GraduationProject/client/message-factory"
+	"github.com/WinnersonKharsunai/GraduationProject/client/pkg/client"
+	"github.com/WinnersonKharsunai/GraduationProject/client/pkg/protocol"
+)
+
+// Admin is the concrete implementation for the Admin
+type Admin struct {
+	client  client.Service
+	factory messagefactory.MessagefactoryIF
+}
+
+// Service is the interface for the Admin service
+type Service interface {
+	ListDeadMessages(ctx context.Context, in *ListDeadMessagesRequest) (*ListDeadMessagesResponse, error)
+	PurgeDeadMessages(ctx context.Context, in *PurgeDeadMessagesRequest) (*PurgeDeadMessagesResponse, error)
+	ReplayDeadMessages(ctx context.Context, in *ReplayDeadMessagesRequest) (*ReplayDeadMessagesResponse, error)
+	CreateTopic(ctx context.Context, in *CreateTopicRequest) (*CreateTopicResponse, error)
+	UpdateTopicTTL(ctx context.Context, in *UpdateTopicTTLRequest) (*UpdateTopicTTLResponse, error)
+	UpdateTopicLimits(ctx context.Context, in *UpdateTopicLimitsRequest) (*UpdateTopicLimitsResponse, error)
+	RenameTopic(ctx context.Context, in *RenameTopicRequest) (*RenameTopicResponse, error)
+	DeleteTopic(ctx context.Context, in *DeleteTopicRequest) (*DeleteTopicResponse, error)
+	DescribeTopic(ctx context.Context, in *DescribeTopicRequest) (*DescribeTopicResponse, error)
+	ListConsumerGroups(ctx context.Context, in *ListConsumerGroupsRequest) (*ListConsumerGroupsResponse, error)
+}
+
+// NewAdmin is the factory function for the Admin type
+func NewAdmin(client client.Service, factory messagefactory.MessagefactoryIF) Service {
+	return &Admin{
+		client:  client,
+		factory: factory,
+	}
+}
+
+// ListDeadMessages fetch all the messages in the dead letter queue of a given topic
+func (a *Admin) ListDeadMessages(ctx context.Context, in *ListDeadMessagesRequest) (*ListDeadMessagesResponse, error) {
+
+	var listDeadMessagesResponse *ListDeadMessagesResponse
+
+	hdr := protocol.SetHeader(version, contentType, listDeadMessages, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &listDeadMessagesResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return listDeadMessagesResponse, nil
+}
+
+// PurgeDeadMessages drops messages from the dead letter queue of a given topic
+func (a *Admin) PurgeDeadMessages(ctx context.Context, in *PurgeDeadMessagesRequest) (*PurgeDeadMessagesResponse, error) {
+
+	var purgeDeadMessagesResponse *PurgeDeadMessagesResponse
+
+	hdr := protocol.SetHeader(version, contentType, purgeDeadMessages, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &purgeDeadMessagesResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return purgeDeadMessagesResponse, nil
+}
+
+// ReplayDeadMessages publishes messages from the dead letter queue of a given topic back to the topic
+func (a *Admin) ReplayDeadMessages(ctx context.Context, in *ReplayDeadMessagesRequest) (*ReplayDeadMessagesResponse, error) {
+
+	var replayDeadMessagesResponse *ReplayDeadMessagesResponse
+
+	hdr := protocol.SetHeader(version, contentType, replayDeadMessages, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &replayDeadMessagesResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return replayDeadMessagesResponse, nil
+}
+
+// CreateTopic creates a new topic
+func (a *Admin) CreateTopic(ctx context.Context, in *CreateTopicRequest) (*CreateTopicResponse, error) {
+
+	var createTopicResponse *CreateTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, createTopic, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &createTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return createTopicResponse, nil
+}
+
+// UpdateTopicTTL sets the default and maximum time to live of the messages of a given topic
+func (a *Admin) UpdateTopicTTL(ctx context.Context, in *UpdateTopicTTLRequest) (*UpdateTopicTTLResponse, error) {
+
+	var updateTopicTTLResponse *UpdateTopicTTLResponse
+
+	hdr := protocol.SetHeader(version, contentType, updateTopicTTL, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &updateTopicTTLResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateTopicTTLResponse, nil
+}
+
+// UpdateTopicLimits sets the limits and overflow policy of a given topic
+func (a *Admin) UpdateTopicLimits(ctx context.Context, in *UpdateTopicLimitsRequest) (*UpdateTopicLimitsResponse, error) {
+
+	var updateTopicLimitsResponse *UpdateTopicLimitsResponse
+
+	hdr := protocol.SetHeader(version, contentType, updateTopicLimits, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &updateTopicLimitsResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return updateTopicLimitsResponse, nil
+}
+
+// RenameTopic changes the name of a given topic
+func (a *Admin) RenameTopic(ctx context.Context, in *RenameTopicRequest) (*RenameTopicResponse, error) {
+
+	var renameTopicResponse *RenameTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, renameTopic, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &renameTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return renameTopicResponse, nil
+}
+
+// DeleteTopic removes a given topic along with its subscriptions
+func (a *Admin) DeleteTopic(ctx context.Context, in *DeleteTopicRequest) (*DeleteTopicResponse, error) {
+
+	var deleteTopicResponse *DeleteTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, deleteTopic, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &deleteTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return deleteTopicResponse, nil
+}
+
+// DescribeTopic fetch the number of messages, dead messages, publishers and subscribers of a given topic
+func (a *Admin) DescribeTopic(ctx context.Context, in *DescribeTopicRequest) (*DescribeTopicResponse, error) {
+
+	var describeTopicResponse *DescribeTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, describeTopic, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &describeTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return describeTopicResponse, nil
+}
+
+// ListConsumerGroups fetch the consumer groups reading a given topic with their members, lag and partitions
+func (a *Admin) ListConsumerGroups(ctx context.Context, in *ListConsumerGroupsRequest) (*ListConsumerGroupsResponse, error) {
+
+	var listConsumerGroupsResponse *ListConsumerGroupsResponse
+
+	hdr := protocol.SetHeader(version, contentType, listConsumerGroups, a.client.GetAddress())
+
+	bodyBytes, err := a.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := a.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = a.factory.UnmarshalRequestBody(responseBytes, &listConsumerGroupsResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return listConsumerGroupsResponse, nil
+}
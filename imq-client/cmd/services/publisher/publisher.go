@@ -21,6 +21,7 @@ type Service interface {
 	ConnectToTopic(ctx context.Context, in *ConnectToTopicRequest) (*ConnectToTopicResponse, error)
 	DisconnectFromTopic(ctx context.Context, in *DisconnectFromTopicRequest) (*DisconnectFromTopicResponse, error)
 	PublishMessage(ctx context.Context, in *PublishMessageRequest) (*PublishMessageResponse, error)
+	PublishBatch(ctx context.Context, in *PublishBatchRequest) (*PublishBatchResponse, error)
 }
 
 // NewPublisher is the factory function for the Publisher type
@@ -150,3 +151,33 @@ func (p *Publisher) PublishMessage(ctx context.Context, in *PublishMessageReques
 
 	return publishMessageResponse, nil
 }
+
+// PublishBatch publishes a batch of messages to topic with a single request
+func (p *Publisher) PublishBatch(ctx context.Context, in *PublishBatchRequest) (*PublishBatchResponse, error) {
+
+	var publishBatchResponse *PublishBatchResponse
+
+	hdr := protocol.SetHeader(version, contentType, publishBatch, p.client.GetAddress())
+
+	bodyBytes, err := p.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := p.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = p.factory.UnmarshalRequestBody(responseBytes, &publishBatchResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return publishBatchResponse, nil
+}
@@ -1,13 +1,24 @@
 package subscriber
 
+import (
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/client/pkg/protocol"
+)
+
 const (
-	version              = "1.0"
+	version              = "2.0"
 	contentType          = "json"
 	showTopic            = "showTopicRequest"
 	subscribeToTopic     = "subscribeToTopicRequest"
 	unsubscribeFromTopic = "unsubscribeFromTopicRequest"
 	getSubscribedTopics  = "getSubscribedTopicsRequest"
 	getMessageFromTopic  = "getMessageFromTopicRequest"
+	getMessagesFromTopic = "getMessagesFromTopicRequest"
+	watchTopic           = "watchTopicRequest"
+	unwatchTopic         = "unwatchTopicRequest"
+	ackMessage           = "ackMessageRequest"
+	nackMessage          = "nackMessageRequest"
 )
 
 // ShowTopicRequest holds the request details for ShowTopics
@@ -20,15 +31,24 @@ type ShowTopicResponse struct {
 	Topics []string `json:"topics" xml:"topics"`
 }
 
-// SubscribeToTopicRequest holds the request details for SubscribeToTopic
+// SubscribeToTopicRequest holds the request details for SubscribeToTopic, TopicName may be a subscription pattern
+// where * stands for exactly one word of a dotted topic name and # for zero or more words, such as orders.*.created.
+// Filter is an optional selector on the message headers such as region = 'eu' AND priority > 3, only the messages
+// it accepts are delivered to the subscriber. GroupName optionally joins the subscriber to a consumer group, every
+// message of the topic is delivered to only one member of each group. Neither is supported on subscription patterns
+// and a subscription to a consumer group cannot be filtered
 type SubscribeToTopicRequest struct {
 	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
 	TopicName    string `json:"topicName" xml:"topicName"`
+	Filter       string `json:"filter,omitempty" xml:"filter,omitempty"`
+	GroupName    string `json:"groupName,omitempty" xml:"groupName,omitempty"`
 }
 
-// SubscribeToTopicResponse holds the response details for SubscribeToTopic
+// SubscribeToTopicResponse holds the response details for SubscribeToTopic, StreamError tells why the messages
+// of the topic cannot be pushed to the subscriber, which can still poll for them
 type SubscribeToTopicResponse struct {
-	Status string `json:"status" xml:"status"`
+	Status      string `json:"status" xml:"status"`
+	StreamError string `json:"streamError,omitempty" xml:"streamError,omitempty"`
 }
 
 // UnsubscribeFromTopicRequest holds the request details for UnsubscribeFromTopic
@@ -63,9 +83,83 @@ type GetMessageFromTopicResponse struct {
 	Message Message `json:"message" xml:"message"`
 }
 
-// Message holds the message details
+// GetMessagesFromTopicRequest holds the request details for GetMessagesFromTopic
+type GetMessagesFromTopicRequest struct {
+	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
+	MaxCount     int    `json:"maxCount" xml:"maxCount"`
+	MaxBytes     int    `json:"maxBytes" xml:"maxBytes"`
+}
+
+// GetMessagesFromTopicResponse holds the response details for GetMessagesFromTopic
+type GetMessagesFromTopicResponse struct {
+	Messages []Message `json:"messages" xml:"messages"`
+}
+
+// Message holds the message details, ExpiresAt is zero for a message that never expires
 type Message struct {
-	Data      string `json:"data" xml:"data"`
-	CretedAt  string `json:"cretedAt" xml:"cretedAt"`
-	ExpiresAt string `json:"expiredAt" xml:"expiredAt"`
+	MessageID     string           `json:"messageId" xml:"messageId"`
+	Key           string           `json:"key,omitempty" xml:"key,omitempty"`
+	ContentType   string           `json:"contentType,omitempty" xml:"contentType,omitempty"`
+	Headers       protocol.Headers `json:"headers,omitempty" xml:"headers,omitempty"`
+	Data          protocol.Payload `json:"data" xml:"data"`
+	CretedAt      time.Time        `json:"cretedAt" xml:"cretedAt"`
+	ExpiresAt     time.Time        `json:"expiredAt" xml:"expiredAt"`
+	DeliveryCount int              `json:"deliveryCount" xml:"deliveryCount"`
+}
+
+// WatchTopicRequest holds the request details for WatchTopic
+type WatchTopicRequest struct {
+	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
+}
+
+// WatchTopicResponse holds the response details for WatchTopic
+type WatchTopicResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// UnwatchTopicRequest holds the request details for UnwatchTopic
+type UnwatchTopicRequest struct {
+	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
 }
+
+// UnwatchTopicResponse holds the response details for UnwatchTopic
+type UnwatchTopicResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// AckMessageRequest holds the request details for AckMessage
+type AckMessageRequest struct {
+	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
+	MessageID    string `json:"messageId" xml:"messageId"`
+}
+
+// AckMessageResponse holds the response details for AckMessage
+type AckMessageResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// NackMessageRequest holds the request details for NackMessage
+type NackMessageRequest struct {
+	SubscriberID int    `json:"subscriberId" xml:"subscriberId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
+	MessageID    string `json:"messageId" xml:"messageId"`
+}
+
+// NackMessageResponse holds the response details for NackMessage
+type NackMessageResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// MessagePush holds the details of a message pushed by the server, TopicName is the topic the message
+// was published to and has to be used to acknowledge it
+type MessagePush struct {
+	TopicName string  `json:"topicName" xml:"topicName"`
+	Message   Message `json:"message" xml:"message"`
+}
+
+// MessageHandler is called with every message pushed by the server
+type MessageHandler func(push *MessagePush)
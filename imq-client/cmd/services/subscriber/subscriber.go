@@ -22,6 +22,12 @@ type Service interface {
 	UnsubscribeFromTopic(ctx context.Context, in *UnsubscribeFromTopicRequest) (*UnsubscribeFromTopicResponse, error)
 	GetSubscribedTopics(ctx context.Context, in *GetSubscribedTopicsRequest) (*GetSubscribedTopicsResponse, error)
 	GetMessageFromTopic(ctx context.Context, in *GetMessageFromTopicRequest) (*GetMessageFromTopicResponse, error)
+	GetMessagesFromTopic(ctx context.Context, in *GetMessagesFromTopicRequest) (*GetMessagesFromTopicResponse, error)
+	WatchTopic(ctx context.Context, in *WatchTopicRequest) (*WatchTopicResponse, error)
+	UnwatchTopic(ctx context.Context, in *UnwatchTopicRequest) (*UnwatchTopicResponse, error)
+	AckMessage(ctx context.Context, in *AckMessageRequest) (*AckMessageResponse, error)
+	NackMessage(ctx context.Context, in *NackMessageRequest) (*NackMessageResponse, error)
+	ReceiveMessages(handler MessageHandler)
 }
 
 // NewSubscriber is the factory function for the Subscriber
@@ -181,3 +187,169 @@ func (s *Subscriber) GetMessageFromTopic(ctx context.Context, in *GetMessageFrom
 
 	return getMessageFromTopicResponse, nil
 }
+
+// GetMessagesFromTopic fetches up to MaxCount messages publised to a given topic, stopping before their data grows over MaxBytes
+func (s *Subscriber) GetMessagesFromTopic(ctx context.Context, in *GetMessagesFromTopicRequest) (*GetMessagesFromTopicResponse, error) {
+
+	var getMessagesFromTopicResponse *GetMessagesFromTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, getMessagesFromTopic, s.client.GetAddress())
+
+	bodyBytes, err := s.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := s.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.factory.UnmarshalRequestBody(responseBytes, &getMessagesFromTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return getMessagesFromTopicResponse, nil
+}
+
+// WatchTopic asks the server to push every new message of a given topic
+func (s *Subscriber) WatchTopic(ctx context.Context, in *WatchTopicRequest) (*WatchTopicResponse, error) {
+
+	var watchTopicResponse *WatchTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, watchTopic, s.client.GetAddress())
+
+	bodyBytes, err := s.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := s.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.factory.UnmarshalRequestBody(responseBytes, &watchTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return watchTopicResponse, nil
+}
+
+// UnwatchTopic asks the server to stop pushing new messages of a given topic
+func (s *Subscriber) UnwatchTopic(ctx context.Context, in *UnwatchTopicRequest) (*UnwatchTopicResponse, error) {
+
+	var unwatchTopicResponse *UnwatchTopicResponse
+
+	hdr := protocol.SetHeader(version, contentType, unwatchTopic, s.client.GetAddress())
+
+	bodyBytes, err := s.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := s.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.factory.UnmarshalRequestBody(responseBytes, &unwatchTopicResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return unwatchTopicResponse, nil
+}
+
+// AckMessage confirms that a message received from a given topic has been processed
+func (s *Subscriber) AckMessage(ctx context.Context, in *AckMessageRequest) (*AckMessageResponse, error) {
+
+	var ackMessageResponse *AckMessageResponse
+
+	hdr := protocol.SetHeader(version, contentType, ackMessage, s.client.GetAddress())
+
+	bodyBytes, err := s.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := s.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.factory.UnmarshalRequestBody(responseBytes, &ackMessageResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return ackMessageResponse, nil
+}
+
+// NackMessage asks the server to deliver a message received from a given topic again
+func (s *Subscriber) NackMessage(ctx context.Context, in *NackMessageRequest) (*NackMessageResponse, error) {
+
+	var nackMessageResponse *NackMessageResponse
+
+	hdr := protocol.SetHeader(version, contentType, nackMessage, s.client.GetAddress())
+
+	bodyBytes, err := s.factory.MarshalRequestBody(in, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	request := protocol.Request{
+		Header: hdr,
+		Body:   string(bodyBytes),
+	}
+
+	responseBytes, err := s.client.SendRequest(ctx, &request)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.factory.UnmarshalRequestBody(responseBytes, &nackMessageResponse, contentType)
+	if err != nil {
+		return nil, err
+	}
+
+	return nackMessageResponse, nil
+}
+
+// ReceiveMessages calls handler with every message pushed by the server for the watched topics
+func (s *Subscriber) ReceiveMessages(handler MessageHandler) {
+	s.client.SetPushHandler(func(response *protocol.Response) {
+		if response.Event != protocol.EventMessage {
+			return
+		}
+
+		push := &MessagePush{}
+		if err := s.factory.UnmarshalRequestBody(response.Body, push, contentType); err != nil {
+			return
+		}
+
+		handler(push)
+	})
+}
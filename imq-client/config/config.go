@@ -4,6 +4,15 @@ package config
 type Settings struct {
 	ImqClientHost       string `env:"IMQ_CLIENT_HOST" envDefault:"localhost"`
 	ImqClientPort       int    `env:"IMQ_CLIENT_PORT" envDefault:"80"`
-	ImqClientDailerHost string `env:"IMQ_CLIENT_DAILER_PORT" envDefault:"localhost"`
-	ImqClientDailerPort int    `env:"IMQ_CLIENT_DAILER_PORT" envDefault:"5000"`
+	ImqClientDailerHost string `env:"IMQ_CLIENT_DAILER_HOST" envDefault:"localhost"`
+	ImqClientDailerPort int    `env:"IMQ_CLIENT_DAILER_PORT" envDefault:"0"`
+	ImqClientAPIKey     string `env:"IMQ_CLIENT_API_KEY"`
+
+	// with a client certificate the api key can be left empty, the server then authenticates
+	// the client named after the common name of the certificate
+	ImqClientTLS           bool   `env:"IMQ_CLIENT_TLS" envDefault:"false"`
+	ImqClientTLSCAFile     string `env:"IMQ_CLIENT_TLS_CA_FILE"`
+	ImqClientTLSCertFile   string `env:"IMQ_CLIENT_TLS_CERT_FILE"`
+	ImqClientTLSKeyFile    string `env:"IMQ_CLIENT_TLS_KEY_FILE"`
+	ImqClientTLSServerName string `env:"IMQ_CLIENT_TLS_SERVER_NAME"`
 }
@@ -0,0 +1,294 @@
+package console
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/admin"
+	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/publisher"
+)
+
+func (c *Console) adminHandler(ctx context.Context) {
+	fmt.Println(welcomeAdmin)
+
+	shutdown := false
+	for !shutdown {
+		displayActions(adminWelcomeMenu())
+
+		input := getStringInput("Enter your choice")
+
+		switch choice(input) {
+		case showAllTopics:
+			response, err := processShowAllTopics(ctx, c.publisherSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayTopics(response.Topics)
+
+		case listDeadMessages:
+			response, err := processListDeadMessages(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayDeadMessages(response.Messages)
+
+		case replayDeadMessages:
+			response, err := processReplayDeadMessages(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(fmt.Sprintf("%v %v message(s)", response.Status, response.Count))
+
+		case purgeDeadMessages:
+			response, err := processPurgeDeadMessages(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(fmt.Sprintf("%v %v message(s)", response.Status, response.Count))
+
+		case createTopic:
+			response, err := processCreateTopic(ctx, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(response.Status)
+
+		case renameTopic:
+			response, err := processRenameTopic(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(response.Status)
+
+		case deleteTopic:
+			response, err := processDeleteTopic(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(response.Status)
+
+		case describeTopic:
+			response, err := processDescribeTopic(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayTopicDescription(response)
+
+		case updateTopicTTL:
+			response, err := processUpdateTopicTTL(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(response.Status)
+
+		case listConsumerGroups:
+			response, err := processListConsumerGroups(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayConsumerGroups(response.Groups)
+
+		case updateTopicLimits:
+			response, err := processUpdateTopicLimits(ctx, c.publisherSvc, c.adminSvc, c.clientID)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(response.Status)
+
+		case exitAdmin:
+			shutdown = true
+			c.ShutdwonChan <- struct{}{}
+
+		default:
+			displayError(errors.New(invalidChoice))
+		}
+	}
+}
+
+func processChooseTopic(ctx context.Context, svc publisher.Service, id int) (string, error) {
+	showTopicResponse, err := processShowAllTopics(ctx, svc, id)
+	if err != nil {
+		return "", err
+	}
+
+	displayTopics(showTopicResponse.Topics)
+
+	input := getIntegerInput("Choose topic")
+	if input == 0 || input > len(showTopicResponse.Topics) {
+		return "", errors.New(invalidChoice)
+	}
+
+	return showTopicResponse.Topics[input-1], nil
+}
+
+func processListDeadMessages(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.ListDeadMessagesResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	listDeadMessagesResponse, err := aSvc.ListDeadMessages(ctx, &admin.ListDeadMessagesRequest{AdminID: id, TopicName: topicName})
+	if err != nil {
+		return nil, err
+	}
+	return listDeadMessagesResponse, nil
+}
+
+func processReplayDeadMessages(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.ReplayDeadMessagesResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID := getStringInput("Enter message id (leave empty for all)")
+
+	replayDeadMessagesResponse, err := aSvc.ReplayDeadMessages(ctx, &admin.ReplayDeadMessagesRequest{AdminID: id, TopicName: topicName, MessageID: messageID})
+	if err != nil {
+		return nil, err
+	}
+	return replayDeadMessagesResponse, nil
+}
+
+func processPurgeDeadMessages(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.PurgeDeadMessagesResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	messageID := getStringInput("Enter message id (leave empty for all)")
+
+	purgeDeadMessagesResponse, err := aSvc.PurgeDeadMessages(ctx, &admin.PurgeDeadMessagesRequest{AdminID: id, TopicName: topicName, MessageID: messageID})
+	if err != nil {
+		return nil, err
+	}
+	return purgeDeadMessagesResponse, nil
+}
+
+func processCreateTopic(ctx context.Context, aSvc admin.Service, id int) (*admin.CreateTopicResponse, error) {
+	topicName := getStringInput("Enter topic name")
+	partitions := getIntegerInput("Enter number of partitions (leave empty for a single partition)")
+
+	createTopicResponse, err := aSvc.CreateTopic(ctx, &admin.CreateTopicRequest{AdminID: id, TopicName: topicName, Partitions: partitions})
+	if err != nil {
+		return nil, err
+	}
+	return createTopicResponse, nil
+}
+
+func processRenameTopic(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.RenameTopicResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	newTopicName := getStringInput("Enter new topic name")
+
+	renameTopicResponse, err := aSvc.RenameTopic(ctx, &admin.RenameTopicRequest{AdminID: id, TopicName: topicName, NewTopicName: newTopicName})
+	if err != nil {
+		return nil, err
+	}
+	return renameTopicResponse, nil
+}
+
+func processDeleteTopic(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.DeleteTopicResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	force := getStringInput("Delete even if the topic still has messages (y/n)") == "y"
+
+	deleteTopicResponse, err := aSvc.DeleteTopic(ctx, &admin.DeleteTopicRequest{AdminID: id, TopicName: topicName, Force: force})
+	if err != nil {
+		return nil, err
+	}
+	return deleteTopicResponse, nil
+}
+
+func processDescribeTopic(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.DescribeTopicResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	describeTopicResponse, err := aSvc.DescribeTopic(ctx, &admin.DescribeTopicRequest{AdminID: id, TopicName: topicName})
+	if err != nil {
+		return nil, err
+	}
+	return describeTopicResponse, nil
+}
+
+func processUpdateTopicTTL(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.UpdateTopicTTLResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultTTL := getIntegerInput("Enter default ttl in seconds (leave empty for the server default)")
+	maxTTL := getIntegerInput("Enter maximum ttl in seconds (leave empty for the server default)")
+
+	updateTopicTTLResponse, err := aSvc.UpdateTopicTTL(ctx, &admin.UpdateTopicTTLRequest{AdminID: id, TopicName: topicName, DefaultTTL: defaultTTL, MaxTTL: maxTTL})
+	if err != nil {
+		return nil, err
+	}
+	return updateTopicTTLResponse, nil
+}
+
+func processListConsumerGroups(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.ListConsumerGroupsResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	listConsumerGroupsResponse, err := aSvc.ListConsumerGroups(ctx, &admin.ListConsumerGroupsRequest{AdminID: id, TopicName: topicName})
+	if err != nil {
+		return nil, err
+	}
+	return listConsumerGroupsResponse, nil
+}
+
+func processUpdateTopicLimits(ctx context.Context, pSvc publisher.Service, aSvc admin.Service, id int) (*admin.UpdateTopicLimitsResponse, error) {
+	topicName, err := processChooseTopic(ctx, pSvc, id)
+	if err != nil {
+		return nil, err
+	}
+
+	maxMessages := getIntegerInput("Enter maximum number of messages (leave empty for the server default)")
+	maxBytes := getIntegerInput("Enter maximum bytes (leave empty for the server default)")
+	overflowPolicy := getStringInput("Enter overflow policy reject, dropOldest or block (leave empty for the server default)")
+
+	updateTopicLimitsResponse, err := aSvc.UpdateTopicLimits(ctx, &admin.UpdateTopicLimitsRequest{AdminID: id, TopicName: topicName, MaxMessages: maxMessages, MaxBytes: maxBytes, OverflowPolicy: overflowPolicy})
+	if err != nil {
+		return nil, err
+	}
+	return updateTopicLimitsResponse, nil
+}
+
+func adminWelcomeMenu() []string {
+	return []string{
+		"1. Show all Topics",
+		"2. List dead messages of Topic",
+		"3. Replay dead messages of Topic",
+		"4. Purge dead messages of Topic",
+		"5. Create Topic",
+		"6. Rename Topic",
+		"7. Delete Topic",
+		"8. Describe Topic",
+		"9. Set TTL of Topic",
+		"10. List consumer groups of Topic",
+		"11. Set limits of Topic",
+		"12. Exit",
+	}
+}
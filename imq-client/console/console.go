@@ -6,8 +6,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/admin"
 	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/publisher"
 	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/subscriber"
 	"github.com/WinnersonKharsunai/GraduationProject/client/pkg/client"
@@ -19,16 +21,18 @@ type Console struct {
 	client        client.Service
 	publisherSvc  publisher.Service
 	subscriberSvc subscriber.Service
+	adminSvc      admin.Service
 	ShutdwonChan  chan struct{}
 }
 
 // NewConsole is the factory function for Console type
-func NewConsole(clientID int, client client.Service, pSvc publisher.Service, sSvc subscriber.Service) *Console {
+func NewConsole(clientID int, client client.Service, pSvc publisher.Service, sSvc subscriber.Service, aSvc admin.Service) *Console {
 	return &Console{
 		client:        client,
 		clientID:      clientID,
 		publisherSvc:  pSvc,
 		subscriberSvc: sSvc,
+		adminSvc:      aSvc,
 		ShutdwonChan:  make(chan struct{}),
 	}
 }
@@ -42,6 +46,8 @@ func (c *Console) Start(ctx context.Context) error {
 		go c.publisherHandler(ctx)
 	case subscriberRole:
 		go c.subscriberHandler(ctx)
+	case adminRole:
+		go c.adminHandler(ctx)
 	default:
 		return errors.New("client not recognised")
 	}
@@ -53,12 +59,13 @@ func getMessage() (publisher.Message, error) {
 	if err != nil {
 		return publisher.Message{}, err
 	}
-	now := time.Now().UTC()
+
+	ttl := getIntegerInput("Enter ttl in seconds (leave empty for the default of the topic)")
 
 	return publisher.Message{
-		Data:      msg,
-		CretedAt:  now.Format("2006-01-02 15:04:05"),
-		ExpiresAt: now.Add(time.Duration(time.Second * 60)).Format("2006-01-02 15:04:05"),
+		ContentType: "text/plain",
+		Data:        []byte(msg),
+		TTL:         ttl,
 	}, nil
 }
 
@@ -75,6 +82,20 @@ func getMessageInput() (string, error) {
 	return msg, nil
 }
 
+// getLineInput reads a whole line so the value may hold spaces, an empty line is returned as an empty value
+func getLineInput(msg string) (string, error) {
+
+	fmt.Printf("\n%v: ", msg)
+	reader := bufio.NewReader(os.Stdin)
+
+	value, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(value), nil
+}
+
 func getStringInput(msg string) string {
 	var value string
 
@@ -94,10 +115,13 @@ func getIntegerInput(msg string) int {
 }
 
 func (c *Console) getRole() clientRole {
-	if c.clientID >= 5000 && c.clientID < 6000 {
+	switch c.client.GetRole() {
+	case "publisher":
 		return publisherRole
-	} else if c.clientID >= 6000 && c.clientID < 7000 {
+	case "subscriber":
 		return subscriberRole
+	case "admin":
+		return adminRole
 	}
 	return unknown
 }
@@ -124,11 +148,59 @@ func displayStatus(status string) {
 	fmt.Printf("\nSTATUS: %v\n", status)
 }
 
+func displayPushedMessage(push *subscriber.MessagePush) {
+	fmt.Printf("\nNEW MESSAGE ON %v\n\tMessageId: %v\n\tData: %s\n\tCreatedAt: %v\n\tExpiredAt: %v\n\tDeliveries: %v\n",
+		push.TopicName, push.Message.MessageID, push.Message.Data, push.Message.CretedAt, formatExpiry(push.Message.ExpiresAt), push.Message.DeliveryCount)
+}
+
 func displayMessage(msg subscriber.Message) {
-	if msg.Data != "" {
-		fmt.Printf("\nMESSAGE\n\tData: %v\n\tCreatedAt: %v\n\tExpiredAt: %v\n",
-			msg.Data, msg.CretedAt, msg.ExpiresAt)
+	if len(msg.Data) != 0 {
+		fmt.Printf("\nMESSAGE\n\tMessageId: %v\n\tData: %s\n\tCreatedAt: %v\n\tExpiredAt: %v\n\tDeliveries: %v\n",
+			msg.MessageID, msg.Data, msg.CretedAt, formatExpiry(msg.ExpiresAt), msg.DeliveryCount)
 	} else {
 		fmt.Println("MESSAGE:\tNo message recieved")
 	}
 }
+
+func displayDeadMessages(messages []admin.DeadMessage) {
+	if len(messages) == 0 {
+		fmt.Println("\nDEAD MESSAGES:\tNo message found")
+		return
+	}
+
+	fmt.Print("\nDEAD MESSAGES:")
+	for i, msg := range messages {
+		fmt.Printf("\t%d.%v\n\t\tData: %s\n\t\tReason: %v\n\t\tDeadAt: %v\n",
+			i+1, msg.MessageID, msg.Data, msg.Reason, msg.DeadAt)
+	}
+}
+
+func displayConsumerGroups(groups []admin.ConsumerGroup) {
+	if len(groups) == 0 {
+		fmt.Println("\nCONSUMER GROUPS:\tNo consumer group found")
+		return
+	}
+
+	fmt.Print("\nCONSUMER GROUPS:")
+	for i, g := range groups {
+		fmt.Printf("\t%d.%v\n\t\tMembers: %v\n\t\tPending: %v\n\t\tLag: %v\n",
+			i+1, g.GroupName, g.Members, g.Pending, g.Lag)
+		for _, p := range g.Partitions {
+			fmt.Printf("\t\tPartition %v: Members: %v Offset: %v Pending: %v Lag: %v\n",
+				p.Partition, p.Members, p.Offset, p.Pending, p.Lag)
+		}
+	}
+}
+
+func displayTopicDescription(topic *admin.DescribeTopicResponse) {
+	fmt.Printf("\nTOPIC %v\n\tPartitions: %v\n\tMessages: %v (%v per partition)\n\tBytes: %v (%v per partition)\n\tOverflowPolicy: %v\n\tDeadMessages: %v\n\tPublishers: %v\n\tSubscribers: %v\n\tDefaultTTL: %vs\n\tMaxTTL: %vs\n",
+		topic.TopicName, topic.Partitions, topic.Depth, topic.MaxMessages, topic.Bytes, topic.MaxBytes, topic.OverflowPolicy,
+		topic.DeadMessages, topic.Publishers, topic.Subscribers, topic.DefaultTTL, topic.MaxTTL)
+}
+
+func formatExpiry(expiresAt time.Time) string {
+	if expiresAt.IsZero() {
+		return "never"
+	}
+	return expiresAt.String()
+}
@@ -7,6 +7,7 @@ const (
 	unknown clientRole = iota
 	publisherRole
 	subscriberRole
+	adminRole
 
 	showAllTopics        choice = "1"
 	register             choice = "2"
@@ -18,9 +19,21 @@ const (
 	unsubscribe          choice = "4"
 	readMessage          choice = "5"
 	exitSubscriber       choice = "6"
+	listDeadMessages     choice = "2"
+	replayDeadMessages   choice = "3"
+	purgeDeadMessages    choice = "4"
+	createTopic          choice = "5"
+	renameTopic          choice = "6"
+	deleteTopic          choice = "7"
+	describeTopic        choice = "8"
+	updateTopicTTL       choice = "9"
+	listConsumerGroups   choice = "10"
+	updateTopicLimits    choice = "11"
+	exitAdmin            choice = "12"
 
 	welcome           = "Welcome to ITT Messaging Queue"
 	welcomepublisher  = "You are logged in as publisher"
 	welcomeSubscriber = "You are logged in as subscriber"
+	welcomeAdmin      = "You are logged in as admin"
 	invalidChoice     = "invalid choice!!!"
 )
@@ -91,7 +91,12 @@ func processRegisterToTopic(ctx context.Context, svc publisher.Service, id int)
 }
 
 func processDeregisterFromTopic(ctx context.Context, svc publisher.Service, id int) (*publisher.DisconnectFromTopicResponse, error) {
-	disconnectFromTopicResponse, err := svc.DisconnectFromTopic(ctx, &publisher.DisconnectFromTopicRequest{PublisherID: id})
+	topicName := getStringInput("Enter topic name")
+	if topicName == "" {
+		return nil, errors.New(invalidChoice)
+	}
+
+	disconnectFromTopicResponse, err := svc.DisconnectFromTopic(ctx, &publisher.DisconnectFromTopicRequest{PublisherID: id, TopicName: topicName})
 	if err != nil {
 		return nil, err
 	}
@@ -99,12 +104,14 @@ func processDeregisterFromTopic(ctx context.Context, svc publisher.Service, id i
 }
 
 func processPublishMessage(ctx context.Context, svc publisher.Service, id int) (*publisher.PublishMessageResponse, error) {
+	topicName := getStringInput("Enter topic name (leave empty when connected to a single topic)")
+
 	msg, err := getMessage()
 	if err != nil {
 		return nil, err
 	}
 
-	publishMessageResponse, err := svc.PublishMessage(ctx, &publisher.PublishMessageRequest{PublisherID: id, Message: msg})
+	publishMessageResponse, err := svc.PublishMessage(ctx, &publisher.PublishMessageRequest{PublisherID: id, TopicName: topicName, Message: msg})
 	if err != nil {
 		return nil, err
 	}
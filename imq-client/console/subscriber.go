@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/WinnersonKharsunai/GraduationProject/client/cmd/services/subscriber"
 )
@@ -11,6 +12,20 @@ import (
 func (c *Console) subscriberHandler(ctx context.Context) {
 	fmt.Println(welcomeSubscriber)
 
+	c.subscriberSvc.ReceiveMessages(func(push *subscriber.MessagePush) {
+		displayPushedMessage(push)
+
+		// the push is handled on the connection reader, so the acknowledgement is sent from its own goroutine
+		go func() {
+			if _, err := c.subscriberSvc.AckMessage(ctx, &subscriber.AckMessageRequest{SubscriberID: c.clientID, TopicName: push.TopicName, MessageID: push.Message.MessageID}); err != nil {
+				displayError(err)
+			}
+		}()
+	})
+	if err := watchSubscribedTopics(ctx, c.subscriberSvc, c.clientID); err != nil {
+		displayError(err)
+	}
+
 	shutdown := false
 	for !shutdown {
 		displayActions(subscriberWelcomeMenu())
@@ -33,6 +48,9 @@ func (c *Console) subscriberHandler(ctx context.Context) {
 				continue
 			}
 			displayStatus(response.Status)
+			if response.StreamError != "" {
+				fmt.Printf("\nSTREAMING: %v, poll the topic for its messages\n", response.StreamError)
+			}
 
 		case showSubscribedTopics:
 			response, err := processShowSubscribedTopics(ctx, c.subscriberSvc, c.clientID)
@@ -51,13 +69,20 @@ func (c *Console) subscriberHandler(ctx context.Context) {
 			displayStatus(response.Status)
 
 		case readMessage:
-			response, err := processReadMessage(ctx, c.subscriberSvc, c.clientID)
+			topicName, response, err := processReadMessage(ctx, c.subscriberSvc, c.clientID)
 			if err != nil {
 				displayError(err)
 				continue
 			}
 			displayMessage(response.Message)
 
+			status, err := processAcknowledgeMessage(ctx, c.subscriberSvc, c.clientID, topicName, response.Message)
+			if err != nil {
+				displayError(err)
+				continue
+			}
+			displayStatus(status)
+
 		case exitSubscriber:
 			shutdown = true
 			c.ShutdwonChan <- struct{}{}
@@ -84,14 +109,34 @@ func procesSubscribeToTopic(ctx context.Context, svc subscriber.Service, id int)
 
 	displayTopics(showTopicResponse.Topics)
 
-	input := getIntegerInput("Choose topic")
-	if input == 0 || input > len(showTopicResponse.Topics) {
+	input := getIntegerInput("Choose topic (0 to enter a pattern)")
+	if input > len(showTopicResponse.Topics) {
 		return nil, errors.New(invalidChoice)
 	}
 
-	topicName := showTopicResponse.Topics[input-1]
+	var topicName, filter, groupName string
+	if input == 0 {
+		topicName = getStringInput("Enter pattern")
+	} else {
+		topicName = showTopicResponse.Topics[input-1]
+
+		// consumer groups and filters are only supported on topic subscriptions, and not together
+		groupName, err = getLineInput("Enter consumer group to share the messages with (empty to read every message)")
+		if err != nil {
+			return nil, err
+		}
+
+		if groupName == "" {
+			filter, err = getLineInput("Enter filter, such as region = 'eu' AND priority > 3 (empty for every message)")
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	request := &subscriber.SubscribeToTopicRequest{SubscriberID: id, TopicName: topicName, Filter: filter, GroupName: groupName}
 
-	subscribeToTopicResponse, err := svc.SubscribeToTopic(ctx, &subscriber.SubscribeToTopicRequest{SubscriberID: id, TopicName: topicName})
+	subscribeToTopicResponse, err := svc.SubscribeToTopic(ctx, request)
 	if err != nil {
 		return nil, err
 	}
@@ -126,24 +171,70 @@ func processUnsubscribeFromTopic(ctx context.Context, svc subscriber.Service, id
 	return unsubscribeFromTopicResponse, nil
 }
 
-func processReadMessage(ctx context.Context, svc subscriber.Service, id int) (*subscriber.GetMessageFromTopicResponse, error) {
+func processReadMessage(ctx context.Context, svc subscriber.Service, id int) (string, *subscriber.GetMessageFromTopicResponse, error) {
 	getSubscribedTopicsResponse, err := processShowSubscribedTopics(ctx, svc, id)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 	displayTopics(getSubscribedTopicsResponse.Topics)
 
 	input := getIntegerInput("Choose topic")
 	if input == 0 || input > len(getSubscribedTopicsResponse.Topics) {
-		return nil, errors.New(invalidChoice)
+		return "", nil, errors.New(invalidChoice)
 	}
 
 	topicName := getSubscribedTopicsResponse.Topics[input-1]
+	if isPattern(topicName) {
+		// messages are read from a single topic, so one matched by the pattern has to be named
+		topicName = getStringInput("Enter topic name")
+	}
+
 	getMessageFromTopicResponse, err := svc.GetMessageFromTopic(ctx, &subscriber.GetMessageFromTopicRequest{SubscriberID: id, TopicName: topicName})
 	if err != nil {
-		return nil, err
+		return "", nil, err
+	}
+	return topicName, getMessageFromTopicResponse, nil
+}
+
+func processAcknowledgeMessage(ctx context.Context, svc subscriber.Service, id int, topicName string, msg subscriber.Message) (string, error) {
+	switch getStringInput("Acknowledge message (y/n)") {
+	case "y":
+		ackMessageResponse, err := svc.AckMessage(ctx, &subscriber.AckMessageRequest{SubscriberID: id, TopicName: topicName, MessageID: msg.MessageID})
+		if err != nil {
+			return "", err
+		}
+		return ackMessageResponse.Status, nil
+
+	case "n":
+		nackMessageResponse, err := svc.NackMessage(ctx, &subscriber.NackMessageRequest{SubscriberID: id, TopicName: topicName, MessageID: msg.MessageID})
+		if err != nil {
+			return "", err
+		}
+		return nackMessageResponse.Status, nil
+
+	default:
+		return "", errors.New(invalidChoice)
 	}
-	return getMessageFromTopicResponse, nil
+}
+
+func watchSubscribedTopics(ctx context.Context, svc subscriber.Service, id int) error {
+	getSubscribedTopicsResponse, err := processShowSubscribedTopics(ctx, svc, id)
+	if err != nil {
+		return err
+	}
+
+	for _, topicName := range getSubscribedTopicsResponse.Topics {
+		_, err := svc.WatchTopic(ctx, &subscriber.WatchTopicRequest{SubscriberID: id, TopicName: topicName})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isPattern reports whether the subscription is a pattern rather than a topic name
+func isPattern(topicName string) bool {
+	return strings.ContainsAny(topicName, "*#")
 }
 
 func subscriberWelcomeMenu() []string {
@@ -1,6 +1,8 @@
 package messagefactory
 
 import (
+	"bytes"
+	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
@@ -9,6 +11,7 @@ import (
 const (
 	contentTypeJSON    = "json"
 	contentTypeXML     = "xml"
+	contentTypeGob     = "gob"
 	unknownContentType = "unknown content-type"
 )
 
@@ -29,6 +32,12 @@ func (m *Messagefactory) MarshalRequestBody(v interface{}, contentType string) (
 		return json.Marshal(v)
 	case contentTypeXML:
 		return xml.Marshal(v)
+	case contentTypeGob:
+		buf := &bytes.Buffer{}
+		if err := gob.NewEncoder(buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
 	default:
 		return nil, errors.New(unknownContentType)
 	}
@@ -40,6 +49,8 @@ func (m *Messagefactory) UnmarshalRequestBody(data []byte, v interface{}, conten
 		return json.Unmarshal(data, v)
 	case contentTypeXML:
 		return xml.Unmarshal(data, v)
+	case contentTypeGob:
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
 	default:
 		return errors.New(unknownContentType)
 	}
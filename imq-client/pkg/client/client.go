@@ -3,36 +3,70 @@ package client
 import (
 	"bufio"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
 
 	"github.com/WinnersonKharsunai/GraduationProject/client/pkg/protocol"
 )
 
+var (
+	errConnectionClosed = errors.New("connection closed")
+	errServerGoingAway  = errors.New("server is shutting down")
+)
+
 // Client is the concrete implementation for the client
 type Client struct {
-	Addr       string
-	con        net.Conn
-	dialerHost string
-	dialerPort int
+	Addr        string
+	con         net.Conn
+	reader      *bufio.Reader
+	dialerHost  string
+	dialerPort  int
+	apiKey      string
+	tlsConfig   *tls.Config
+	framed      bool
+	clientID    int
+	role        string
+	writeMu     sync.Mutex
+	pendingMu   sync.Mutex
+	pending     map[string]chan *protocol.Response
+	nextID      uint64
+	handlerMu   sync.RWMutex
+	pushHandler PushHandler
+	done        chan struct{}
+	err         error
+	// goingAway is set once the server said it is shutting down, no further request is sent
+	goingAway int32
 }
 
+// PushHandler is called with every response pushed by the server
+type PushHandler func(response *protocol.Response)
+
 // Service is the interface for the client
 type Service interface {
 	Dial() error
 	GetID() int
+	GetRole() string
 	GetAddress() string
 	SendRequest(ctx context.Context, request *protocol.Request) ([]byte, error)
+	SetPushHandler(handler PushHandler)
 }
 
-// NewClient is the factory functipn for the client
-func NewClient(addr, dialerHost string, dialerPort int) Service {
+// NewClient is the factory functipn for the client, the connection is made over TLS when tlsConfig is given
+func NewClient(addr, dialerHost string, dialerPort int, apiKey string, tlsConfig *tls.Config) Service {
 	return &Client{
 		Addr:       addr,
 		dialerHost: dialerHost,
 		dialerPort: dialerPort,
+		apiKey:     apiKey,
+		tlsConfig:  tlsConfig,
+		pending:    make(map[string]chan *protocol.Response),
+		done:       make(chan struct{}),
 	}
 }
 
@@ -45,16 +79,35 @@ func (c *Client) Dial() error {
 		},
 	}
 
-	con, err := dialer.Dial("tcp", c.Addr)
+	var (
+		con net.Conn
+		err error
+	)
+
+	if c.tlsConfig != nil {
+		con, err = tls.DialWithDialer(dialer, "tcp", c.Addr, c.tlsConfig)
+	} else {
+		con, err = dialer.Dial("tcp", c.Addr)
+	}
 	if err != nil {
 		return err
 	}
 	c.con = con
+	c.reader = bufio.NewReader(con)
 
-	if err = testConnection(con); err != nil {
+	// a server over capacity answers with a retryable error instead of the connected status
+	if err = testConnection(c.reader); err != nil {
+		con.Close()
 		return err
 	}
 
+	if err = c.authenticate(); err != nil {
+		con.Close()
+		return err
+	}
+
+	go c.receive()
+
 	return nil
 }
 
@@ -63,37 +116,151 @@ func (c *Client) GetAddress() string {
 	return c.Addr
 }
 
-// GetID return client Id
+// GetID return the client Id given by the server on authentication
 func (c *Client) GetID() int {
-	return c.dialerPort
+	return c.clientID
 }
 
-// SendRequest send the request to server
+// GetRole return the role given by the server on authentication
+func (c *Client) GetRole() string {
+	return c.role
+}
+
+// SetPushHandler sets the handler called with every response pushed by the server
+func (c *Client) SetPushHandler(handler PushHandler) {
+	c.handlerMu.Lock()
+	defer c.handlerMu.Unlock()
+	c.pushHandler = handler
+}
+
+// SendRequest send the request to server, it is safe to be called concurrently and every
+// response is matched to its request by the requestId
 func (c *Client) SendRequest(ctx context.Context, request *protocol.Request) ([]byte, error) {
+	if atomic.LoadInt32(&c.goingAway) == 1 {
+		return []byte{}, errServerGoingAway
+	}
+
+	requestID := strconv.FormatUint(atomic.AddUint64(&c.nextID, 1), 10)
+	request.Header.RequestID = requestID
+
+	responseCh := make(chan *protocol.Response, 1)
+
+	c.pendingMu.Lock()
+	c.pending[requestID] = responseCh
+	c.pendingMu.Unlock()
 
-	if err := writeToConnection(c.con, request); err != nil {
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, requestID)
+		c.pendingMu.Unlock()
+	}()
+
+	if err := c.writeRequest(request); err != nil {
 		return []byte{}, err
 	}
 
-	raw, err := readFromConnection(c.con)
-	if err != nil {
+	var response *protocol.Response
+	select {
+	case response = <-responseCh:
+	case <-c.done:
+		return []byte{}, c.err
+	case <-ctx.Done():
+		return []byte{}, ctx.Err()
+	}
+
+	if err := response.Err(); err != nil {
 		return []byte{}, err
 	}
 
-	response, err := unmarshalResponse(raw)
+	return response.Body, nil
+}
+
+// receive reads every response from the server, handing pushed ones to the
+// push handler and the rest to the request with the same requestId
+func (c *Client) receive() {
+	defer close(c.done)
+
+	for {
+		response, err := c.readResponse()
+		if err != nil {
+			c.err = errConnectionClosed
+			if atomic.LoadInt32(&c.goingAway) == 1 {
+				c.err = errServerGoingAway
+			}
+			return
+		}
+
+		// the requests already sent are still answered before the server closes the connection
+		if response.Event == protocol.EventGoingAway {
+			atomic.StoreInt32(&c.goingAway, 1)
+		}
+
+		if response.Event != "" {
+			c.handlePush(response)
+			continue
+		}
+
+		c.pendingMu.Lock()
+		responseCh, ok := c.pending[response.RequestID]
+		c.pendingMu.Unlock()
+
+		if ok {
+			responseCh <- response
+		}
+	}
+}
+
+func (c *Client) handlePush(response *protocol.Response) {
+	c.handlerMu.RLock()
+	handler := c.pushHandler
+	c.handlerMu.RUnlock()
+
+	if handler != nil {
+		handler(response)
+	}
+}
+
+// authenticate presents the api key to the server, it has to be the first request on the connection
+func (c *Client) authenticate() error {
+	body, err := json.Marshal(authenticateRequest{APIKey: c.apiKey})
 	if err != nil {
-		return []byte{}, err
+		return err
 	}
 
-	if response.Error != "" {
-		return []byte{}, errors.New(response.Error)
+	request := &protocol.Request{
+		Header: protocol.SetHeader(version, contentType, authenticate, c.Addr),
+		Body:   string(body),
 	}
 
-	return response.Body, nil
+	// the server answers in the framing of the first request for the rest of the connection
+	c.framed = version == protocol.Version2
+
+	if err := c.writeRequest(request); err != nil {
+		return err
+	}
+
+	response, err := c.readResponse()
+	if err != nil {
+		return err
+	}
+
+	if err := response.Err(); err != nil {
+		return err
+	}
+
+	session := authenticateResponse{}
+	if err := json.Unmarshal(response.Body, &session); err != nil {
+		return err
+	}
+
+	c.clientID = session.ClientID
+	c.role = session.Role
+
+	return nil
 }
 
-func testConnection(c net.Conn) error {
-	data, err := bufio.NewReader(c).ReadString('\n')
+func testConnection(r *bufio.Reader) error {
+	data, err := r.ReadString('\n')
 	if err != nil {
 		return err
 	}
@@ -103,15 +270,37 @@ func testConnection(c net.Conn) error {
 		return err
 	}
 
-	if response.Error != "" {
-		return errors.New(response.Error)
+	if err := response.Err(); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func readFromConnection(c net.Conn) (string, error) {
-	data, err := bufio.NewReader(c).ReadString('\n')
+func (c *Client) writeRequest(request *protocol.Request) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.framed {
+		return protocol.WriteRequestFrame(c.con, request)
+	}
+	return writeToConnection(c.con, request)
+}
+
+func (c *Client) readResponse() (*protocol.Response, error) {
+	if c.framed {
+		return protocol.ReadResponseFrame(c.reader)
+	}
+
+	data, err := readFromConnection(c.reader)
+	if err != nil {
+		return nil, err
+	}
+	return unmarshalResponse(data)
+}
+
+func readFromConnection(r *bufio.Reader) (string, error) {
+	data, err := r.ReadString('\n')
 	return data, err
 }
 
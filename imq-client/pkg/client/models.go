@@ -0,0 +1,20 @@
+package client
+
+const (
+	version      = "2.0"
+	contentType  = "json"
+	authenticate = "authenticateRequest"
+)
+
+// authenticateRequest holds the credentials presented to the server
+type authenticateRequest struct {
+	APIKey string `json:"apiKey"`
+}
+
+// authenticateResponse holds the session given by the server
+type authenticateResponse struct {
+	ClientID    int      `json:"clientId"`
+	Name        string   `json:"name"`
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
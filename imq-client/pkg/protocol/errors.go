@@ -0,0 +1,78 @@
+package protocol
+
+import "errors"
+
+// ErrorCode classifies the error of a response so callers do not have to match its message
+type ErrorCode string
+
+// codes a failed response can carry
+const (
+	CodeNotFound        ErrorCode = "NOT_FOUND"
+	CodeAlreadyExists   ErrorCode = "ALREADY_EXISTS"
+	CodeUnauthorized    ErrorCode = "UNAUTHORIZED"
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	CodeUnavailable     ErrorCode = "UNAVAILABLE"
+	CodeQueueEmpty      ErrorCode = "QUEUE_EMPTY"
+	CodeQueueFull       ErrorCode = "QUEUE_FULL"
+	CodeInternal        ErrorCode = "INTERNAL"
+)
+
+// Error is the error a request failed with on the server
+type Error struct {
+	Code      ErrorCode
+	Message   string
+	Retryable bool
+	Details   string
+}
+
+// errors to check the error of a request against with errors.Is, they match every Error of the same code
+var (
+	ErrNotFound        = &Error{Code: CodeNotFound, Message: "not found"}
+	ErrAlreadyExists   = &Error{Code: CodeAlreadyExists, Message: "already exists"}
+	ErrUnauthorized    = &Error{Code: CodeUnauthorized, Message: "unauthorized"}
+	ErrInvalidArgument = &Error{Code: CodeInvalidArgument, Message: "invalid argument"}
+	ErrUnavailable     = &Error{Code: CodeUnavailable, Message: "unavailable"}
+	ErrQueueEmpty      = &Error{Code: CodeQueueEmpty, Message: "queue empty"}
+	ErrQueueFull       = &Error{Code: CodeQueueFull, Message: "queue full"}
+	ErrInternal        = &Error{Code: CodeInternal, Message: "internal error"}
+)
+
+// Error returns the details of the error when the server sent them, they hold the message along with what caused it
+func (e *Error) Error() string {
+	if e.Details != "" {
+		return e.Details
+	}
+	return e.Message
+}
+
+// Is reports whether target is an Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// IsRetryable reports whether the request that failed with err may be sent again as is
+func IsRetryable(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Retryable
+}
+
+// Err returns the error the response failed with, nil when it succeeded
+func (r *Response) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+
+	// servers without error codes only send the message
+	code := r.Code
+	if code == "" {
+		code = CodeInternal
+	}
+
+	return &Error{
+		Code:      code,
+		Message:   r.Error,
+		Retryable: r.Retryable,
+		Details:   r.Details,
+	}
+}
@@ -0,0 +1,119 @@
+package protocol
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// Version1 is the newline-delimited json protocol and Version2 the length-prefixed binary frames
+const (
+	Version1 = "1.0"
+	Version2 = "2.0"
+)
+
+// a binary frame is laid out as magic, version, header length, body length, header, body,
+// the lengths are big endian and the header is the json encoded Header or responseHeader
+const (
+	frameVersion    byte = 2
+	framePrefixSize      = 12
+
+	// MaxFrameSize is the largest header and body a frame can carry
+	MaxFrameSize = 16 << 20
+)
+
+// frameMagic starts every binary frame, a line of the json protocol never starts with it
+var frameMagic = []byte{'I', 'M', 'Q'}
+
+var (
+	// ErrInvalidFrame is returned when the bytes read are not a binary frame
+	ErrInvalidFrame = errors.New("invalid frame")
+	// ErrFrameTooLarge is returned when a frame is larger than MaxFrameSize
+	ErrFrameTooLarge = errors.New("frame too large")
+)
+
+// responseHeader is the header of a response frame
+type responseHeader struct {
+	RequestID string    `json:"requestId,omitempty"`
+	Error     string    `json:"error"`
+	Code      ErrorCode `json:"code,omitempty"`
+	Retryable bool      `json:"retryable,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	Event     string    `json:"event,omitempty"`
+}
+
+// WriteFrame writes the header and body as a single binary frame
+func WriteFrame(w io.Writer, header, body []byte) error {
+	if len(header)+len(body) > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+
+	frame := make([]byte, framePrefixSize+len(header)+len(body))
+	copy(frame, frameMagic)
+	frame[3] = frameVersion
+	binary.BigEndian.PutUint32(frame[4:8], uint32(len(header)))
+	binary.BigEndian.PutUint32(frame[8:12], uint32(len(body)))
+	copy(frame[framePrefixSize:], header)
+	copy(frame[framePrefixSize+len(header):], body)
+
+	_, err := w.Write(frame)
+	return err
+}
+
+// ReadFrame reads a single binary frame and returns its header and body
+func ReadFrame(r io.Reader) ([]byte, []byte, error) {
+	prefix := make([]byte, framePrefixSize)
+	if _, err := io.ReadFull(r, prefix); err != nil {
+		return nil, nil, err
+	}
+
+	if string(prefix[:3]) != string(frameMagic) || prefix[3] != frameVersion {
+		return nil, nil, ErrInvalidFrame
+	}
+
+	headerLen := binary.BigEndian.Uint32(prefix[4:8])
+	bodyLen := binary.BigEndian.Uint32(prefix[8:12])
+	if uint64(headerLen)+uint64(bodyLen) > MaxFrameSize {
+		return nil, nil, ErrFrameTooLarge
+	}
+
+	payload := make([]byte, headerLen+bodyLen)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, nil, err
+	}
+
+	return payload[:headerLen], payload[headerLen:], nil
+}
+
+// WriteRequestFrame writes the request as a binary frame, the body is carried as is
+func WriteRequestFrame(w io.Writer, request *Request) error {
+	header, err := json.Marshal(request.Header)
+	if err != nil {
+		return err
+	}
+	return WriteFrame(w, header, []byte(request.Body))
+}
+
+// ReadResponseFrame reads a response sent as a binary frame
+func ReadResponseFrame(r io.Reader) (*Response, error) {
+	header, body, err := ReadFrame(r)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr := responseHeader{}
+	if err := json.Unmarshal(header, &hdr); err != nil {
+		return nil, err
+	}
+
+	return &Response{
+		RequestID: hdr.RequestID,
+		Error:     hdr.Error,
+		Code:      hdr.Code,
+		Retryable: hdr.Retryable,
+		Details:   hdr.Details,
+		Event:     hdr.Event,
+		Body:      body,
+	}, nil
+}
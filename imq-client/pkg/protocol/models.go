@@ -1,5 +1,14 @@
 package protocol
 
+// events a response pushed by the server can carry
+const (
+	// EventMessage marks a response pushed by the server for a newly published message
+	EventMessage = "message"
+	// EventGoingAway is pushed once the server shuts down, the connection is closed after the requests
+	// being processed are answered and no further request is read
+	EventGoingAway = "goingAway"
+)
+
 // Request is accepted request type for IMQ
 type Request struct {
 	Header Header `json:"header"`
@@ -12,10 +21,18 @@ type Header struct {
 	RemoteAddr  string `json:"remoteAddr"`
 	ContentType string `json:"contentType"`
 	Method      string `json:"method"`
+	RequestID   string `json:"requestId,omitempty"`
 }
 
-// Response is accepted response type for IMQ
+// Response is accepted response type for IMQ, RequestID echoes the requestId of the request it answers.
+// A failed response carries the Code of the error, whether the request may be retried as is and,
+// when the error says more than its message, the Details
 type Response struct {
-	Error string `json:"error"`
-	Body  []byte `json:"body"`
+	RequestID string    `json:"requestId,omitempty"`
+	Error     string    `json:"error"`
+	Code      ErrorCode `json:"code,omitempty"`
+	Retryable bool      `json:"retryable,omitempty"`
+	Details   string    `json:"details,omitempty"`
+	Event     string    `json:"event,omitempty"`
+	Body      []byte    `json:"body"`
 }
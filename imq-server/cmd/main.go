@@ -2,21 +2,28 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"os"
 	"os/signal"
-	"sync"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/routes"
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/admin"
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/auth"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/publisher"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/subscriber"
 	"github.com/WinnersonKharsunai/GraduationProject/server/config"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/migration"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/wal"
 	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/server"
 	"github.com/caarlos0/env"
 	"github.com/sirupsen/logrus"
@@ -35,12 +42,38 @@ func main() {
 		log.Fatalf("main: failed to connect to database: %v", err)
 	}
 
-	queueSvc, err := startQueue(db, log)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrateDatabase(log, db, os.Args[2:]); err != nil {
+			log.Fatalf("main: failed to migrate database: %v", err)
+		}
+		return
+	}
+
+	if cfgs.AutoMigrate && cfgs.DbBackend == "mysql" {
+		if err := migrateDatabase(log, db, []string{"up"}); err != nil {
+			log.Fatalf("main: failed to migrate database: %v", err)
+		}
+	}
+
+	clientSvc := domain.NewClient(log, db)
+
+	if len(os.Args) > 1 && os.Args[1] == "client" {
+		if err := addClient(cfgs, clientSvc, os.Args[2:]); err != nil {
+			log.Fatalf("main: failed to add client: %v", err)
+		}
+		return
+	}
+
+	if err := registerClients(log, clientSvc, cfgs.Clients); err != nil {
+		log.Fatalf("main: failed to register clients: %v", err)
+	}
+
+	queueSvc, err := startQueue(db, log, cfgs)
 	if err != nil {
 		log.Fatalf("main: failed to start queue service: %v", err)
 	}
 
-	handler := initializeServiceHandler(log, db, queueSvc)
+	handler := initializeServiceHandler(log, db, queueSvc, clientSvc, cfgs)
 
 	serverr, addr, err := startImqServer(log, cfgs, handler)
 	if err != nil {
@@ -65,27 +98,167 @@ func loadConfigurations() (config.Settings, error) {
 }
 
 func connectToDatabase(cfgs config.Settings) (storage.DatabaseIF, error) {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s", cfgs.DbUserName, cfgs.DbPassword, cfgs.DbHost, cfgs.DbPort, cfgs.DbName)
-	db, err := storage.NewMysqlDB(dsn)
+	switch cfgs.DbBackend {
+	case "mysql":
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true", cfgs.DbUserName, cfgs.DbPassword, cfgs.DbHost, cfgs.DbPort, cfgs.DbName)
+		return storage.NewMysqlDB(dsn)
+	case "file":
+		return storage.NewFileDB(cfgs.DbFile, cfgs.Topics)
+	case "memory":
+		return storage.NewMemoryDB(cfgs.Topics)
+	}
+	return nil, fmt.Errorf("unknown db backend: %v", cfgs.DbBackend)
+}
+
+// migrateDatabase runs the migrate subcommand: up, down [steps] or status
+func migrateDatabase(log *logrus.Logger, db storage.DatabaseIF, args []string) error {
+	mysql, ok := db.(*storage.MysqlDB)
+	if !ok {
+		return errors.New("migrations are only supported by the mysql backend")
+	}
+
+	migrator := migration.NewMigrator(mysql.Cxn, migration.Migrations)
+	ctx := context.Background()
+
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		count, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		log.Infof("main: applied %d migrations", count)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps: %v", args[1])
+			}
+			steps = n
+		}
+
+		count, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		log.Infof("main: reverted %d migrations", count)
+
+	case "status":
+		status, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, s := range status {
+			log.Infof("main: migration %d %v applied: %v %v", s.Version, s.Name, s.Applied, s.AppliedAt)
+		}
+
+	default:
+		return fmt.Errorf("unknown migrate command: %v", command)
+	}
+
+	return nil
+}
+
+// addClient runs the client subcommand: add <name> <role>, the generated api key is printed once
+// and only its hash is stored
+func addClient(cfgs config.Settings, clientSvc domain.ClientServicesIF, args []string) error {
+	if cfgs.DbBackend == "memory" {
+		return errors.New("clients of the memory backend have to be set with CLIENTS")
+	}
+
+	if len(args) != 3 || args[0] != "add" {
+		return errors.New("usage: client add <name> <publisher|subscriber|admin>")
+	}
+
+	apiKey, err := domain.GenerateAPIKey()
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	client, err := clientSvc.RegisterClient(context.Background(), args[1], args[2], apiKey)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("clientId: %d\nrole: %v\napiKey: %v\n", client.ClientID, client.Role, apiKey)
+	return nil
+}
+
+// registerClients registers every name:role:apiKey entry whose api key is not known yet
+func registerClients(log *logrus.Logger, clientSvc domain.ClientServicesIF, clients []string) error {
+	for _, entry := range clients {
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 {
+			return fmt.Errorf("invalid client entry, expected name:role:apiKey: %v", parts[0])
+		}
+
+		// only a key no client authenticates with is registered, a failing database must not register it again
+		_, err := clientSvc.Authenticate(context.Background(), parts[2])
+		if err == nil {
+			continue
+		}
+
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			return err
+		}
+
+		client, err := clientSvc.RegisterClient(context.Background(), parts[0], parts[1], parts[2])
+		if err != nil {
+			return err
+		}
+		log.Infof("main: registered client %v as %v with Id %d", client.Name, client.Role, client.ClientID)
 	}
-	return db, nil
+
+	return nil
 }
 
-func startQueue(db storage.DatabaseIF, log *logrus.Logger) (queue.ImqQueueIF, error) {
-	queueSvc, err := queue.NewQueue(log, db)
+func startQueue(db storage.DatabaseIF, log *logrus.Logger, cfgs config.Settings) (queue.ImqQueueIF, error) {
+	opts := queue.Options{
+		VisibilityTimeout: time.Duration(time.Second * time.Duration(cfgs.VisibilityTimeout)),
+		MaxDeliveries:     cfgs.MaxDeliveries,
+		MaxMessages:       cfgs.MaxTopicMessages,
+		MaxBytes:          cfgs.MaxTopicBytes,
+		Overflow:          queue.OverflowPolicy(cfgs.OverflowPolicy),
+		OverflowTimeout:   time.Duration(time.Second * time.Duration(cfgs.OverflowTimeout)),
+	}
+
+	if cfgs.WALDir != "" {
+		walLog, err := wal.NewLog(cfgs.WALDir, cfgs.WALSegmentSize)
+		if err != nil {
+			return nil, err
+		}
+		opts.Log = walLog
+	}
+
+	queueSvc, err := queue.NewQueue(log, db, opts)
 	if err != nil {
 		return nil, err
 	}
 	return queueSvc, nil
 }
 
-func initializeServiceHandler(log *logrus.Logger, db storage.DatabaseIF, qSvc queue.ImqQueueIF) routes.Router {
-	topicSvc := domain.NewTopic(log, db, qSvc)
+func initializeServiceHandler(log *logrus.Logger, db storage.DatabaseIF, qSvc queue.ImqQueueIF, clientSvc domain.ClientServicesIF, cfgs config.Settings) routes.Router {
+	opts := domain.TopicOptions{
+		DefaultTTL: time.Duration(time.Second * time.Duration(cfgs.DefaultTTL)),
+		MaxTTL:     time.Duration(time.Second * time.Duration(cfgs.MaxTTL)),
+	}
+
+	topicSvc := domain.NewTopic(log, db, qSvc, opts)
 	publisherSvc := publisher.NewPublisher(log, topicSvc)
 	subscriberSvc := subscriber.NewSubscriber(log, topicSvc)
-	return routes.NewHandler(publisherSvc, subscriberSvc)
+	adminSvc := admin.NewAdmin(log, topicSvc)
+	authSvc := auth.NewAuth(log, clientSvc)
+	return routes.NewHandler(publisherSvc, subscriberSvc, adminSvc, authSvc)
 }
 
 func startImqServer(log *logrus.Logger, cfgs config.Settings, handler routes.Router) (*server.Server, string, error) {
@@ -96,7 +269,23 @@ func startImqServer(log *logrus.Logger, cfgs config.Settings, handler routes.Rou
 		return nil, addr, err
 	}
 
-	s := server.NewServer(log, lis, cfgs.PublisherCount, cfgs.SubscriberCount, handler)
+	if cfgs.TLSCertFile != "" {
+		tlsConfig, err := server.NewTLSConfig(cfgs.TLSCertFile, cfgs.TLSKeyFile, cfgs.TLSClientCAFile, cfgs.TLSRequireClientCert)
+		if err != nil {
+			lis.Close()
+			return nil, addr, err
+		}
+		lis = tls.NewListener(lis, tlsConfig)
+		log.Infof("main: tls enabled, client certificates verified: %v", cfgs.TLSClientCAFile != "")
+	}
+
+	opts := server.Options{
+		MaxConnections: cfgs.MaxConnections,
+		ReadTimeout:    time.Duration(time.Second * time.Duration(cfgs.ReadTimeout)),
+		IdleTimeout:    time.Duration(time.Second * time.Duration(cfgs.IdleTimeout)),
+	}
+
+	s := server.NewServer(log, lis, opts, handler)
 	log.Infof("main: imq-server running on port: %v", addr)
 
 	go func() {
@@ -115,26 +304,21 @@ func gracefulShutdown(log *logrus.Logger, addr string, servr *server.Server, que
 	case <-shutdown:
 		log.Infof("main: shutting down imq-server")
 		grace := time.Duration(time.Second * time.Duration(shutdownGrace))
+
+		// the clients are drained first so that the messages they are still publishing are backed up
 		ctx, cancel := context.WithTimeout(context.Background(), grace)
 		defer cancel()
 
-		var wg sync.WaitGroup
-		wg.Add(2)
+		if err := servr.Shutdown(ctx); err != nil {
+			log.Warnf("main: graceful shutdown failed: %v", err)
+		}
 
-		go func() {
-			if err := queueSvc.BackUpQueue(ctx); err != nil {
-				log.Warnf("main: graceful shutdown failed: %v", err)
-			}
-			wg.Done()
-		}()
+		backupCtx, backupCancel := context.WithTimeout(context.Background(), grace)
+		defer backupCancel()
 
-		go func() {
-			if err := servr.Shutdown(ctx); err != nil {
-				log.Warnf("main: graceful shutdown failed: %v", err)
-			}
-			wg.Done()
-		}()
-		wg.Wait()
+		if err := queueSvc.BackUpQueue(backupCtx); err != nil {
+			log.Warnf("main: graceful shutdown failed: %v", err)
+		}
 
 		log.Infof("main: imq-server stopped: %v", addr)
 	}
@@ -1,12 +1,122 @@
 package routes
 
+import (
+	"errors"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
 const (
+	authenticate         = "authenticateRequest"
 	showTopic            = "showTopicRequest"
 	connectToTopic       = "connectToTopicRequest"
 	disconnectFromTopic  = "disconnectFromTopicRequest"
 	publishMessage       = "publishMessageRequest"
+	publishBatch         = "publishBatchRequest"
 	subscribeToTopic     = "subscribeToTopicRequest"
 	unsubscribeFromTopic = "unsubscribeFromTopicRequest"
 	getSubscribedTopics  = "getSubscribedTopicsRequest"
 	getMessageFromTopic  = "getMessageFromTopicRequest"
+	getMessagesFromTopic = "getMessagesFromTopicRequest"
+	watchTopic           = "watchTopicRequest"
+	unwatchTopic         = "unwatchTopicRequest"
+	ackMessage           = "ackMessageRequest"
+	nackMessage          = "nackMessageRequest"
+	listDeadMessages     = "listDeadMessagesRequest"
+	purgeDeadMessages    = "purgeDeadMessagesRequest"
+	replayDeadMessages   = "replayDeadMessagesRequest"
+	createTopic          = "createTopicRequest"
+	updateTopicTTL       = "updateTopicTtlRequest"
+	updateTopicLimits    = "updateTopicLimitsRequest"
+	renameTopic          = "renameTopicRequest"
+	deleteTopic          = "deleteTopicRequest"
+	describeTopic        = "describeTopicRequest"
+	listConsumerGroups   = "listConsumerGroupsRequest"
+)
+
+var (
+	errUnauthenticated      = errors.New("unauthenticated")
+	errPermissionDenied     = errors.New("permission denied")
+	errMethodUnimplemented  = errors.New("method unimplemented")
+	errUnknownContentType   = errors.New("unknown content-type")
+	errInvalidBody          = errors.New("invalid request body")
+	errStreamingUnsupported = errors.New("streaming is not supported on this connection")
 )
+
+// methodRoles holds the roles allowed to call each method
+var methodRoles = map[string][]string{
+	showTopic:            {domain.RolePublisher, domain.RoleSubscriber, domain.RoleAdmin},
+	connectToTopic:       {domain.RolePublisher},
+	disconnectFromTopic:  {domain.RolePublisher},
+	publishMessage:       {domain.RolePublisher},
+	publishBatch:         {domain.RolePublisher},
+	subscribeToTopic:     {domain.RoleSubscriber},
+	unsubscribeFromTopic: {domain.RoleSubscriber},
+	getSubscribedTopics:  {domain.RoleSubscriber},
+	getMessageFromTopic:  {domain.RoleSubscriber},
+	getMessagesFromTopic: {domain.RoleSubscriber},
+	watchTopic:           {domain.RoleSubscriber},
+	unwatchTopic:         {domain.RoleSubscriber},
+	ackMessage:           {domain.RoleSubscriber},
+	nackMessage:          {domain.RoleSubscriber},
+	listDeadMessages:     {domain.RoleAdmin},
+	purgeDeadMessages:    {domain.RoleAdmin},
+	replayDeadMessages:   {domain.RoleAdmin},
+	createTopic:          {domain.RoleAdmin},
+	updateTopicTTL:       {domain.RoleAdmin},
+	updateTopicLimits:    {domain.RoleAdmin},
+	renameTopic:          {domain.RoleAdmin},
+	deleteTopic:          {domain.RoleAdmin},
+	describeTopic:        {domain.RoleAdmin},
+	listConsumerGroups:   {domain.RoleAdmin},
+}
+
+// errorCode is the code a sentinel error is reported with and whether the request may be retried as is
+type errorCode struct {
+	err       error
+	code      protocol.ErrorCode
+	retryable bool
+}
+
+// errorCodes holds the code of every sentinel error a response can report, any other error is reported as internal
+var errorCodes = []errorCode{
+	{err: domain.ErrTopicNotFound, code: protocol.CodeNotFound},
+	{err: domain.ErrNotRegistered, code: protocol.CodeNotFound},
+	{err: domain.ErrNotConnected, code: protocol.CodeNotFound},
+	{err: domain.ErrNotSubscribed, code: protocol.CodeNotFound},
+	{err: queue.ErrNotAwaitingAck, code: protocol.CodeNotFound},
+	{err: domain.ErrTopicExists, code: protocol.CodeAlreadyExists},
+	{err: domain.ErrAlreadyRegistered, code: protocol.CodeAlreadyExists},
+	{err: domain.ErrAlreadySubscribed, code: protocol.CodeAlreadyExists},
+	{err: errUnauthenticated, code: protocol.CodeUnauthorized},
+	{err: errPermissionDenied, code: protocol.CodeUnauthorized},
+	{err: domain.ErrInvalidCredentials, code: protocol.CodeUnauthorized},
+	{err: domain.ErrInvalidTopicName, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidPattern, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidFilter, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidGroupName, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidPartitions, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrTopicNotEmpty, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidBatchSize, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrTopicRequired, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidTTL, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrTTLTooLong, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidTopicTTL, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrInvalidLimits, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrKeyTooLong, code: protocol.CodeInvalidArgument},
+	{err: domain.ErrContentTypeTooLong, code: protocol.CodeInvalidArgument},
+	{err: queue.ErrNoTopic, code: protocol.CodeInvalidArgument},
+	{err: queue.ErrEmptyMessage, code: protocol.CodeInvalidArgument},
+	{err: queue.ErrMessageTooLarge, code: protocol.CodeInvalidArgument},
+	{err: protocol.ErrInvalidVersion, code: protocol.CodeInvalidArgument},
+	{err: protocol.ErrUnsupportedContentType, code: protocol.CodeInvalidArgument},
+	{err: errMethodUnimplemented, code: protocol.CodeInvalidArgument},
+	{err: errUnknownContentType, code: protocol.CodeInvalidArgument},
+	{err: errInvalidBody, code: protocol.CodeInvalidArgument},
+	{err: errStreamingUnsupported, code: protocol.CodeUnavailable},
+	{err: queue.ErrPersistFailed, code: protocol.CodeUnavailable, retryable: true},
+	{err: queue.ErrQueueEmpty, code: protocol.CodeQueueEmpty, retryable: true},
+	{err: queue.ErrQueueFull, code: protocol.CodeQueueFull, retryable: true},
+}
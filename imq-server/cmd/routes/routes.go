@@ -1,11 +1,17 @@
 package routes
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"encoding/json"
 	"encoding/xml"
 	"errors"
+	"fmt"
+	"sort"
 
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/admin"
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/auth"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/publisher"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/subscriber"
 	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
@@ -15,52 +21,100 @@ import (
 type Handler struct {
 	pSvc publisher.PublisherIF
 	sSvc subscriber.SubscriberIF
+	aSvc admin.AdminIF
+	auth auth.AuthIF
 }
 
 // Router is the interface for the Handler type
 type Router interface {
-	RequestRouter(ctx context.Context, r string) *protocol.Response
+	Authenticate(ctx context.Context, request *protocol.Request) (context.Context, *protocol.Response)
+	RequestRouter(ctx context.Context, request *protocol.Request) *protocol.Response
 }
 
 // NewHandler is the factory function for the Handler type
-func NewHandler(pSvc publisher.PublisherIF, sSvc subscriber.SubscriberIF) Router {
+func NewHandler(pSvc publisher.PublisherIF, sSvc subscriber.SubscriberIF, aSvc admin.AdminIF, authSvc auth.AuthIF) Router {
 	return &Handler{
 		pSvc: pSvc,
 		sSvc: sSvc,
+		aSvc: aSvc,
+		auth: authSvc,
 	}
 }
 
-// RequestRouter handles all the request and response
-func (h Handler) RequestRouter(ctx context.Context, r string) *protocol.Response {
-	request := protocol.Request{}
-	if err := json.Unmarshal([]byte(r), &request); err != nil {
-		return &protocol.Response{Error: err.Error()}
+// Authenticate handles the handshake request a client has to send first, on success
+// the returned context carries the session of the client for every following request
+func (h Handler) Authenticate(ctx context.Context, request *protocol.Request) (context.Context, *protocol.Response) {
+	if err := request.Header.ValidateRequestHeader(ctx); err != nil {
+		return ctx, errorResponse(err)
+	}
+
+	if request.Header.Method != authenticate {
+		return ctx, errorResponse(errUnauthenticated)
+	}
+
+	authenticateRequest := &auth.AuthenticateRequest{}
+	if err := unmarshal([]byte(request.Body), authenticateRequest, request.Header.ContentType); err != nil {
+		return ctx, errorResponse(err)
+	}
+	authenticateRequest.CertificateSubject, _ = protocol.PeerFromContext(ctx)
+
+	resp, err := h.auth.Authenticate(ctx, authenticateRequest)
+	if err != nil {
+		return ctx, errorResponse(err)
+	}
+	resp.Permissions = permissions(resp.Role)
+
+	body, err := marshal(resp, request.Header.ContentType)
+	if err != nil {
+		return ctx, errorResponse(err)
+	}
+
+	session := &protocol.Session{
+		ClientID: resp.ClientID,
+		Role:     resp.Role,
 	}
 
+	return protocol.NewSessionContext(ctx, session), &protocol.Response{Body: body}
+}
+
+// RequestRouter handles all the request and response
+func (h Handler) RequestRouter(ctx context.Context, request *protocol.Request) *protocol.Response {
 	if err := request.Header.ValidateRequestHeader(ctx); err != nil {
-		return &protocol.Response{Error: err.Error()}
+		return errorResponse(err)
 	}
 
-	resp, err := processRequest(ctx, h.pSvc, h.sSvc, request)
+	session, ok := protocol.SessionFromContext(ctx)
+	if !ok {
+		return errorResponse(errUnauthenticated)
+	}
+
+	if !authorized(session.Role, request.Header.Method) {
+		return errorResponse(errPermissionDenied)
+	}
+
+	resp, err := processRequest(ctx, h.pSvc, h.sSvc, h.aSvc, *request, session.ClientID)
 	if err != nil {
-		return &protocol.Response{Error: err.Error()}
+		return errorResponse(err)
 	}
 
 	body, err := marshal(resp, request.Header.ContentType)
 	if err != nil {
-		return &protocol.Response{Error: err.Error()}
+		return errorResponse(err)
 	}
 
 	return &protocol.Response{Body: body}
 }
 
-func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.SubscriberIF, request protocol.Request) (interface{}, error) {
+// processRequest calls the service for the requested method, the client id in the request body
+// is always replaced with the one of the session so that clients cannot act as one another
+func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.SubscriberIF, a admin.AdminIF, request protocol.Request, clientID int) (interface{}, error) {
 	switch request.Header.Method {
 	case showTopic:
 		showTopicRequest := &publisher.ShowTopicRequest{}
 		if err := unmarshal([]byte(request.Body), showTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		showTopicRequest.PublisherID = clientID
 		return p.ShowTopics(ctx, showTopicRequest)
 
 	case connectToTopic:
@@ -68,6 +122,7 @@ func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.S
 		if err := unmarshal([]byte(request.Body), connectToTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		connectToTopicRequest.PublisherID = clientID
 		return p.ConnectToTopic(ctx, connectToTopicRequest)
 
 	case disconnectFromTopic:
@@ -75,6 +130,7 @@ func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.S
 		if err := unmarshal([]byte(request.Body), disconnectFromTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		disconnectFromTopicRequest.PublisherID = clientID
 		return p.DisconnectFromTopic(ctx, disconnectFromTopicRequest)
 
 	case publishMessage:
@@ -82,20 +138,39 @@ func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.S
 		if err := unmarshal([]byte(request.Body), publishMessageRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		publishMessageRequest.PublisherID = clientID
 		return p.PublishMessage(ctx, publishMessageRequest)
 
+	case publishBatch:
+		publishBatchRequest := &publisher.PublishBatchRequest{}
+		if err := unmarshal([]byte(request.Body), publishBatchRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		publishBatchRequest.PublisherID = clientID
+		return p.PublishBatch(ctx, publishBatchRequest)
+
 	case subscribeToTopic:
 		subscribeToTopicRequest := &subscriber.SubscribeToTopicRequest{}
 		if err := unmarshal([]byte(request.Body), subscribeToTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
-		return s.SubscribeToTopic(ctx, subscribeToTopicRequest)
+		subscribeToTopicRequest.SubscriberID = clientID
+		subscribeToTopicResponse, err := s.SubscribeToTopic(ctx, subscribeToTopicRequest)
+		if err != nil {
+			return nil, err
+		}
+		// the subscription is already stored, a subscriber that cannot be streamed to can still poll
+		if _, err := streamTopic(ctx, s, &subscriber.WatchTopicRequest{SubscriberID: subscribeToTopicRequest.SubscriberID, TopicName: subscribeToTopicRequest.TopicName}, request.Header.ContentType); err != nil {
+			subscribeToTopicResponse.StreamError = err.Error()
+		}
+		return subscribeToTopicResponse, nil
 
 	case unsubscribeFromTopic:
 		unsubscribeFromTopicRequest := &subscriber.UnsubscribeFromTopicRequest{}
 		if err := unmarshal([]byte(request.Body), unsubscribeFromTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		unsubscribeFromTopicRequest.SubscriberID = clientID
 		return s.UnsubscribeFromTopic(ctx, unsubscribeFromTopicRequest)
 
 	case getSubscribedTopics:
@@ -103,6 +178,7 @@ func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.S
 		if err := unmarshal([]byte(request.Body), getSubscribedTopicsRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		getSubscribedTopicsRequest.SubscriberID = clientID
 		return s.GetSubscribedTopics(ctx, getSubscribedTopicsRequest)
 
 	case getMessageFromTopic:
@@ -110,22 +186,202 @@ func processRequest(ctx context.Context, p publisher.PublisherIF, s subscriber.S
 		if err := unmarshal([]byte(request.Body), getMessageFromTopicRequest, request.Header.ContentType); err != nil {
 			return nil, err
 		}
+		getMessageFromTopicRequest.SubscriberID = clientID
 		return s.GetMessageFromTopic(ctx, getMessageFromTopicRequest)
 
+	case getMessagesFromTopic:
+		getMessagesFromTopicRequest := &subscriber.GetMessagesFromTopicRequest{}
+		if err := unmarshal([]byte(request.Body), getMessagesFromTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		getMessagesFromTopicRequest.SubscriberID = clientID
+		return s.GetMessagesFromTopic(ctx, getMessagesFromTopicRequest)
+
+	case watchTopic:
+		watchTopicRequest := &subscriber.WatchTopicRequest{}
+		if err := unmarshal([]byte(request.Body), watchTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		watchTopicRequest.SubscriberID = clientID
+		return streamTopic(ctx, s, watchTopicRequest, request.Header.ContentType)
+
+	case unwatchTopic:
+		unwatchTopicRequest := &subscriber.UnwatchTopicRequest{}
+		if err := unmarshal([]byte(request.Body), unwatchTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		unwatchTopicRequest.SubscriberID = clientID
+		return s.UnwatchTopic(ctx, unwatchTopicRequest)
+
+	case ackMessage:
+		ackMessageRequest := &subscriber.AckMessageRequest{}
+		if err := unmarshal([]byte(request.Body), ackMessageRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		ackMessageRequest.SubscriberID = clientID
+		return s.AckMessage(ctx, ackMessageRequest)
+
+	case nackMessage:
+		nackMessageRequest := &subscriber.NackMessageRequest{}
+		if err := unmarshal([]byte(request.Body), nackMessageRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		nackMessageRequest.SubscriberID = clientID
+		return s.NackMessage(ctx, nackMessageRequest)
+
+	case listDeadMessages:
+		listDeadMessagesRequest := &admin.ListDeadMessagesRequest{}
+		if err := unmarshal([]byte(request.Body), listDeadMessagesRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		listDeadMessagesRequest.AdminID = clientID
+		return a.ListDeadMessages(ctx, listDeadMessagesRequest)
+
+	case purgeDeadMessages:
+		purgeDeadMessagesRequest := &admin.PurgeDeadMessagesRequest{}
+		if err := unmarshal([]byte(request.Body), purgeDeadMessagesRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		purgeDeadMessagesRequest.AdminID = clientID
+		return a.PurgeDeadMessages(ctx, purgeDeadMessagesRequest)
+
+	case replayDeadMessages:
+		replayDeadMessagesRequest := &admin.ReplayDeadMessagesRequest{}
+		if err := unmarshal([]byte(request.Body), replayDeadMessagesRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		replayDeadMessagesRequest.AdminID = clientID
+		return a.ReplayDeadMessages(ctx, replayDeadMessagesRequest)
+
+	case createTopic:
+		createTopicRequest := &admin.CreateTopicRequest{}
+		if err := unmarshal([]byte(request.Body), createTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		createTopicRequest.AdminID = clientID
+		return a.CreateTopic(ctx, createTopicRequest)
+
+	case updateTopicTTL:
+		updateTopicTTLRequest := &admin.UpdateTopicTTLRequest{}
+		if err := unmarshal([]byte(request.Body), updateTopicTTLRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		updateTopicTTLRequest.AdminID = clientID
+		return a.UpdateTopicTTL(ctx, updateTopicTTLRequest)
+
+	case updateTopicLimits:
+		updateTopicLimitsRequest := &admin.UpdateTopicLimitsRequest{}
+		if err := unmarshal([]byte(request.Body), updateTopicLimitsRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		updateTopicLimitsRequest.AdminID = clientID
+		return a.UpdateTopicLimits(ctx, updateTopicLimitsRequest)
+
+	case renameTopic:
+		renameTopicRequest := &admin.RenameTopicRequest{}
+		if err := unmarshal([]byte(request.Body), renameTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		renameTopicRequest.AdminID = clientID
+		return a.RenameTopic(ctx, renameTopicRequest)
+
+	case deleteTopic:
+		deleteTopicRequest := &admin.DeleteTopicRequest{}
+		if err := unmarshal([]byte(request.Body), deleteTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		deleteTopicRequest.AdminID = clientID
+		return a.DeleteTopic(ctx, deleteTopicRequest)
+
+	case describeTopic:
+		describeTopicRequest := &admin.DescribeTopicRequest{}
+		if err := unmarshal([]byte(request.Body), describeTopicRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		describeTopicRequest.AdminID = clientID
+		return a.DescribeTopic(ctx, describeTopicRequest)
+
+	case listConsumerGroups:
+		listConsumerGroupsRequest := &admin.ListConsumerGroupsRequest{}
+		if err := unmarshal([]byte(request.Body), listConsumerGroupsRequest, request.Header.ContentType); err != nil {
+			return nil, err
+		}
+		listConsumerGroupsRequest.AdminID = clientID
+		return a.ListConsumerGroups(ctx, listConsumerGroupsRequest)
+
 	default:
-		return nil, errors.New("method unimplemented")
+		return nil, errMethodUnimplemented
+	}
+}
+
+// authorized reports whether the role may call the method, unknown methods are left
+// for processRequest to reject
+func authorized(role string, method string) bool {
+	roles, ok := methodRoles[method]
+	if !ok {
+		return true
+	}
+
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// errorResponse reports err with the code of the sentinel error it wraps, the message of the sentinel
+// is sent as the error and the full error as the details when it says more
+func errorResponse(err error) *protocol.Response {
+	for _, c := range errorCodes {
+		if !errors.Is(err, c.err) {
+			continue
+		}
+
+		response := &protocol.Response{
+			Error:     c.err.Error(),
+			Code:      c.code,
+			Retryable: c.retryable,
+		}
+		if err.Error() != c.err.Error() {
+			response.Details = err.Error()
+		}
+		return response
+	}
+
+	return &protocol.Response{Error: err.Error(), Code: protocol.CodeInternal}
+}
+
+// permissions lists the methods the role may call
+func permissions(role string) []string {
+	methods := []string{}
+	for method := range methodRoles {
+		if authorized(role, method) {
+			methods = append(methods, method)
+		}
 	}
+	sort.Strings(methods)
+	return methods
 }
 
+// unmarshal decodes the request body, a body that cannot be decoded is reported as errInvalidBody
 func unmarshal(data []byte, v interface{}, contentType string) error {
+	var err error
 	switch contentType {
 	case "json":
-		return json.Unmarshal(data, v)
+		err = json.Unmarshal(data, v)
 	case "xml":
-		return xml.Unmarshal(data, v)
+		err = xml.Unmarshal(data, v)
+	case "gob":
+		err = gob.NewDecoder(bytes.NewReader(data)).Decode(v)
 	default:
-		return errors.New("unknown content-type")
+		return errUnknownContentType
+	}
+
+	if err != nil {
+		return fmt.Errorf("%w: %v", errInvalidBody, err)
 	}
+	return nil
 }
 
 func marshal(v interface{}, contentType string) ([]byte, error) {
@@ -134,7 +390,13 @@ func marshal(v interface{}, contentType string) ([]byte, error) {
 		return json.Marshal(v)
 	case "xml":
 		return xml.Marshal(v)
+	case "gob":
+		buf := &bytes.Buffer{}
+		if err := gob.NewEncoder(buf).Encode(v); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
 	default:
-		return nil, errors.New("unknown content-type")
+		return nil, errUnknownContentType
 	}
 }
@@ -1,13 +1,22 @@
 package routes_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"reflect"
 	"testing"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/routes"
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/admin"
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/auth"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/publisher"
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/subscriber"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
 	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
 	"github.com/WinnersonKharsunai/GraduationProject/server/test"
 	"github.com/stretchr/testify/mock"
@@ -33,15 +42,15 @@ func TestRequestRouter_ShowTopic(t *testing.T) {
 
 	hdr.Method = "showTopicRequest"
 
-	request := getRequestString(hdr, showTopicRequest)
+	request := getRequest(hdr, showTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockPsvc.Given(publisher.PublisherIF.ShowTopics).When(mock.Anything, showTopicRequest).Return(showTopicResponse, nil)
 	mockSsvc := &test.MockSubscriberIF{}
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
@@ -58,15 +67,15 @@ func TestRequestRouter_ConnectToTopicRequest(t *testing.T) {
 
 	hdr.Method = "connectToTopicRequest"
 
-	request := getRequestString(hdr, connectToTopicRequest)
+	request := getRequest(hdr, connectToTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockPsvc.Given(publisher.PublisherIF.ConnectToTopic).When(mock.Anything, connectToTopicRequest).Return(connectToTopicResponse, nil)
 	mockSsvc := &test.MockSubscriberIF{}
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
@@ -83,15 +92,15 @@ func TestRequestRouter_DisconnectFromTopicRequest(t *testing.T) {
 
 	hdr.Method = "disconnectFromTopicRequest"
 
-	request := getRequestString(hdr, disconnectFromTopicRequest)
+	request := getRequest(hdr, disconnectFromTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockPsvc.Given(publisher.PublisherIF.DisconnectFromTopic).When(mock.Anything, disconnectFromTopicRequest).Return(disconnectFromTopicResponse, nil)
 	mockSsvc := &test.MockSubscriberIF{}
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
@@ -100,9 +109,8 @@ func TestRequestRouter_PublishMessageRequest(t *testing.T) {
 	publishMessageRequest := &publisher.PublishMessageRequest{
 		PublisherID: 600,
 		Message: publisher.Message{
-			Data:      "test data",
-			CretedAt:  "2021-02-27 20:03:09",
-			ExpiresAt: "2021-02-27 20:04:09",
+			Data: []byte("test data"),
+			TTL:  60,
 		},
 	}
 
@@ -112,19 +120,56 @@ func TestRequestRouter_PublishMessageRequest(t *testing.T) {
 
 	hdr.Method = "publishMessageRequest"
 
-	request := getRequestString(hdr, publishMessageRequest)
+	request := getRequest(hdr, publishMessageRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockPsvc.Given(publisher.PublisherIF.PublishMessage).When(mock.Anything, publishMessageRequest).Return(publishMessageResponse, nil)
 	mockSsvc := &test.MockSubscriberIF{}
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 600), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+func TestRequestRouter_PublishBatchRequest(t *testing.T) {
+	publishBatchRequest := &publisher.PublishBatchRequest{
+		PublisherID: 600,
+		Messages: []publisher.Message{
+			{Data: []byte("test data 1")},
+			{Data: []byte("test data 2")},
+		},
+	}
+
+	publishBatchResponse := &publisher.PublishBatchResponse{
+		Results: []publisher.PublishResult{
+			{MessageID: "message1", Status: "successful"},
+			{MessageID: "message2", Status: "successful"},
+		},
+	}
+
+	hdr.Method = "publishBatchRequest"
+
+	request := getRequest(hdr, publishBatchRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.PublishBatch).When(mock.Anything, publishBatchRequest).Return(publishBatchResponse, nil)
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 600), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
+
+	got := &publisher.PublishBatchResponse{}
+	json.Unmarshal(resp.Body, got)
+	if !reflect.DeepEqual(publishBatchResponse, got) {
+		t.Fatalf("\necpected: %v \n\t got: %v", publishBatchResponse, got)
+	}
 }
+
 func TestRequestRouter_SubscribeToTopicRequest(t *testing.T) {
 	subscribeToTopicRequest := &subscriber.SubscribeToTopicRequest{
 		SubscriberID: 6000,
@@ -137,20 +182,66 @@ func TestRequestRouter_SubscribeToTopicRequest(t *testing.T) {
 
 	hdr.Method = "subscribeToTopicRequest"
 
-	request := getRequestString(hdr, subscribeToTopicRequest)
+	request := getRequest(hdr, subscribeToTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 
 	mockSsvc := &test.MockSubscriberIF{}
 	mockSsvc.Given(subscriber.SubscriberIF.SubscribeToTopic).When(mock.Anything, subscribeToTopicRequest).Return(subscribeToTopicResponse, nil)
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
+
+	// the subscription is kept when the topic cannot be streamed
+	got := &subscriber.SubscribeToTopicResponse{}
+	json.Unmarshal(resp.Body, got)
+	if got.StreamError != "streaming is not supported on this connection" {
+		t.Fatalf("\necpected: %v \n\t got: %v", "streaming is not supported on this connection", got.StreamError)
+	}
+}
+
+func TestRequestRouter_SubscribeToTopicRequest_WatchFailed(t *testing.T) {
+	subscribeToTopicRequest := &subscriber.SubscribeToTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+	}
+
+	watchTopicRequest := &subscriber.WatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+	}
+
+	hdr.Method = "subscribeToTopicRequest"
+
+	request := getRequest(hdr, subscribeToTopicRequest)
+
+	stream := &testStream{done: make(chan struct{})}
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.SubscribeToTopic).When(mock.Anything, subscribeToTopicRequest).Return(&subscriber.SubscribeToTopicResponse{Status: "subscribed"}, nil)
+	mockSsvc.Given(subscriber.SubscriberIF.WatchTopic).When(mock.Anything, watchTopicRequest, mock.Anything).Return(&subscriber.WatchTopicResponse{}, errors.New("failed to watch topic"))
+
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(protocol.NewStreamContext(context.Background(), stream), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+
+	expected := &subscriber.SubscribeToTopicResponse{Status: "subscribed", StreamError: "failed to watch topic"}
+
+	got := &subscriber.SubscribeToTopicResponse{}
+	json.Unmarshal(resp.Body, got)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("\necpected: %v \n\t got: %v", expected, got)
+	}
 }
+
 func TestRequestRouter_UnsubscribeFromTopicRequest(t *testing.T) {
 	unsubscribeFromTopicRequest := &subscriber.UnsubscribeFromTopicRequest{
 		SubscriberID: 6000,
@@ -163,15 +254,15 @@ func TestRequestRouter_UnsubscribeFromTopicRequest(t *testing.T) {
 
 	hdr.Method = "unsubscribeFromTopicRequest"
 
-	request := getRequestString(hdr, unsubscribeFromTopicRequest)
+	request := getRequest(hdr, unsubscribeFromTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockSsvc := &test.MockSubscriberIF{}
 	mockSsvc.Given(subscriber.SubscriberIF.UnsubscribeFromTopic).When(mock.Anything, unsubscribeFromTopicRequest).Return(unsubscribeFromTopicResponse, nil)
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
@@ -187,15 +278,15 @@ func TestRequestRouter_GetSubscribedTopicsRequest(t *testing.T) {
 
 	hdr.Method = "getSubscribedTopicsRequest"
 
-	request := getRequestString(hdr, getSubscribedTopicsRequest)
+	request := getRequest(hdr, getSubscribedTopicsRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockSsvc := &test.MockSubscriberIF{}
 	mockSsvc.Given(subscriber.SubscriberIF.GetSubscribedTopics).When(mock.Anything, getSubscribedTopicsRequest).Return(getSubscribedTopicsResponse, nil)
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
 
-	resp := route.RequestRouter(context.Background(), request)
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
@@ -208,32 +299,671 @@ func TestRequestRouter_GetMessageFromTopicRequest(t *testing.T) {
 
 	getMessageFromTopicResponse := &subscriber.GetMessageFromTopicResponse{
 		Message: subscriber.Message{
-			Data: "test data",
+			Data: []byte("test data"),
 		},
 	}
 
 	hdr.Method = "getMessageFromTopicRequest"
 
-	request := getRequestString(hdr, getMessageFromTopicRequest)
+	request := getRequest(hdr, getMessageFromTopicRequest)
 
 	mockPsvc := &test.MockPublisherIF{}
 	mockSsvc := &test.MockSubscriberIF{}
 	mockSsvc.Given(subscriber.SubscriberIF.GetMessageFromTopic).When(mock.Anything, getMessageFromTopicRequest).Return(getMessageFromTopicResponse, nil)
 
-	route := routes.NewHandler(mockPsvc, mockSsvc)
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_GetMessagesFromTopicRequest(t *testing.T) {
+	getMessagesFromTopicRequest := &subscriber.GetMessagesFromTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+		MaxCount:     10,
+		MaxBytes:     4096,
+	}
+
+	getMessagesFromTopicResponse := &subscriber.GetMessagesFromTopicResponse{
+		Messages: []subscriber.Message{
+			{Data: []byte("test data 1")},
+			{Data: []byte("test data 2")},
+		},
+	}
+
+	hdr.Method = "getMessagesFromTopicRequest"
+
+	request := getRequest(hdr, getMessagesFromTopicRequest)
+
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.GetMessagesFromTopic).When(mock.Anything, getMessagesFromTopicRequest).Return(getMessagesFromTopicResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_AckMessageRequest(t *testing.T) {
+	ackMessageRequest := &subscriber.AckMessageRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+		MessageID:    "123",
+	}
+
+	ackMessageResponse := &subscriber.AckMessageResponse{
+		Status: "acknowledged",
+	}
+
+	hdr.Method = "ackMessageRequest"
+
+	request := getRequest(hdr, ackMessageRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.AckMessage).When(mock.Anything, ackMessageRequest).Return(ackMessageResponse, nil)
+
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_NackMessageRequest(t *testing.T) {
+	nackMessageRequest := &subscriber.NackMessageRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+		MessageID:    "123",
+	}
+
+	nackMessageResponse := &subscriber.NackMessageResponse{
+		Status: "requeued",
+	}
+
+	hdr.Method = "nackMessageRequest"
+
+	request := getRequest(hdr, nackMessageRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.NackMessage).When(mock.Anything, nackMessageRequest).Return(nackMessageResponse, nil)
+
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_ListDeadMessagesRequest(t *testing.T) {
+	listDeadMessagesRequest := &admin.ListDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "java",
+	}
+
+	listDeadMessagesResponse := &admin.ListDeadMessagesResponse{
+		Messages: []admin.DeadMessage{
+			{
+				MessageID: "123",
+				Reason:    "expired",
+			},
+		},
+	}
+
+	hdr.Method = "listDeadMessagesRequest"
+
+	request := getRequest(hdr, listDeadMessagesRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.ListDeadMessages).When(mock.Anything, listDeadMessagesRequest).Return(listDeadMessagesResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_ReplayDeadMessagesRequest(t *testing.T) {
+	replayDeadMessagesRequest := &admin.ReplayDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "java",
+	}
+
+	replayDeadMessagesResponse := &admin.ReplayDeadMessagesResponse{
+		Status: "replayed",
+		Count:  1,
+	}
+
+	hdr.Method = "replayDeadMessagesRequest"
+
+	request := getRequest(hdr, replayDeadMessagesRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.ReplayDeadMessages).When(mock.Anything, replayDeadMessagesRequest).Return(replayDeadMessagesResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_CreateTopicRequest(t *testing.T) {
+	createTopicRequest := &admin.CreateTopicRequest{
+		AdminID:   7000,
+		TopicName: "rust",
+	}
+
+	createTopicResponse := &admin.CreateTopicResponse{
+		Status: "created",
+	}
+
+	hdr.Method = "createTopicRequest"
+
+	request := getRequest(hdr, createTopicRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.CreateTopic).When(mock.Anything, createTopicRequest).Return(createTopicResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_UpdateTopicTtlRequest(t *testing.T) {
+	updateTopicTTLRequest := &admin.UpdateTopicTTLRequest{
+		AdminID:    7000,
+		TopicName:  "rust",
+		DefaultTTL: 60,
+	}
+
+	updateTopicTTLResponse := &admin.UpdateTopicTTLResponse{
+		Status: "updated",
+	}
+
+	hdr.Method = "updateTopicTtlRequest"
+
+	request := getRequest(hdr, updateTopicTTLRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.UpdateTopicTTL).When(mock.Anything, updateTopicTTLRequest).Return(updateTopicTTLResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_UpdateTopicLimitsRequest(t *testing.T) {
+	updateTopicLimitsRequest := &admin.UpdateTopicLimitsRequest{
+		AdminID:        7000,
+		TopicName:      "rust",
+		MaxMessages:    100,
+		OverflowPolicy: "block",
+	}
+
+	updateTopicLimitsResponse := &admin.UpdateTopicLimitsResponse{
+		Status: "updated",
+	}
+
+	hdr.Method = "updateTopicLimitsRequest"
+
+	request := getRequest(hdr, updateTopicLimitsRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.UpdateTopicLimits).When(mock.Anything, updateTopicLimitsRequest).Return(updateTopicLimitsResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_DescribeTopicRequest(t *testing.T) {
+	describeTopicRequest := &admin.DescribeTopicRequest{
+		AdminID:   7000,
+		TopicName: "java",
+	}
+
+	describeTopicResponse := &admin.DescribeTopicResponse{
+		TopicName:   "java",
+		Depth:       3,
+		Subscribers: 2,
+	}
+
+	hdr.Method = "describeTopicRequest"
+
+	request := getRequest(hdr, describeTopicRequest)
+
+	mockAsvc := &test.MockAdminIF{}
+	mockAsvc.Given(admin.AdminIF.DescribeTopic).When(mock.Anything, describeTopicRequest).Return(describeTopicResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, mockAsvc, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 7000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_WatchTopicRequest_NoStreamFail(t *testing.T) {
+	watchTopicRequest := &subscriber.WatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+	}
+
+	hdr.Method = "watchTopicRequest"
+
+	request := getRequest(hdr, watchTopicRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockSsvc := &test.MockSubscriberIF{}
+
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "streaming is not supported on this connection" {
+		t.Fatalf("\necpected: %v \n\t got: %v", "streaming is not supported on this connection", resp.Error)
+	}
+}
+
+func TestRequestRouter_WatchTopicRequest(t *testing.T) {
+	watchTopicRequest := &subscriber.WatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+	}
+
+	watchTopicResponse := &subscriber.WatchTopicResponse{
+		Status: "watching",
+	}
+
+	hdr.Method = "watchTopicRequest"
+
+	request := getRequest(hdr, watchTopicRequest)
+
+	stream := &testStream{done: make(chan struct{})}
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.WatchTopic).When(mock.Anything, watchTopicRequest, mock.Anything).Return(watchTopicResponse, nil).Run(func(args mock.Arguments) {
+		fn := args.Get(2).(func(subscriber.MessagePush) error)
+		fn(subscriber.MessagePush{TopicName: "java", Message: subscriber.Message{Data: []byte("test data")}})
+	})
+
+	route := routes.NewHandler(mockPsvc, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(protocol.NewStreamContext(context.Background(), stream), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+
+	if len(stream.pushed) != 1 || stream.pushed[0].Event != protocol.EventMessage {
+		t.Fatalf("\necpected: 1 pushed message \n\t got: %v", stream.pushed)
+	}
+}
+
+func TestAuthenticate_Pass(t *testing.T) {
+	authenticateRequest := &auth.AuthenticateRequest{
+		APIKey: "secret",
+	}
+
+	authenticateResponse := &auth.AuthenticateResponse{
+		ClientID: 1000,
+		Name:     "orders",
+		Role:     domain.RolePublisher,
+	}
+
+	hdr.Method = "authenticateRequest"
+
+	request := getRequest(hdr, authenticateRequest)
+
+	mockAuth := &test.MockAuthIF{}
+	mockAuth.Given(auth.AuthIF.Authenticate).When(mock.Anything, authenticateRequest).Return(authenticateResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, mockAuth)
+
+	ctx, resp := route.Authenticate(context.Background(), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+
+	session, ok := protocol.SessionFromContext(ctx)
+	if !ok || session.ClientID != 1000 || session.Role != domain.RolePublisher {
+		t.Fatalf("\necpected: %v \n\t got: %v", authenticateResponse, session)
+	}
+
+	got := &auth.AuthenticateResponse{}
+	json.Unmarshal(resp.Body, got)
+	if len(got.Permissions) != 5 {
+		t.Fatalf("\necpected: %v \n\t got: %v", 5, got.Permissions)
+	}
+}
+
+func TestAuthenticate_CertificateSubject_Pass(t *testing.T) {
+	hdr.Method = "authenticateRequest"
+
+	request := getRequest(hdr, &auth.AuthenticateRequest{})
+
+	authenticateRequest := &auth.AuthenticateRequest{
+		CertificateSubject: "orders",
+	}
+
+	mockAuth := &test.MockAuthIF{}
+	mockAuth.Given(auth.AuthIF.Authenticate).When(mock.Anything, authenticateRequest).Return(&auth.AuthenticateResponse{ClientID: 1000, Role: domain.RoleSubscriber}, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, mockAuth)
+
+	ctx, resp := route.Authenticate(protocol.NewPeerContext(context.Background(), "orders"), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+
+	if session, ok := protocol.SessionFromContext(ctx); !ok || session.ClientID != 1000 {
+		t.Fatalf("\necpected: %v \n\t got: %v", 1000, session)
+	}
+}
+
+func TestAuthenticate_NotAuthenticateRequestFail(t *testing.T) {
+	hdr.Method = "showTopicRequest"
+
+	request := getRequest(hdr, &publisher.ShowTopicRequest{PublisherID: 5000})
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	ctx, resp := route.Authenticate(context.Background(), request)
+	if resp.Error != "unauthenticated" {
+		t.Fatalf("\necpected: %v \n\t got: %v", "unauthenticated", resp.Error)
+	}
+
+	if _, ok := protocol.SessionFromContext(ctx); ok {
+		t.Fatalf("\necpected: no session \n\t got: session")
+	}
+}
+
+func TestRequestRouter_UnauthenticatedFail(t *testing.T) {
+	hdr.Method = "showTopicRequest"
+
+	request := getRequest(hdr, &publisher.ShowTopicRequest{PublisherID: 5000})
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
 
 	resp := route.RequestRouter(context.Background(), request)
+	if resp.Error != "unauthenticated" {
+		t.Fatalf("\necpected: %v \n\t got: %v", "unauthenticated", resp.Error)
+	}
+}
+
+func TestRequestRouter_PermissionDeniedFail(t *testing.T) {
+	hdr.Method = "createTopicRequest"
+
+	request := getRequest(hdr, &admin.CreateTopicRequest{AdminID: 5000, TopicName: "rust"})
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
+	if resp.Error != "permission denied" {
+		t.Fatalf("\necpected: %v \n\t got: %v", "permission denied", resp.Error)
+	}
+
+	if resp.Code != protocol.CodeUnauthorized {
+		t.Fatalf("\necpected: %v \n\t got: %v", protocol.CodeUnauthorized, resp.Code)
+	}
+}
+
+func TestRequestRouter_NotFoundFail(t *testing.T) {
+	connectToTopicRequest := &publisher.ConnectToTopicRequest{
+		PublisherID: 5000,
+		TopicName:   "rust",
+	}
+
+	hdr.Method = "connectToTopicRequest"
+
+	request := getRequest(hdr, connectToTopicRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.ConnectToTopic).When(mock.Anything, connectToTopicRequest).Return((*publisher.ConnectToTopicResponse)(nil), domain.ErrTopicNotFound)
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
+
+	expected := &protocol.Response{Error: "topic not found", Code: protocol.CodeNotFound}
+	if !reflect.DeepEqual(expected, resp) {
+		t.Fatalf("\necpected: %v \n\t got: %v", expected, resp)
+	}
+}
+
+func TestRequestRouter_QueueEmptyFail(t *testing.T) {
+	getMessageFromTopicRequest := &subscriber.GetMessageFromTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "java",
+	}
+
+	hdr.Method = "getMessageFromTopicRequest"
+
+	request := getRequest(hdr, getMessageFromTopicRequest)
+
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.GetMessageFromTopic).When(mock.Anything, getMessageFromTopicRequest).Return((*subscriber.GetMessageFromTopicResponse)(nil), queue.ErrQueueEmpty)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+
+	expected := &protocol.Response{Error: "no message present in queue", Code: protocol.CodeQueueEmpty, Retryable: true}
+	if !reflect.DeepEqual(expected, resp) {
+		t.Fatalf("\necpected: %v \n\t got: %v", expected, resp)
+	}
+}
+
+func TestRequestRouter_QueueFullFail(t *testing.T) {
+	publishMessageRequest := &publisher.PublishMessageRequest{
+		PublisherID: 600,
+		Message: publisher.Message{
+			Data: []byte("test data"),
+		},
+	}
+
+	hdr.Method = "publishMessageRequest"
+
+	request := getRequest(hdr, publishMessageRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.PublishMessage).When(mock.Anything, publishMessageRequest).Return((*publisher.PublishMessageResponse)(nil), queue.ErrQueueFull)
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 600), request)
+
+	expected := &protocol.Response{Error: "topic is full, try again later", Code: protocol.CodeQueueFull, Retryable: true}
+	if !reflect.DeepEqual(expected, resp) {
+		t.Fatalf("\necpected: %v \n\t got: %v", expected, resp)
+	}
+}
+
+func TestRequestRouter_InvalidBodyFail(t *testing.T) {
+	hdr.Method = "createTopicRequest"
+
+	request := &protocol.Request{Header: hdr, Body: "{"}
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleAdmin, 5000), request)
+	if resp.Error != "invalid request body" || resp.Code != protocol.CodeInvalidArgument {
+		t.Fatalf("\necpected: %v %v \n\t got: %v %v", "invalid request body", protocol.CodeInvalidArgument, resp.Error, resp.Code)
+	}
+
+	if resp.Details == "" {
+		t.Fatalf("\necpected: details \n\t got: %v", resp.Details)
+	}
+}
+
+func TestRequestRouter_InternalFail(t *testing.T) {
+	hdr.Method = "showTopicRequest"
+
+	showTopicRequest := &publisher.ShowTopicRequest{PublisherID: 5000}
+
+	request := getRequest(hdr, showTopicRequest)
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.ShowTopics).When(mock.Anything, showTopicRequest).Return((*publisher.ShowTopicResponse)(nil), errors.New("connection refused"))
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 5000), request)
+
+	expected := &protocol.Response{Error: "connection refused", Code: protocol.CodeInternal}
+	if !reflect.DeepEqual(expected, resp) {
+		t.Fatalf("\necpected: %v \n\t got: %v", expected, resp)
+	}
+}
+
+func TestRequestRouter_UsesSessionClientID(t *testing.T) {
+	hdr.Method = "connectToTopicRequest"
+
+	request := getRequest(hdr, &publisher.ConnectToTopicRequest{PublisherID: 5001, TopicName: "golang"})
+
+	connectToTopicRequest := &publisher.ConnectToTopicRequest{
+		PublisherID: 1000,
+		TopicName:   "golang",
+	}
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.ConnectToTopic).When(mock.Anything, connectToTopicRequest).Return(&publisher.ConnectToTopicResponse{Status: "connected"}, nil)
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 1000), request)
 	if resp.Error != "" {
 		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
 	}
 }
 
-func getRequestString(hdr protocol.Header, v interface{}) string {
+func TestRequestRouter_GetMessageFromTopicRequestGob(t *testing.T) {
+	getMessageFromTopicRequest := &subscriber.GetMessageFromTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+	}
+
+	getMessageFromTopicResponse := &subscriber.GetMessageFromTopicResponse{
+		Message: subscriber.Message{
+			MessageID:   "message1",
+			Key:         "order-1",
+			ContentType: "application/octet-stream",
+			Headers:     protocol.Headers{"source": "orders"},
+			Data:        []byte{0x00, 0xff, '\n', 0x80},
+		},
+	}
+
+	body := &bytes.Buffer{}
+	gob.NewEncoder(body).Encode(getMessageFromTopicRequest)
+
+	request := &protocol.Request{
+		Header: protocol.Header{Version: protocol.Version2, ContentType: "gob", Method: "getMessageFromTopicRequest"},
+		Body:   body.String(),
+	}
+
+	mockSsvc := &test.MockSubscriberIF{}
+	mockSsvc.Given(subscriber.SubscriberIF.GetMessageFromTopic).When(mock.Anything, getMessageFromTopicRequest).Return(getMessageFromTopicResponse, nil)
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, mockSsvc, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RoleSubscriber, 6000), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+
+	got := &subscriber.GetMessageFromTopicResponse{}
+	if err := gob.NewDecoder(bytes.NewReader(resp.Body)).Decode(got); err != nil {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, err)
+	}
+
+	if !reflect.DeepEqual(getMessageFromTopicResponse, got) {
+		t.Fatalf("\necpected: %v \n\t got: %v", getMessageFromTopicResponse, got)
+	}
+}
+
+func TestRequestRouter_PublishMessageRequestXML(t *testing.T) {
+	publishMessageRequest := &publisher.PublishMessageRequest{
+		PublisherID: 600,
+		Message: publisher.Message{
+			Key:     "order-1",
+			Headers: protocol.Headers{"source": "orders", "trace": "abc"},
+			Data:    []byte{0x00, 0xff, '<'},
+		},
+	}
+
+	body, _ := xml.Marshal(publishMessageRequest)
+
+	request := &protocol.Request{
+		Header: protocol.Header{Version: protocol.Version1, ContentType: "xml", Method: "publishMessageRequest"},
+		Body:   string(body),
+	}
+
+	mockPsvc := &test.MockPublisherIF{}
+	mockPsvc.Given(publisher.PublisherIF.PublishMessage).When(mock.Anything, publishMessageRequest).Return(&publisher.PublishMessageResponse{Status: "successful"}, nil)
+
+	route := routes.NewHandler(mockPsvc, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 600), request)
+	if resp.Error != "" {
+		t.Fatalf("\necpected: %v \n\t got: %v", nil, resp.Error)
+	}
+}
+
+func TestRequestRouter_GobOverVersion1Fail(t *testing.T) {
+	request := &protocol.Request{
+		Header: protocol.Header{Version: protocol.Version1, ContentType: "gob", Method: "showTopicRequest"},
+	}
+
+	route := routes.NewHandler(&test.MockPublisherIF{}, &test.MockSubscriberIF{}, &test.MockAdminIF{}, &test.MockAuthIF{})
+
+	resp := route.RequestRouter(sessionContext(context.Background(), domain.RolePublisher, 600), request)
+	if resp.Error != protocol.ErrUnsupportedContentType.Error() || resp.Code != protocol.CodeInvalidArgument {
+		t.Fatalf("\necpected: %v %v \n\t got: %v %v", protocol.ErrUnsupportedContentType, protocol.CodeInvalidArgument, resp.Error, resp.Code)
+	}
+}
+
+type testStream struct {
+	pushed []*protocol.Response
+	done   chan struct{}
+}
+
+func (s *testStream) Push(response *protocol.Response) error {
+	s.pushed = append(s.pushed, response)
+	return nil
+}
+
+func (s *testStream) Done() <-chan struct{} {
+	return s.done
+}
+
+func sessionContext(ctx context.Context, role string, clientID int) context.Context {
+	return protocol.NewSessionContext(ctx, &protocol.Session{ClientID: clientID, Role: role})
+}
+
+func getRequest(hdr protocol.Header, v interface{}) *protocol.Request {
 	body, _ := json.Marshal(v)
-	request := protocol.Request{
+	return &protocol.Request{
 		Header: hdr,
 		Body:   string(body),
 	}
-	requestBytes, _ := json.Marshal(request)
-	return string(requestBytes)
 }
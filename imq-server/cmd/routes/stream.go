@@ -0,0 +1,39 @@
+package routes
+
+import (
+	"context"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/subscriber"
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
+// streamTopic pushes every new message of the requested topic, or of the topics matched by the requested
+// subscription pattern, over the stream carried by ctx until the stream is closed
+func streamTopic(ctx context.Context, s subscriber.SubscriberIF, in *subscriber.WatchTopicRequest, contentType string) (*subscriber.WatchTopicResponse, error) {
+	stream, ok := protocol.StreamFromContext(ctx)
+	if !ok {
+		return nil, errStreamingUnsupported
+	}
+
+	resp, err := s.WatchTopic(ctx, in, func(push subscriber.MessagePush) error {
+		body, err := marshal(&push, contentType)
+		if err != nil {
+			return err
+		}
+
+		return stream.Push(&protocol.Response{Event: protocol.EventMessage, Body: body})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-stream.Done()
+		s.UnwatchTopic(context.Background(), &subscriber.UnwatchTopicRequest{
+			SubscriberID: in.SubscriberID,
+			TopicName:    in.TopicName,
+		})
+	}()
+
+	return resp, nil
+}
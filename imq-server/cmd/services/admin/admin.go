@@ -0,0 +1,241 @@
+package admin
+
+import (
+	"context"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// Admin is the concrete implementation for the Admin
+type Admin struct {
+	log          *logrus.Logger
+	topicService domain.TopicServicesIF
+}
+
+// AdminIF is the interface for the Admin service
+type AdminIF interface {
+	ListDeadMessages(ctx context.Context, in *ListDeadMessagesRequest) (*ListDeadMessagesResponse, error)
+	PurgeDeadMessages(ctx context.Context, in *PurgeDeadMessagesRequest) (*PurgeDeadMessagesResponse, error)
+	ReplayDeadMessages(ctx context.Context, in *ReplayDeadMessagesRequest) (*ReplayDeadMessagesResponse, error)
+	CreateTopic(ctx context.Context, in *CreateTopicRequest) (*CreateTopicResponse, error)
+	UpdateTopicTTL(ctx context.Context, in *UpdateTopicTTLRequest) (*UpdateTopicTTLResponse, error)
+	UpdateTopicLimits(ctx context.Context, in *UpdateTopicLimitsRequest) (*UpdateTopicLimitsResponse, error)
+	RenameTopic(ctx context.Context, in *RenameTopicRequest) (*RenameTopicResponse, error)
+	DeleteTopic(ctx context.Context, in *DeleteTopicRequest) (*DeleteTopicResponse, error)
+	DescribeTopic(ctx context.Context, in *DescribeTopicRequest) (*DescribeTopicResponse, error)
+	ListConsumerGroups(ctx context.Context, in *ListConsumerGroupsRequest) (*ListConsumerGroupsResponse, error)
+}
+
+// NewAdmin is the factory function for the Admin type
+func NewAdmin(log *logrus.Logger, topicService domain.TopicServicesIF) AdminIF {
+	return &Admin{
+		log:          log,
+		topicService: topicService,
+	}
+}
+
+// ListDeadMessages fetch all the messages in the dead letter queue of a given topic
+func (a *Admin) ListDeadMessages(ctx context.Context, in *ListDeadMessagesRequest) (*ListDeadMessagesResponse, error) {
+	listDeadMessagesResponse := &ListDeadMessagesResponse{}
+
+	messages, err := a.topicService.ListDeadMessages(ctx, in.TopicName)
+	if err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("ListDeadMessages: failed to get dead messages: %v", err)
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		listDeadMessagesResponse.Messages = append(listDeadMessagesResponse.Messages, DeadMessage{
+			MessageID:   msg.MessageID,
+			Key:         msg.Key,
+			ContentType: msg.ContentType,
+			Headers:     msg.Headers,
+			Data:        msg.Data,
+			CretedAt:    msg.CretedAt,
+			ExpiresAt:   msg.ExpiresAt,
+			Reason:      msg.Reason,
+			DeadAt:      msg.DeadAt,
+		})
+	}
+
+	return listDeadMessagesResponse, nil
+}
+
+// PurgeDeadMessages drops messages from the dead letter queue of a given topic
+func (a *Admin) PurgeDeadMessages(ctx context.Context, in *PurgeDeadMessagesRequest) (*PurgeDeadMessagesResponse, error) {
+	purgeDeadMessagesResponse := &PurgeDeadMessagesResponse{}
+
+	count, err := a.topicService.PurgeDeadMessages(ctx, in.TopicName, in.MessageID)
+	if err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("PurgeDeadMessages: failed to purge dead messages: %v", err)
+		return nil, err
+	}
+
+	purgeDeadMessagesResponse.Status = statusPurged
+	purgeDeadMessagesResponse.Count = count
+
+	return purgeDeadMessagesResponse, nil
+}
+
+// ReplayDeadMessages publishes messages from the dead letter queue of a given topic back to the topic
+func (a *Admin) ReplayDeadMessages(ctx context.Context, in *ReplayDeadMessagesRequest) (*ReplayDeadMessagesResponse, error) {
+	replayDeadMessagesResponse := &ReplayDeadMessagesResponse{}
+
+	count, err := a.topicService.ReplayDeadMessages(ctx, in.TopicName, in.MessageID)
+	if err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("ReplayDeadMessages: failed to replay dead messages: %v", err)
+		return nil, err
+	}
+
+	replayDeadMessagesResponse.Status = statusReplayed
+	replayDeadMessagesResponse.Count = count
+
+	return replayDeadMessagesResponse, nil
+}
+
+// CreateTopic creates a new topic
+func (a *Admin) CreateTopic(ctx context.Context, in *CreateTopicRequest) (*CreateTopicResponse, error) {
+	createTopicResponse := &CreateTopicResponse{}
+
+	if err := a.topicService.CreateTopic(ctx, uuid.New().String(), in.TopicName, topicTTL(in.DefaultTTL, in.MaxTTL), in.Partitions, topicLimits(in.MaxMessages, in.MaxBytes, in.OverflowPolicy)); err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("CreateTopic: failed to create topic: %v", err)
+		return nil, err
+	}
+
+	createTopicResponse.Status = statusCreated
+
+	return createTopicResponse, nil
+}
+
+// UpdateTopicTTL sets the default and maximum time to live of the messages of a given topic
+func (a *Admin) UpdateTopicTTL(ctx context.Context, in *UpdateTopicTTLRequest) (*UpdateTopicTTLResponse, error) {
+	updateTopicTTLResponse := &UpdateTopicTTLResponse{}
+
+	if err := a.topicService.UpdateTopicTTL(ctx, in.TopicName, topicTTL(in.DefaultTTL, in.MaxTTL)); err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("UpdateTopicTTL: failed to update topic ttl: %v", err)
+		return nil, err
+	}
+
+	updateTopicTTLResponse.Status = statusUpdated
+
+	return updateTopicTTLResponse, nil
+}
+
+// UpdateTopicLimits sets the limits and overflow policy of a given topic
+func (a *Admin) UpdateTopicLimits(ctx context.Context, in *UpdateTopicLimitsRequest) (*UpdateTopicLimitsResponse, error) {
+	updateTopicLimitsResponse := &UpdateTopicLimitsResponse{}
+
+	if err := a.topicService.UpdateTopicLimits(ctx, in.TopicName, topicLimits(in.MaxMessages, in.MaxBytes, in.OverflowPolicy)); err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("UpdateTopicLimits: failed to update topic limits: %v", err)
+		return nil, err
+	}
+
+	updateTopicLimitsResponse.Status = statusUpdated
+
+	return updateTopicLimitsResponse, nil
+}
+
+// RenameTopic changes the name of a given topic
+func (a *Admin) RenameTopic(ctx context.Context, in *RenameTopicRequest) (*RenameTopicResponse, error) {
+	renameTopicResponse := &RenameTopicResponse{}
+
+	if err := a.topicService.RenameTopic(ctx, in.TopicName, in.NewTopicName); err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("RenameTopic: failed to rename topic: %v", err)
+		return nil, err
+	}
+
+	renameTopicResponse.Status = statusRenamed
+
+	return renameTopicResponse, nil
+}
+
+// DeleteTopic removes a given topic along with its subscriptions
+func (a *Admin) DeleteTopic(ctx context.Context, in *DeleteTopicRequest) (*DeleteTopicResponse, error) {
+	deleteTopicResponse := &DeleteTopicResponse{}
+
+	if err := a.topicService.DeleteTopic(ctx, in.TopicName, in.Force); err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("DeleteTopic: failed to delete topic: %v", err)
+		return nil, err
+	}
+
+	deleteTopicResponse.Status = statusDeleted
+
+	return deleteTopicResponse, nil
+}
+
+// DescribeTopic fetch the number of partitions, messages, dead messages, publishers and subscribers of a given topic
+func (a *Admin) DescribeTopic(ctx context.Context, in *DescribeTopicRequest) (*DescribeTopicResponse, error) {
+	description, err := a.topicService.DescribeTopic(ctx, in.TopicName)
+	if err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("DescribeTopic: failed to describe topic: %v", err)
+		return nil, err
+	}
+
+	return &DescribeTopicResponse{
+		TopicName:      description.Name,
+		Partitions:     description.Partitions,
+		Depth:          description.Depth,
+		Bytes:          description.Bytes,
+		DeadMessages:   description.DeadMessages,
+		Publishers:     description.Publishers,
+		Subscribers:    description.Subscribers,
+		DefaultTTL:     int(description.TTL.DefaultTTL / time.Second),
+		MaxTTL:         int(description.TTL.MaxTTL / time.Second),
+		MaxMessages:    description.Limits.MaxMessages,
+		MaxBytes:       description.Limits.MaxBytes,
+		OverflowPolicy: description.Limits.Overflow,
+	}, nil
+}
+
+// ListConsumerGroups fetch the consumer groups reading a given topic with their members, lag and partitions
+func (a *Admin) ListConsumerGroups(ctx context.Context, in *ListConsumerGroupsRequest) (*ListConsumerGroupsResponse, error) {
+	listConsumerGroupsResponse := &ListConsumerGroupsResponse{Groups: []ConsumerGroup{}}
+
+	groups, err := a.topicService.ListConsumerGroups(ctx, in.TopicName)
+	if err != nil {
+		a.log.WithField("adminId", in.AdminID).Errorf("ListConsumerGroups: failed to list consumer groups: %v", err)
+		return nil, err
+	}
+
+	for _, g := range groups {
+		partitions := []GroupPartition{}
+		for _, p := range g.Partitions {
+			partitions = append(partitions, GroupPartition{
+				Partition: p.Partition,
+				Members:   p.Members,
+				Offset:    p.Offset,
+				Pending:   p.Pending,
+				Lag:       p.Lag,
+			})
+		}
+
+		listConsumerGroupsResponse.Groups = append(listConsumerGroupsResponse.Groups, ConsumerGroup{
+			GroupName:  g.Name,
+			Members:    g.Members,
+			Pending:    g.Pending,
+			Lag:        g.Lag,
+			Partitions: partitions,
+		})
+	}
+
+	return listConsumerGroupsResponse, nil
+}
+
+// topicTTL converts the ttls given in seconds
+func topicTTL(defaultTTL, maxTTL int) domain.TopicTTL {
+	return domain.TopicTTL{
+		DefaultTTL: time.Duration(defaultTTL) * time.Second,
+		MaxTTL:     time.Duration(maxTTL) * time.Second,
+	}
+}
+
+func topicLimits(maxMessages, maxBytes int, overflowPolicy string) domain.TopicLimits {
+	return domain.TopicLimits{
+		MaxMessages: maxMessages,
+		MaxBytes:    maxBytes,
+		Overflow:    overflowPolicy,
+	}
+}
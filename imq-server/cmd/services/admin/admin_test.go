@@ -0,0 +1,343 @@
+package admin_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/admin"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/test"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestListDeadMessages_Fail(t *testing.T) {
+	req := &admin.ListDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	expectedErr := errors.New("failed to get topicId")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ListDeadMessages).When(mock.Anything, req.TopicName).Return([]domain.DeadMessage{}, expectedErr)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+	_, err := adm.ListDeadMessages(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestListDeadMessages_Pass(t *testing.T) {
+	req := &admin.ListDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	resp := []domain.DeadMessage{
+		{
+			Message: domain.Message{MessageID: "123", Data: []byte("test data")},
+			Reason:  "expired",
+		},
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ListDeadMessages).When(mock.Anything, req.TopicName).Return(resp, nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+	got, err := adm.ListDeadMessages(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if len(got.Messages) != 1 || got.Messages[0].MessageID != "123" || got.Messages[0].Reason != "expired" {
+		t.Fatalf("expected: %v \n\t got: %v", resp, got.Messages)
+	}
+}
+
+func TestPurgeDeadMessages_Pass(t *testing.T) {
+	req := &admin.PurgeDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.PurgeDeadMessages).When(mock.Anything, req.TopicName, req.MessageID).Return(3, nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+	got, err := adm.PurgeDeadMessages(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Count != 3 {
+		t.Fatalf("expected: 3 \n\t got: %v", got.Count)
+	}
+}
+
+func TestReplayDeadMessages_Fail(t *testing.T) {
+	req := &admin.ReplayDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+		MessageID: "123",
+	}
+
+	expectedErr := errors.New("failed to get topicId")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ReplayDeadMessages).When(mock.Anything, req.TopicName, req.MessageID).Return(0, expectedErr)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+	_, err := adm.ReplayDeadMessages(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestReplayDeadMessages_Pass(t *testing.T) {
+	req := &admin.ReplayDeadMessagesRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+		MessageID: "123",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ReplayDeadMessages).When(mock.Anything, req.TopicName, req.MessageID).Return(1, nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+	got, err := adm.ReplayDeadMessages(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Count != 1 {
+		t.Fatalf("expected: 1 \n\t got: %v", got.Count)
+	}
+}
+
+func TestCreateTopic_Fail(t *testing.T) {
+	req := &admin.CreateTopicRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	expectedErr := errors.New("topic already exists")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.CreateTopic).When(mock.Anything, mock.Anything, req.TopicName, domain.TopicTTL{}, 0, domain.TopicLimits{}).Return(expectedErr)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	_, err := adm.CreateTopic(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestCreateTopic_Pass(t *testing.T) {
+	req := &admin.CreateTopicRequest{
+		AdminID:        7000,
+		TopicName:      "rust",
+		Partitions:     4,
+		MaxMessages:    100,
+		OverflowPolicy: "block",
+	}
+
+	limits := domain.TopicLimits{MaxMessages: 100, Overflow: "block"}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.CreateTopic).When(mock.Anything, mock.Anything, req.TopicName, domain.TopicTTL{}, 4, limits).Return(nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.CreateTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Status != "created" {
+		t.Fatalf("expected: created \n\t got: %v", got.Status)
+	}
+}
+
+func TestUpdateTopicTTL_Pass(t *testing.T) {
+	req := &admin.UpdateTopicTTLRequest{
+		AdminID:    7000,
+		TopicName:  "golang",
+		DefaultTTL: 60,
+		MaxTTL:     3600,
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.UpdateTopicTTL).When(mock.Anything, req.TopicName, domain.TopicTTL{DefaultTTL: time.Minute, MaxTTL: time.Hour}).Return(nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.UpdateTopicTTL(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Status != "updated" {
+		t.Fatalf("expected: updated \n\t got: %v", got.Status)
+	}
+}
+
+func TestUpdateTopicLimits_Pass(t *testing.T) {
+	req := &admin.UpdateTopicLimitsRequest{
+		AdminID:        7000,
+		TopicName:      "golang",
+		MaxBytes:       4096,
+		OverflowPolicy: "dropOldest",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.UpdateTopicLimits).When(mock.Anything, req.TopicName, domain.TopicLimits{MaxBytes: 4096, Overflow: "dropOldest"}).Return(nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.UpdateTopicLimits(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Status != "updated" {
+		t.Fatalf("expected: updated \n\t got: %v", got.Status)
+	}
+}
+
+func TestDeleteTopic_Pass(t *testing.T) {
+	req := &admin.DeleteTopicRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+		Force:     true,
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.DeleteTopic).When(mock.Anything, req.TopicName, true).Return(nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.DeleteTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Status != "deleted" {
+		t.Fatalf("expected: deleted \n\t got: %v", got.Status)
+	}
+}
+
+func TestDescribeTopic_Pass(t *testing.T) {
+	req := &admin.DescribeTopicRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	resp := &domain.TopicDescription{
+		Name:         "golang",
+		Partitions:   2,
+		Depth:        3,
+		DeadMessages: 1,
+		Publishers:   1,
+		Subscribers:  2,
+		TTL:          domain.TopicTTL{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour},
+		Limits:       domain.TopicLimits{MaxMessages: 10, MaxBytes: 1024, Overflow: "reject"},
+	}
+
+	expected := &admin.DescribeTopicResponse{
+		TopicName:      "golang",
+		Partitions:     2,
+		Depth:          3,
+		DeadMessages:   1,
+		Publishers:     1,
+		Subscribers:    2,
+		DefaultTTL:     3600,
+		MaxTTL:         86400,
+		MaxMessages:    10,
+		MaxBytes:       1024,
+		OverflowPolicy: "reject",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.DescribeTopic).When(mock.Anything, req.TopicName).Return(resp, nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.DescribeTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if *got != *expected {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestListConsumerGroups_Fail(t *testing.T) {
+	req := &admin.ListConsumerGroupsRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	expectedErr := errors.New("topic not found")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ListConsumerGroups).When(mock.Anything, req.TopicName).Return([]domain.ConsumerGroup{}, expectedErr)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	_, err := adm.ListConsumerGroups(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestListConsumerGroups_Pass(t *testing.T) {
+	req := &admin.ListConsumerGroupsRequest{
+		AdminID:   7000,
+		TopicName: "golang",
+	}
+
+	groups := []domain.ConsumerGroup{{
+		Name:    "billing",
+		Members: []int{6000, 7000},
+		Pending: 2,
+		Lag:     6,
+		Partitions: []domain.GroupPartition{
+			{Partition: 0, Members: []int{6000}, Offset: 4, Pending: 2, Lag: 1},
+			{Partition: 1, Members: []int{7000}, Offset: -1, Lag: 5},
+		},
+	}}
+
+	expected := &admin.ListConsumerGroupsResponse{
+		Groups: []admin.ConsumerGroup{{
+			GroupName: "billing",
+			Members:   []int{6000, 7000},
+			Pending:   2,
+			Lag:       6,
+			Partitions: []admin.GroupPartition{
+				{Partition: 0, Members: []int{6000}, Offset: 4, Pending: 2, Lag: 1},
+				{Partition: 1, Members: []int{7000}, Offset: -1, Lag: 5},
+			},
+		}},
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.ListConsumerGroups).When(mock.Anything, req.TopicName).Return(groups, nil)
+
+	adm := admin.NewAdmin(&logrus.Logger{}, mockTopicSvc)
+
+	got, err := adm.ListConsumerGroups(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
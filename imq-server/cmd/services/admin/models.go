@@ -0,0 +1,198 @@
+package admin
+
+import (
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
+const (
+	statusPurged   = "purged"
+	statusReplayed = "replayed"
+	statusCreated  = "created"
+	statusRenamed  = "renamed"
+	statusDeleted  = "deleted"
+	statusUpdated  = "updated"
+)
+
+// ListDeadMessagesRequest holds the request details for ListDeadMessages
+type ListDeadMessagesRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+}
+
+// ListDeadMessagesResponse holds the response details for ListDeadMessages
+type ListDeadMessagesResponse struct {
+	Messages []DeadMessage `json:"messages" xml:"messages"`
+}
+
+// PurgeDeadMessagesRequest holds the request details for PurgeDeadMessages,
+// an empty MessageID purges every dead message of the topic
+type PurgeDeadMessagesRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+	MessageID string `json:"messageId" xml:"messageId"`
+}
+
+// PurgeDeadMessagesResponse holds the response details for PurgeDeadMessages
+type PurgeDeadMessagesResponse struct {
+	Status string `json:"status" xml:"status"`
+	Count  int    `json:"count" xml:"count"`
+}
+
+// ReplayDeadMessagesRequest holds the request details for ReplayDeadMessages,
+// an empty MessageID replays every dead message of the topic
+type ReplayDeadMessagesRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+	MessageID string `json:"messageId" xml:"messageId"`
+}
+
+// ReplayDeadMessagesResponse holds the response details for ReplayDeadMessages
+type ReplayDeadMessagesResponse struct {
+	Status string `json:"status" xml:"status"`
+	Count  int    `json:"count" xml:"count"`
+}
+
+// CreateTopicRequest holds the request details for CreateTopic, the ttls are in seconds and
+// the defaults of the server are used for the ttls, limits and overflow policy left at zero
+// or empty. A topic created without Partitions has a single partition
+type CreateTopicRequest struct {
+	AdminID        int    `json:"adminId" xml:"adminId"`
+	TopicName      string `json:"topicName" xml:"topicName"`
+	DefaultTTL     int    `json:"defaultTtl,omitempty" xml:"defaultTtl,omitempty"`
+	MaxTTL         int    `json:"maxTtl,omitempty" xml:"maxTtl,omitempty"`
+	Partitions     int    `json:"partitions,omitempty" xml:"partitions,omitempty"`
+	MaxMessages    int    `json:"maxMessages,omitempty" xml:"maxMessages,omitempty"`
+	MaxBytes       int    `json:"maxBytes,omitempty" xml:"maxBytes,omitempty"`
+	OverflowPolicy string `json:"overflowPolicy,omitempty" xml:"overflowPolicy,omitempty"`
+}
+
+// CreateTopicResponse holds the response details for CreateTopic
+type CreateTopicResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// RenameTopicRequest holds the request details for RenameTopic
+type RenameTopicRequest struct {
+	AdminID      int    `json:"adminId" xml:"adminId"`
+	TopicName    string `json:"topicName" xml:"topicName"`
+	NewTopicName string `json:"newTopicName" xml:"newTopicName"`
+}
+
+// RenameTopicResponse holds the response details for RenameTopic
+type RenameTopicResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// DeleteTopicRequest holds the request details for DeleteTopic,
+// a topic still holding messages is only deleted when Force is set
+type DeleteTopicRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+	Force     bool   `json:"force" xml:"force"`
+}
+
+// DeleteTopicResponse holds the response details for DeleteTopic
+type DeleteTopicResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// UpdateTopicTTLRequest holds the request details for UpdateTopicTTL, the ttls are in seconds
+// and the defaults of the server are used for the ones left at zero
+type UpdateTopicTTLRequest struct {
+	AdminID    int    `json:"adminId" xml:"adminId"`
+	TopicName  string `json:"topicName" xml:"topicName"`
+	DefaultTTL int    `json:"defaultTtl" xml:"defaultTtl"`
+	MaxTTL     int    `json:"maxTtl" xml:"maxTtl"`
+}
+
+// UpdateTopicTTLResponse holds the response details for UpdateTopicTTL
+type UpdateTopicTTLResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// UpdateTopicLimitsRequest holds the request details for UpdateTopicLimits, the limits apply to
+// every partition and the defaults of the server are used for the ones left at zero or empty
+type UpdateTopicLimitsRequest struct {
+	AdminID        int    `json:"adminId" xml:"adminId"`
+	TopicName      string `json:"topicName" xml:"topicName"`
+	MaxMessages    int    `json:"maxMessages" xml:"maxMessages"`
+	MaxBytes       int    `json:"maxBytes" xml:"maxBytes"`
+	OverflowPolicy string `json:"overflowPolicy" xml:"overflowPolicy"`
+}
+
+// UpdateTopicLimitsResponse holds the response details for UpdateTopicLimits
+type UpdateTopicLimitsResponse struct {
+	Status string `json:"status" xml:"status"`
+}
+
+// DescribeTopicRequest holds the request details for DescribeTopic
+type DescribeTopicRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+}
+
+// DescribeTopicResponse holds the response details for DescribeTopic, the ttls are in seconds
+// and a zero MaxTTL allows any ttl. MaxMessages and MaxBytes bound every partition when they are
+// not zero, so Depth and Bytes of the topic reach at most Partitions times them. OverflowPolicy is
+// applied to a message published to a partition once it is full
+type DescribeTopicResponse struct {
+	TopicName      string `json:"topicName" xml:"topicName"`
+	Partitions     int    `json:"partitions" xml:"partitions"`
+	Depth          int    `json:"depth" xml:"depth"`
+	Bytes          int    `json:"bytes" xml:"bytes"`
+	DeadMessages   int    `json:"deadMessages" xml:"deadMessages"`
+	Publishers     int    `json:"publishers" xml:"publishers"`
+	Subscribers    int    `json:"subscribers" xml:"subscribers"`
+	DefaultTTL     int    `json:"defaultTtl" xml:"defaultTtl"`
+	MaxTTL         int    `json:"maxTtl" xml:"maxTtl"`
+	MaxMessages    int    `json:"maxMessages" xml:"maxMessages"`
+	MaxBytes       int    `json:"maxBytes" xml:"maxBytes"`
+	OverflowPolicy string `json:"overflowPolicy" xml:"overflowPolicy"`
+}
+
+// ListConsumerGroupsRequest holds the request details for ListConsumerGroups
+type ListConsumerGroupsRequest struct {
+	AdminID   int    `json:"adminId" xml:"adminId"`
+	TopicName string `json:"topicName" xml:"topicName"`
+}
+
+// ListConsumerGroupsResponse holds the response details for ListConsumerGroups
+type ListConsumerGroupsResponse struct {
+	Groups []ConsumerGroup `json:"groups" xml:"groups"`
+}
+
+// ConsumerGroup holds the members of a consumer group reading a topic, Pending counts the messages delivered to
+// the group that are not acknowledged yet and Lag counts the messages the group has not read yet
+type ConsumerGroup struct {
+	GroupName  string           `json:"groupName" xml:"groupName"`
+	Members    []int            `json:"members" xml:"members"`
+	Pending    int              `json:"pending" xml:"pending"`
+	Lag        int              `json:"lag" xml:"lag"`
+	Partitions []GroupPartition `json:"partitions" xml:"partitions"`
+}
+
+// GroupPartition holds the members of a consumer group reading a partition, empty when the group has no member,
+// and the offset the group resumes reading the partition from along with its pending messages and lag. A partition
+// has several members when the group has more members than the topic has partitions, they compete for its messages
+type GroupPartition struct {
+	Partition int   `json:"partition" xml:"partition"`
+	Members   []int `json:"members" xml:"members"`
+	Offset    int64 `json:"offset" xml:"offset"`
+	Pending   int   `json:"pending" xml:"pending"`
+	Lag       int   `json:"lag" xml:"lag"`
+}
+
+// DeadMessage holds the details of a message in the dead letter queue, ExpiresAt is zero for a message that never expires
+type DeadMessage struct {
+	MessageID   string           `json:"messageId" xml:"messageId"`
+	Key         string           `json:"key,omitempty" xml:"key,omitempty"`
+	ContentType string           `json:"contentType,omitempty" xml:"contentType,omitempty"`
+	Headers     protocol.Headers `json:"headers,omitempty" xml:"headers,omitempty"`
+	Data        protocol.Payload `json:"data" xml:"data"`
+	CretedAt    time.Time        `json:"cretedAt" xml:"cretedAt"`
+	ExpiresAt   time.Time        `json:"expiredAt" xml:"expiredAt"`
+	Reason      string           `json:"reason" xml:"reason"`
+	DeadAt      time.Time        `json:"deadAt" xml:"deadAt"`
+}
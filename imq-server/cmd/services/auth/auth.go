@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/sirupsen/logrus"
+)
+
+// Auth is the concrete implementation for the Auth
+type Auth struct {
+	log           *logrus.Logger
+	clientService domain.ClientServicesIF
+}
+
+// AuthIF is the interface for the Auth service
+type AuthIF interface {
+	Authenticate(ctx context.Context, in *AuthenticateRequest) (*AuthenticateResponse, error)
+}
+
+// NewAuth is the factory function for the Auth type
+func NewAuth(log *logrus.Logger, clientService domain.ClientServicesIF) AuthIF {
+	return &Auth{
+		log:           log,
+		clientService: clientService,
+	}
+}
+
+// Authenticate checks the credentials presented by a client and returns who the client is,
+// a client without an api key is authenticated by its certificate
+func (a *Auth) Authenticate(ctx context.Context, in *AuthenticateRequest) (*AuthenticateResponse, error) {
+	var (
+		client *domain.Client
+		err    error
+	)
+
+	if in.APIKey == "" && in.CertificateSubject != "" {
+		client, err = a.clientService.AuthenticateCertificate(ctx, in.CertificateSubject)
+	} else {
+		client, err = a.clientService.Authenticate(ctx, in.APIKey)
+	}
+	if err != nil {
+		a.log.Errorf("Authenticate: failed to authenticate client: %v", err)
+		return nil, err
+	}
+
+	return &AuthenticateResponse{
+		ClientID: client.ClientID,
+		Name:     client.Name,
+		Role:     client.Role,
+	}, nil
+}
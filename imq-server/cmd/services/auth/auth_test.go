@@ -0,0 +1,73 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/auth"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/test"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticate_Fail(t *testing.T) {
+	req := &auth.AuthenticateRequest{
+		APIKey: "secret",
+	}
+
+	expectedErr := errors.New("invalid credentials")
+
+	mockClientSvc := &test.MockClientServiceIF{}
+	mockClientSvc.Given(domain.ClientServicesIF.Authenticate).When(mock.Anything, req.APIKey).Return(&domain.Client{}, expectedErr)
+
+	a := auth.NewAuth(&logrus.Logger{}, mockClientSvc)
+	_, err := a.Authenticate(context.Background(), req)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestAuthenticate_Pass(t *testing.T) {
+	req := &auth.AuthenticateRequest{
+		APIKey: "secret",
+	}
+
+	mockClientSvc := &test.MockClientServiceIF{}
+	mockClientSvc.Given(domain.ClientServicesIF.Authenticate).When(mock.Anything, req.APIKey).Return(&domain.Client{ClientID: 1000, Name: "orders", Role: domain.RoleAdmin}, nil)
+
+	expected := &auth.AuthenticateResponse{ClientID: 1000, Name: "orders", Role: domain.RoleAdmin}
+
+	a := auth.NewAuth(&logrus.Logger{}, mockClientSvc)
+	got, err := a.Authenticate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestAuthenticate_Certificate_Pass(t *testing.T) {
+	req := &auth.AuthenticateRequest{
+		CertificateSubject: "orders",
+	}
+
+	mockClientSvc := &test.MockClientServiceIF{}
+	mockClientSvc.Given(domain.ClientServicesIF.AuthenticateCertificate).When(mock.Anything, "orders").Return(&domain.Client{ClientID: 1000, Name: "orders", Role: domain.RolePublisher}, nil)
+
+	expected := &auth.AuthenticateResponse{ClientID: 1000, Name: "orders", Role: domain.RolePublisher}
+
+	a := auth.NewAuth(&logrus.Logger{}, mockClientSvc)
+	got, err := a.Authenticate(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
@@ -0,0 +1,16 @@
+package auth
+
+// AuthenticateRequest holds the request details for Authenticate
+// CertificateSubject is set by the server from the verified client certificate of the connection
+type AuthenticateRequest struct {
+	APIKey             string `json:"apiKey" xml:"apiKey"`
+	CertificateSubject string `json:"-" xml:"-"`
+}
+
+// AuthenticateResponse holds the response details for Authenticate
+type AuthenticateResponse struct {
+	ClientID    int      `json:"clientId" xml:"clientId"`
+	Name        string   `json:"name" xml:"name"`
+	Role        string   `json:"role" xml:"role"`
+	Permissions []string `json:"permissions" xml:"permissions"`
+}
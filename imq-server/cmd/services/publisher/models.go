@@ -1,9 +1,12 @@
 package publisher
 
+import "github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+
 const (
 	sucessConnected    = "connected"
 	statusDisconnected = "disconnected"
 	statusSuccessful   = "successful"
+	statusFailed       = "failed"
 )
 
 // ShowTopicRequest holds the request details for ShowTopics
@@ -29,7 +32,8 @@ type ConnectToTopicResponse struct {
 
 // DisconnectFromTopicRequest holds the request details for DisconnectFromTopic
 type DisconnectFromTopicRequest struct {
-	PublisherID int `json:"publisherId" xml:"publisherId"`
+	PublisherID int    `json:"publisherId" xml:"publisherId"`
+	TopicName   string `json:"topicName" xml:"topicName"`
 }
 
 // DisconnectFromTopicResponse holds the response details for DisconnectFromTopic
@@ -37,17 +41,21 @@ type DisconnectFromTopicResponse struct {
 	Status string `json:"status" xml:"status"`
 }
 
-// PublishMessageRequest holds the request details for PublishMessage
+// PublishMessageRequest holds the request details for PublishMessage, the message is published to the only topic
+// the publisher is connected to when TopicName is empty
 type PublishMessageRequest struct {
 	PublisherID int     `json:"publisherId" xml:"publisherId"`
+	TopicName   string  `json:"topicName,omitempty" xml:"topicName,omitempty"`
 	Message     Message `json:"message" xml:"message"`
 }
 
-// Message holds the message details
+// Message holds the message details, TTL is the time to live in seconds and the default ttl of the topic is used when zero
 type Message struct {
-	Data      string `json:"data" xml:"data"`
-	CretedAt  string `json:"cretedAt" xml:"cretedAt"`
-	ExpiresAt string `json:"expiredAt" xml:"expiredAt"`
+	Key         string           `json:"key,omitempty" xml:"key,omitempty"`
+	ContentType string           `json:"contentType,omitempty" xml:"contentType,omitempty"`
+	Headers     protocol.Headers `json:"headers,omitempty" xml:"headers,omitempty"`
+	Data        protocol.Payload `json:"data" xml:"data"`
+	TTL         int              `json:"ttl,omitempty" xml:"ttl,omitempty"`
 }
 
 // PublishMessageResponse holds the response details for PublishMessage
@@ -55,6 +63,25 @@ type PublishMessageResponse struct {
 	Status string `json:"status" xml:"status"`
 }
 
+// PublishBatchRequest holds the request details for PublishBatch, TopicName is picked as for PublishMessageRequest
+type PublishBatchRequest struct {
+	PublisherID int       `json:"publisherId" xml:"publisherId"`
+	TopicName   string    `json:"topicName,omitempty" xml:"topicName,omitempty"`
+	Messages    []Message `json:"messages" xml:"messages"`
+}
+
+// PublishBatchResponse holds the response details for PublishBatch
+type PublishBatchResponse struct {
+	Results []PublishResult `json:"results" xml:"results"`
+}
+
+// PublishResult holds the result of a message published in a batch
+type PublishResult struct {
+	MessageID string `json:"messageId" xml:"messageId"`
+	Status    string `json:"status" xml:"status"`
+	Error     string `json:"error,omitempty" xml:"error,omitempty"`
+}
+
 // CheckMessageStatusRequest holds the request details for  CheckMessageStatus
 type CheckMessageStatusRequest struct {
 	PublisherID int     `json:"publisherId" xml:"publisherId"`
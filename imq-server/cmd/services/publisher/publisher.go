@@ -2,6 +2,7 @@ package publisher
 
 import (
 	"context"
+	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
 	"github.com/google/uuid"
@@ -20,6 +21,7 @@ type PublisherIF interface {
 	ConnectToTopic(ctx context.Context, in *ConnectToTopicRequest) (*ConnectToTopicResponse, error)
 	DisconnectFromTopic(ctx context.Context, in *DisconnectFromTopicRequest) (*DisconnectFromTopicResponse, error)
 	PublishMessage(ctx context.Context, in *PublishMessageRequest) (*PublishMessageResponse, error)
+	PublishBatch(ctx context.Context, in *PublishBatchRequest) (*PublishBatchResponse, error)
 }
 
 // NewPublisher is the factory function for the Publisher type
@@ -47,7 +49,7 @@ func (p *Publisher) ShowTopics(ctx context.Context, in *ShowTopicRequest) (*Show
 	return showTopicResponse, nil
 }
 
-// ConnectToTopic register publisher to topic
+// ConnectToTopic register publisher to topic, in addition to the topics it is already connected to
 func (p *Publisher) ConnectToTopic(ctx context.Context, in *ConnectToTopicRequest) (*ConnectToTopicResponse, error) {
 	connectToTopicResponse := &ConnectToTopicResponse{}
 
@@ -66,7 +68,7 @@ func (p *Publisher) ConnectToTopic(ctx context.Context, in *ConnectToTopicReques
 func (p *Publisher) DisconnectFromTopic(ctx context.Context, in *DisconnectFromTopicRequest) (*DisconnectFromTopicResponse, error) {
 	disconnectFromTopicResponse := &DisconnectFromTopicResponse{}
 
-	err := p.topicService.DeregisterPublisherFromTopic(ctx, in.PublisherID)
+	err := p.topicService.DeregisterPublisherFromTopic(ctx, in.PublisherID, in.TopicName)
 	if err != nil {
 		p.log.WithField("publisherId", in.PublisherID).Errorf("DisconnectFromTopic: failed to remove publisher from topic: %v", err)
 		return nil, err
@@ -82,13 +84,15 @@ func (p *Publisher) PublishMessage(ctx context.Context, in *PublishMessageReques
 	publishMessageResponse := &PublishMessageResponse{}
 
 	msg := domain.Message{
-		MessageID: uuid.New().String(),
-		Data:      in.Message.Data,
-		CretedAt:  in.Message.CretedAt,
-		ExpiresAt: in.Message.ExpiresAt,
+		MessageID:   uuid.New().String(),
+		Key:         in.Message.Key,
+		ContentType: in.Message.ContentType,
+		Headers:     in.Message.Headers,
+		Data:        in.Message.Data,
+		TTL:         time.Duration(in.Message.TTL) * time.Second,
 	}
 
-	err := p.topicService.AddMessageToTopic(ctx, in.PublisherID, msg)
+	err := p.topicService.AddMessageToTopic(ctx, in.PublisherID, in.TopicName, msg)
 	if err != nil {
 		p.log.WithField("publisherId", in.PublisherID).Errorf("PublishMessage: failed to add message to topic: %v", err)
 		return nil, err
@@ -98,3 +102,40 @@ func (p *Publisher) PublishMessage(ctx context.Context, in *PublishMessageReques
 
 	return publishMessageResponse, nil
 }
+
+// PublishBatch publishes a batch of messages to topic, the result of every message is returned in the order it was sent
+func (p *Publisher) PublishBatch(ctx context.Context, in *PublishBatchRequest) (*PublishBatchResponse, error) {
+	publishBatchResponse := &PublishBatchResponse{}
+
+	msgs := []domain.Message{}
+	for _, message := range in.Messages {
+		msgs = append(msgs, domain.Message{
+			MessageID:   uuid.New().String(),
+			Key:         message.Key,
+			ContentType: message.ContentType,
+			Headers:     message.Headers,
+			Data:        message.Data,
+			TTL:         time.Duration(message.TTL) * time.Second,
+		})
+	}
+
+	errs, err := p.topicService.AddMessagesToTopic(ctx, in.PublisherID, in.TopicName, msgs)
+	if err != nil {
+		p.log.WithField("publisherId", in.PublisherID).Errorf("PublishBatch: failed to add messages to topic: %v", err)
+		return nil, err
+	}
+
+	for i, msg := range msgs {
+		result := PublishResult{
+			MessageID: msg.MessageID,
+			Status:    statusSuccessful,
+		}
+		if errs[i] != nil {
+			result.Status = statusFailed
+			result.Error = errs[i].Error()
+		}
+		publishBatchResponse.Results = append(publishBatchResponse.Results, result)
+	}
+
+	return publishBatchResponse, nil
+}
@@ -83,12 +83,13 @@ func TestConnectToTopic_Pass(t *testing.T) {
 func TestDisconnectFromTopic_Fail(t *testing.T) {
 	req := &publisher.DisconnectFromTopicRequest{
 		PublisherID: 500,
+		TopicName:   "golang",
 	}
 
 	expectedErr := errors.New("failed to get deregister from topic")
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.DeregisterPublisherFromTopic).When(mock.Anything, req.PublisherID).Return(expectedErr)
+	mockTopicSvc.Given(domain.TopicServicesIF.DeregisterPublisherFromTopic).When(mock.Anything, req.PublisherID, req.TopicName).Return(expectedErr)
 
 	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
 	_, err := pub.DisconnectFromTopic(context.Background(), req)
@@ -100,10 +101,11 @@ func TestDisconnectFromTopic_Fail(t *testing.T) {
 func TestDisconnectFromTopic_Pass(t *testing.T) {
 	req := &publisher.DisconnectFromTopicRequest{
 		PublisherID: 500,
+		TopicName:   "golang",
 	}
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.DeregisterPublisherFromTopic).When(mock.Anything, req.PublisherID).Return(nil)
+	mockTopicSvc.Given(domain.TopicServicesIF.DeregisterPublisherFromTopic).When(mock.Anything, req.PublisherID, req.TopicName).Return(nil)
 
 	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
 	_, err := pub.DisconnectFromTopic(context.Background(), req)
@@ -116,16 +118,15 @@ func TestPublishMessage_Fail(t *testing.T) {
 	req := &publisher.PublishMessageRequest{
 		PublisherID: 5000,
 		Message: publisher.Message{
-			Data:      "test data",
-			CretedAt:  "2021-02-27 20:03:09",
-			ExpiresAt: "2021-02-27 20:04:09",
+			Data: []byte("test data"),
+			TTL:  60,
 		},
 	}
 
 	expectedErr := errors.New("failed to add message to topic")
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.AddMessageToTopic).When(mock.Anything, req.PublisherID, mock.Anything).Return(expectedErr)
+	mockTopicSvc.Given(domain.TopicServicesIF.AddMessageToTopic).When(mock.Anything, req.PublisherID, req.TopicName, mock.Anything).Return(expectedErr)
 
 	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
 	_, err := pub.PublishMessage(context.Background(), req)
@@ -137,15 +138,15 @@ func TestPublishMessage_Fail(t *testing.T) {
 func TestPublishMessage_Pass(t *testing.T) {
 	req := &publisher.PublishMessageRequest{
 		PublisherID: 5000,
+		TopicName:   "golang",
 		Message: publisher.Message{
-			Data:      "test data",
-			CretedAt:  "2021-02-27 20:03:09",
-			ExpiresAt: "2021-02-27 20:04:09",
+			Data: []byte("test data"),
+			TTL:  60,
 		},
 	}
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.AddMessageToTopic).When(mock.Anything, req.PublisherID, mock.Anything).Return(nil)
+	mockTopicSvc.Given(domain.TopicServicesIF.AddMessageToTopic).When(mock.Anything, req.PublisherID, req.TopicName, mock.Anything).Return(nil)
 
 	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
 	_, err := pub.PublishMessage(context.Background(), req)
@@ -153,3 +154,49 @@ func TestPublishMessage_Pass(t *testing.T) {
 		t.Fatalf("expected: nil \n\t got: %v", err)
 	}
 }
+
+func TestPublishBatch_Fail(t *testing.T) {
+	req := &publisher.PublishBatchRequest{
+		PublisherID: 5000,
+		Messages:    []publisher.Message{{Data: []byte("test data")}},
+	}
+
+	expectedErr := errors.New("you are not connected to any topic")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.AddMessagesToTopic).When(mock.Anything, req.PublisherID, req.TopicName, mock.Anything).Return([]error(nil), expectedErr)
+
+	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
+	_, err := pub.PublishBatch(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestPublishBatch_Pass(t *testing.T) {
+	req := &publisher.PublishBatchRequest{
+		PublisherID: 5000,
+		TopicName:   "golang",
+		Messages: []publisher.Message{
+			{Data: []byte("test data")},
+			{},
+		},
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.AddMessagesToTopic).When(mock.Anything, req.PublisherID, req.TopicName, mock.Anything).Return([]error{nil, errors.New("message cannot be empty")}, nil)
+
+	pub := publisher.NewPublisher(&logrus.Logger{}, mockTopicSvc)
+	got, err := pub.PublishBatch(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if len(got.Results) != 2 || got.Results[0].Status != "successful" || got.Results[1].Status != "failed" || got.Results[1].Error != "message cannot be empty" {
+		t.Fatalf("expected: [successful failed] \n\t got: %v", got.Results)
+	}
+
+	if got.Results[0].MessageID == "" || got.Results[0].MessageID == got.Results[1].MessageID {
+		t.Fatalf("expected: unique messageIds \n\t got: %v", got.Results)
+	}
+}
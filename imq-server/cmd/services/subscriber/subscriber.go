@@ -20,6 +20,11 @@ type SubscriberIF interface {
 	UnsubscribeFromTopic(ctx context.Context, in *UnsubscribeFromTopicRequest) (*UnsubscribeFromTopicResponse, error)
 	GetSubscribedTopics(ctx context.Context, in *GetSubscribedTopicsRequest) (*GetSubscribedTopicsResponse, error)
 	GetMessageFromTopic(ctx context.Context, in *GetMessageFromTopicRequest) (*GetMessageFromTopicResponse, error)
+	GetMessagesFromTopic(ctx context.Context, in *GetMessagesFromTopicRequest) (*GetMessagesFromTopicResponse, error)
+	WatchTopic(ctx context.Context, in *WatchTopicRequest, fn func(MessagePush) error) (*WatchTopicResponse, error)
+	UnwatchTopic(ctx context.Context, in *UnwatchTopicRequest) (*UnwatchTopicResponse, error)
+	AckMessage(ctx context.Context, in *AckMessageRequest) (*AckMessageResponse, error)
+	NackMessage(ctx context.Context, in *NackMessageRequest) (*NackMessageResponse, error)
 }
 
 // NewSubscriber is the factory function for the Subscriber
@@ -51,7 +56,9 @@ func (s *Subscriber) ShowTopics(ctx context.Context, in *ShowTopicRequest) (*Sho
 func (s *Subscriber) SubscribeToTopic(ctx context.Context, in *SubscribeToTopicRequest) (*SubscribeToTopicResponse, error) {
 	subscribeToTopicResponse := &SubscribeToTopicResponse{}
 
-	err := s.topicService.RegisterSubscriberToTopic(ctx, in.SubscriberID, in.TopicName)
+	subscription := domain.Subscription{Filter: in.Filter, GroupName: in.GroupName}
+
+	err := s.topicService.RegisterSubscriberToTopic(ctx, in.SubscriberID, in.TopicName, subscription)
 	if err != nil {
 		s.log.WithField("subscriberId", in.SubscriberID).Errorf("SubscribeToTopic: failed to register subscriber to topic: %v", err)
 		return nil, err
@@ -105,10 +112,116 @@ func (s *Subscriber) GetMessageFromTopic(ctx context.Context, in *GetMessageFrom
 	}
 
 	getMessageFromTopicResponse.Message = Message{
-		Data:      message.Data,
-		CretedAt:  message.CretedAt,
-		ExpiresAt: message.ExpiresAt,
+		MessageID:     message.MessageID,
+		Key:           message.Key,
+		ContentType:   message.ContentType,
+		Headers:       message.Headers,
+		Data:          message.Data,
+		CretedAt:      message.CretedAt,
+		ExpiresAt:     message.ExpiresAt,
+		DeliveryCount: message.DeliveryCount,
 	}
 
 	return getMessageFromTopicResponse, nil
 }
+
+// GetMessagesFromTopic fetches up to MaxCount messages published to a given topic, stopping before their data grows over MaxBytes
+func (s *Subscriber) GetMessagesFromTopic(ctx context.Context, in *GetMessagesFromTopicRequest) (*GetMessagesFromTopicResponse, error) {
+	getMessagesFromTopicResponse := &GetMessagesFromTopicResponse{}
+
+	messages, err := s.topicService.GetMessages(ctx, in.SubscriberID, in.TopicName, in.MaxCount, in.MaxBytes)
+	if err != nil {
+		s.log.WithField("subscriberId", in.SubscriberID).Errorf("GetMessagesFromTopic: failed to get messages from topic: %v", err)
+		return nil, err
+	}
+
+	for _, message := range messages {
+		getMessagesFromTopicResponse.Messages = append(getMessagesFromTopicResponse.Messages, Message{
+			MessageID:     message.MessageID,
+			Key:           message.Key,
+			ContentType:   message.ContentType,
+			Headers:       message.Headers,
+			Data:          message.Data,
+			CretedAt:      message.CretedAt,
+			ExpiresAt:     message.ExpiresAt,
+			DeliveryCount: message.DeliveryCount,
+		})
+	}
+
+	return getMessagesFromTopicResponse, nil
+}
+
+// WatchTopic registers fn to receive every new message published to a given topic, or to the topics matched by a
+// subscription pattern, each push names the topic the message was published to
+func (s *Subscriber) WatchTopic(ctx context.Context, in *WatchTopicRequest, fn func(MessagePush) error) (*WatchTopicResponse, error) {
+	watchTopicResponse := &WatchTopicResponse{}
+
+	err := s.topicService.WatchTopic(ctx, in.SubscriberID, in.TopicName, func(message domain.Message) error {
+		return fn(MessagePush{
+			TopicName: message.TopicName,
+			Message: Message{
+				MessageID:     message.MessageID,
+				Key:           message.Key,
+				ContentType:   message.ContentType,
+				Headers:       message.Headers,
+				Data:          message.Data,
+				CretedAt:      message.CretedAt,
+				ExpiresAt:     message.ExpiresAt,
+				DeliveryCount: message.DeliveryCount,
+			},
+		})
+	})
+	if err != nil {
+		s.log.WithField("subscriberId", in.SubscriberID).Errorf("WatchTopic: failed to watch topic: %v", err)
+		return nil, err
+	}
+
+	watchTopicResponse.Status = statusWatching
+
+	return watchTopicResponse, nil
+}
+
+// UnwatchTopic stops pushing new messages of a given topic to the subscriber
+func (s *Subscriber) UnwatchTopic(ctx context.Context, in *UnwatchTopicRequest) (*UnwatchTopicResponse, error) {
+	unwatchTopicResponse := &UnwatchTopicResponse{}
+
+	err := s.topicService.UnwatchTopic(ctx, in.SubscriberID, in.TopicName)
+	if err != nil {
+		s.log.WithField("subscriberId", in.SubscriberID).Errorf("UnwatchTopic: failed to unwatch topic: %v", err)
+		return nil, err
+	}
+
+	unwatchTopicResponse.Status = statusSuccesful
+
+	return unwatchTopicResponse, nil
+}
+
+// AckMessage confirms that a message received from a given topic has been processed
+func (s *Subscriber) AckMessage(ctx context.Context, in *AckMessageRequest) (*AckMessageResponse, error) {
+	ackMessageResponse := &AckMessageResponse{}
+
+	err := s.topicService.AckMessage(ctx, in.SubscriberID, in.TopicName, in.MessageID)
+	if err != nil {
+		s.log.WithField("subscriberId", in.SubscriberID).Errorf("AckMessage: failed to acknowledge message: %v", err)
+		return nil, err
+	}
+
+	ackMessageResponse.Status = statusAcknowledged
+
+	return ackMessageResponse, nil
+}
+
+// NackMessage rejects a message received from a given topic so that it is delivered again
+func (s *Subscriber) NackMessage(ctx context.Context, in *NackMessageRequest) (*NackMessageResponse, error) {
+	nackMessageResponse := &NackMessageResponse{}
+
+	err := s.topicService.NackMessage(ctx, in.SubscriberID, in.TopicName, in.MessageID)
+	if err != nil {
+		s.log.WithField("subscriberId", in.SubscriberID).Errorf("NackMessage: failed to reject message: %v", err)
+		return nil, err
+	}
+
+	nackMessageResponse.Status = statusRequeued
+
+	return nackMessageResponse, nil
+}
@@ -3,7 +3,9 @@ package subscriber_test
 import (
 	"context"
 	"errors"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/subscriber"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
@@ -55,7 +57,7 @@ func TestSubscribeToTopic_Fail(t *testing.T) {
 	expectedErr := errors.New("failed to register to topic")
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.RegisterSubscriberToTopic).When(mock.Anything, req.SubscriberID, req.TopicName).Return(expectedErr)
+	mockTopicSvc.Given(domain.TopicServicesIF.RegisterSubscriberToTopic).When(mock.Anything, req.SubscriberID, req.TopicName, domain.Subscription{}).Return(expectedErr)
 
 	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
 	_, err := sub.SubscribeToTopic(context.Background(), req)
@@ -68,10 +70,13 @@ func TestSubscribeToTopic_Pass(t *testing.T) {
 	req := &subscriber.SubscribeToTopicRequest{
 		SubscriberID: 6000,
 		TopicName:    "golang",
+		GroupName:    "billing",
 	}
 
+	subscription := domain.Subscription{GroupName: req.GroupName}
+
 	mockTopicSvc := &test.MockTopicServiceIF{}
-	mockTopicSvc.Given(domain.TopicServicesIF.RegisterSubscriberToTopic).When(mock.Anything, req.SubscriberID, req.TopicName).Return(nil)
+	mockTopicSvc.Given(domain.TopicServicesIF.RegisterSubscriberToTopic).When(mock.Anything, req.SubscriberID, req.TopicName, subscription).Return(nil)
 
 	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
 	_, err := sub.SubscribeToTopic(context.Background(), req)
@@ -174,16 +179,154 @@ func TestGetMessageFromTopic_Pass(t *testing.T) {
 
 	resp := &domain.Message{
 		MessageID: "123",
-		Data:      "test data",
-		CretedAt:  "2021-02-27 20:03:09",
-		ExpiresAt: "2021-02-27 20:04:09",
+		Data:      []byte("test data"),
+		CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+		ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 	}
 
 	mockTopicSvc := &test.MockTopicServiceIF{}
 	mockTopicSvc.Given(domain.TopicServicesIF.GetMessage).When(mock.Anything, req.SubscriberID, req.TopicName).Return(resp, nil)
 
 	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
-	_, err := sub.GetMessageFromTopic(context.Background(), req)
+	got, err := sub.GetMessageFromTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.Message.MessageID != resp.MessageID {
+		t.Fatalf("expected: %v \n\t got: %v", resp.MessageID, got.Message.MessageID)
+	}
+}
+
+func TestGetMessagesFromTopic_Pass(t *testing.T) {
+	req := &subscriber.GetMessagesFromTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+		MaxCount:     2,
+		MaxBytes:     1024,
+	}
+
+	resp := []domain.Message{
+		{MessageID: "123", Data: []byte("test data 1")},
+		{MessageID: "124", Data: []byte("test data 2")},
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.GetMessages).When(mock.Anything, req.SubscriberID, req.TopicName, req.MaxCount, req.MaxBytes).Return(resp, nil)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	got, err := sub.GetMessagesFromTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := []subscriber.Message{
+		{MessageID: "123", Data: []byte("test data 1")},
+		{MessageID: "124", Data: []byte("test data 2")},
+	}
+	if !reflect.DeepEqual(expected, got.Messages) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got.Messages)
+	}
+}
+
+func TestWatchTopic_Fail(t *testing.T) {
+	req := &subscriber.WatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+	}
+
+	expectedErr := errors.New("you are not subscribed to this topic")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.WatchTopic).When(mock.Anything, req.SubscriberID, req.TopicName, mock.Anything).Return(expectedErr)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.WatchTopic(context.Background(), req, func(subscriber.MessagePush) error { return nil })
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestWatchTopic_Pass(t *testing.T) {
+	req := &subscriber.WatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.WatchTopic).When(mock.Anything, req.SubscriberID, req.TopicName, mock.Anything).Return(nil)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.WatchTopic(context.Background(), req, func(subscriber.MessagePush) error { return nil })
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestUnwatchTopic_Pass(t *testing.T) {
+	req := &subscriber.UnwatchTopicRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.UnwatchTopic).When(mock.Anything, req.SubscriberID, req.TopicName).Return(nil)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.UnwatchTopic(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestAckMessage_Fail(t *testing.T) {
+	req := &subscriber.AckMessageRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+		MessageID:    "123",
+	}
+
+	expectedErr := errors.New("message is not awaiting acknowledgement")
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.AckMessage).When(mock.Anything, req.SubscriberID, req.TopicName, req.MessageID).Return(expectedErr)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.AckMessage(context.Background(), req)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestAckMessage_Pass(t *testing.T) {
+	req := &subscriber.AckMessageRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+		MessageID:    "123",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.AckMessage).When(mock.Anything, req.SubscriberID, req.TopicName, req.MessageID).Return(nil)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.AckMessage(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestNackMessage_Pass(t *testing.T) {
+	req := &subscriber.NackMessageRequest{
+		SubscriberID: 6000,
+		TopicName:    "golang",
+		MessageID:    "123",
+	}
+
+	mockTopicSvc := &test.MockTopicServiceIF{}
+	mockTopicSvc.Given(domain.TopicServicesIF.NackMessage).When(mock.Anything, req.SubscriberID, req.TopicName, req.MessageID).Return(nil)
+
+	sub := subscriber.NewSubscriber(&logrus.Logger{}, mockTopicSvc)
+	_, err := sub.NackMessage(context.Background(), req)
 	if err != nil {
 		t.Fatalf("expected: nil \n\t got: %v", err)
 	}
@@ -2,15 +2,53 @@ package config
 
 // Settings contain app environment configuration settngs
 type Settings struct {
-	ImqServerHost   string `env:"IMQ_SERVER_HOST" envDefault:"localhost"`
-	ImqServerPort   int    `env:"IMQ_SERVER_PORT" envDefault:"80"`
-	PublisherCount  int    `env:"PUBLISHER_COUNT" envDefault:"5"`
-	SubscriberCount int    `env:"SUBSCRIBER_COUNT" envDefault:"5"`
-	ShutdownGrace   int    `env:"SHUTDOWN_GRACE" envDefault:"10"`
+	ImqServerHost string `env:"IMQ_SERVER_HOST" envDefault:"localhost"`
+	ImqServerPort int    `env:"IMQ_SERVER_PORT" envDefault:"80"`
+	ShutdownGrace int    `env:"SHUTDOWN_GRACE" envDefault:"10"`
+
+	// MaxConnections bounds the clients connected at the same time, ReadTimeout is how long a client has to send
+	// a request once it started and IdleTimeout closes the connections left unused for that long, in seconds
+	MaxConnections int `env:"MAX_CONNECTIONS" envDefault:"1000"`
+	ReadTimeout    int `env:"READ_TIMEOUT" envDefault:"30"`
+	IdleTimeout    int `env:"IDLE_TIMEOUT" envDefault:"600"`
+
+	// TLS is enabled when the certificate is set, clients with a certificate signed by the client CA
+	// are authenticated as the client named after the common name of the certificate
+	TLSCertFile          string `env:"TLS_CERT_FILE"`
+	TLSKeyFile           string `env:"TLS_KEY_FILE"`
+	TLSClientCAFile      string `env:"TLS_CLIENT_CA_FILE"`
+	TLSRequireClientCert bool   `env:"TLS_REQUIRE_CLIENT_CERT" envDefault:"false"`
 
 	ImqQueueHost string `env:"IMQ_QUEUE_HOST" envDefault:""`
 	ImqQueuePort int    `env:"IMQ_QUEUE_PORT" envDefault:""`
 
+	VisibilityTimeout int `env:"VISIBILITY_TIMEOUT" envDefault:"30"`
+	MaxDeliveries     int `env:"MAX_DELIVERIES" envDefault:"5"`
+
+	// DefaultTTL and MaxTTL apply to the topics that do not set their own, in seconds, a zero MaxTTL allows any ttl
+	DefaultTTL int `env:"DEFAULT_TTL" envDefault:"3600"`
+	MaxTTL     int `env:"MAX_TTL" envDefault:"604800"`
+
+	// MaxTopicMessages and MaxTopicBytes bound the messages held by every partition of a topic that sets no limits of
+	// its own, a zero limit is not enforced. OverflowPolicy is reject, dropOldest or block and is the default of a topic
+	// as well, block waits up to OverflowTimeout seconds for room in the partition
+	MaxTopicMessages int    `env:"MAX_TOPIC_MESSAGES" envDefault:"100000"`
+	MaxTopicBytes    int    `env:"MAX_TOPIC_BYTES" envDefault:"67108864"`
+	OverflowPolicy   string `env:"OVERFLOW_POLICY" envDefault:"reject"`
+	OverflowTimeout  int    `env:"OVERFLOW_TIMEOUT" envDefault:"5"`
+
+	WALDir         string `env:"WAL_DIR" envDefault:"data/wal"`
+	WALSegmentSize int64  `env:"WAL_SEGMENT_SIZE" envDefault:"16777216"`
+
+	DbBackend string   `env:"DB_BACKEND" envDefault:"mysql"`
+	DbFile    string   `env:"DB_FILE" envDefault:"data/imq.json"`
+	Topics    []string `env:"TOPICS" envSeparator:","`
+
+	AutoMigrate bool `env:"AUTO_MIGRATE" envDefault:"false"`
+
+	// Clients are registered on startup when their api key is unknown, each one as name:role:apiKey
+	Clients []string `env:"CLIENTS" envSeparator:","`
+
 	DbUserName string `env:"DB_USERNAME" envDefault:"root"`
 	DbPassword string `env:"DB_PASSWORD" envDefault:"password"`
 	DbHost     string `env:"DB_HOST" envDefault:"localhost"`
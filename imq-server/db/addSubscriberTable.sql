@@ -1,4 +0,0 @@
-CREATE TABLE `Subscriber` (
-  `subscriberId` int(10) NOT NULL,
-  PRIMARY KEY (`subscriberId`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -1,5 +0,0 @@
-CREATE TABLE `Topic` (
-  `topicId` varchar(45) NOT NULL,
-  `name` varchar(45) DEFAULT NULL,
-  PRIMARY KEY (`topicId`)
-) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
\ No newline at end of file
@@ -0,0 +1,135 @@
+package domain
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// roles a client can be registered with
+const (
+	RolePublisher  = "publisher"
+	RoleSubscriber = "subscriber"
+	RoleAdmin      = "admin"
+)
+
+// ClientService implement the ClientServicesIF interface
+type ClientService struct {
+	log *logrus.Logger
+	db  storage.DatabaseIF
+}
+
+// ClientServicesIF is the interface of client service
+type ClientServicesIF interface {
+	Authenticate(ctx context.Context, apiKey string) (*Client, error)
+	AuthenticateCertificate(ctx context.Context, subject string) (*Client, error)
+	RegisterClient(ctx context.Context, name string, role string, apiKey string) (*Client, error)
+}
+
+// clientNamePattern is the format of a client name, it fits the name column of the Client table
+var clientNamePattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,45}$`)
+
+// NewClient is the factory function for the ClientService type
+func NewClient(log *logrus.Logger, db storage.DatabaseIF) ClientServicesIF {
+	return &ClientService{
+		log: log,
+		db:  db,
+	}
+}
+
+// Authenticate finds the client the api key was issued to
+func (c *ClientService) Authenticate(ctx context.Context, apiKey string) (*Client, error) {
+	if apiKey == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	client, notFound, err := c.db.GetClientByAPIKey(ctx, HashAPIKey(apiKey))
+	if err != nil {
+		c.log.Errorf("Authenticate: failed to get client: %v", err)
+		return nil, err
+	}
+
+	if notFound {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Client{
+		ClientID: client.ClientID,
+		Name:     client.Name,
+		Role:     client.Role,
+	}, nil
+}
+
+// AuthenticateCertificate finds the client named after the subject of a verified client certificate
+func (c *ClientService) AuthenticateCertificate(ctx context.Context, subject string) (*Client, error) {
+	if subject == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	client, notFound, err := c.db.GetClientByName(ctx, subject)
+	if err != nil {
+		c.log.WithField("clientName", subject).Errorf("AuthenticateCertificate: failed to get client: %v", err)
+		return nil, err
+	}
+
+	if notFound {
+		return nil, ErrInvalidCredentials
+	}
+
+	return &Client{
+		ClientID: client.ClientID,
+		Name:     client.Name,
+		Role:     client.Role,
+	}, nil
+}
+
+// RegisterClient stores a new client that authenticates with the given api key
+func (c *ClientService) RegisterClient(ctx context.Context, name string, role string, apiKey string) (*Client, error) {
+	if !clientNamePattern.MatchString(name) {
+		return nil, ErrInvalidClientName
+	}
+
+	if role != RolePublisher && role != RoleSubscriber && role != RoleAdmin {
+		return nil, ErrInvalidClientRole
+	}
+
+	if apiKey == "" {
+		return nil, ErrAPIKeyRequired
+	}
+
+	clientID, err := c.db.InsertClient(ctx, storage.Client{
+		Name:       name,
+		Role:       role,
+		APIKeyHash: HashAPIKey(apiKey),
+	})
+	if err != nil {
+		c.log.WithField("clientName", name).Errorf("RegisterClient: failed to insert client: %v", err)
+		return nil, err
+	}
+
+	return &Client{
+		ClientID: clientID,
+		Name:     name,
+		Role:     role,
+	}, nil
+}
+
+// GenerateAPIKey returns a new random api key
+func GenerateAPIKey() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashAPIKey returns the hash of the api key as it is stored in the Client table
+func HashAPIKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,94 @@
+package domain_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
+	"github.com/WinnersonKharsunai/GraduationProject/server/test"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAuthenticate_Pass(t *testing.T) {
+	stored := &storage.Client{ClientID: 1000, Name: "orders", Role: domain.RolePublisher, APIKeyHash: domain.HashAPIKey("secret")}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetClientByAPIKey).When(mock.Anything, domain.HashAPIKey("secret")).Return(stored, false, nil)
+
+	expected := &domain.Client{ClientID: 1000, Name: "orders", Role: domain.RolePublisher}
+
+	client := domain.NewClient(&logrus.Logger{}, mockDb)
+	got, err := client.Authenticate(context.Background(), "secret")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestAuthenticate_UnknownKeyFail(t *testing.T) {
+	expectedErr := errors.New("invalid credentials")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetClientByAPIKey).When(mock.Anything, domain.HashAPIKey("secret")).Return(&storage.Client{}, true, nil)
+
+	client := domain.NewClient(&logrus.Logger{}, mockDb)
+	_, err := client.Authenticate(context.Background(), "secret")
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestAuthenticateCertificate_Pass(t *testing.T) {
+	stored := &storage.Client{ClientID: 1000, Name: "orders", Role: domain.RoleSubscriber}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetClientByName).When(mock.Anything, "orders").Return(stored, false, nil)
+
+	expected := &domain.Client{ClientID: 1000, Name: "orders", Role: domain.RoleSubscriber}
+
+	client := domain.NewClient(&logrus.Logger{}, mockDb)
+	got, err := client.AuthenticateCertificate(context.Background(), "orders")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestRegisterClient_Pass(t *testing.T) {
+	stored := storage.Client{Name: "orders", Role: domain.RoleSubscriber, APIKeyHash: domain.HashAPIKey("secret")}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.InsertClient).When(mock.Anything, stored).Return(1000, nil)
+
+	expected := &domain.Client{ClientID: 1000, Name: "orders", Role: domain.RoleSubscriber}
+
+	client := domain.NewClient(&logrus.Logger{}, mockDb)
+	got, err := client.RegisterClient(context.Background(), "orders", domain.RoleSubscriber, "secret")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestRegisterClient_InvalidRoleFail(t *testing.T) {
+	expectedErr := errors.New("invalid client role")
+
+	client := domain.NewClient(&logrus.Logger{}, &test.MockDatabaseIF{})
+	_, err := client.RegisterClient(context.Background(), "orders", "root", "secret")
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
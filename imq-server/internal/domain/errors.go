@@ -0,0 +1,32 @@
+package domain
+
+import "errors"
+
+// errors returned by the topic and client services, callers tell them apart with errors.Is
+var (
+	ErrTopicNotFound      = errors.New("topic not found")
+	ErrTopicExists        = errors.New("topic already exists")
+	ErrInvalidTopicName   = errors.New("invalid topic name")
+	ErrTopicNotEmpty      = errors.New("topic still has messages, use force to delete it")
+	ErrAlreadyRegistered  = errors.New("you are already connected to this topic")
+	ErrNotRegistered      = errors.New("you are not connected to this topic")
+	ErrNotConnected       = errors.New("you are not connected to any topic")
+	ErrTopicRequired      = errors.New("a topic name is required when connected to several topics")
+	ErrAlreadySubscribed  = errors.New("you are already subscribed to this topic")
+	ErrInvalidBatchSize   = errors.New("a batch has to hold between 1 and 100 messages")
+	ErrNotSubscribed      = errors.New("you are not subscribed to this topic")
+	ErrInvalidPattern     = errors.New("invalid subscription pattern")
+	ErrInvalidFilter      = errors.New("invalid filter expression")
+	ErrInvalidGroupName   = errors.New("invalid consumer group name")
+	ErrInvalidPartitions  = errors.New("a topic has to have between 1 and 64 partitions")
+	ErrInvalidTTL         = errors.New("ttl cannot be negative")
+	ErrTTLTooLong         = errors.New("ttl exceeds the maximum ttl of the topic")
+	ErrInvalidTopicTTL    = errors.New("default ttl of a topic cannot be negative or exceed its maximum ttl")
+	ErrKeyTooLong         = errors.New("message key cannot be longer than 255 characters")
+	ErrContentTypeTooLong = errors.New("message content type cannot be longer than 100 characters")
+	ErrInvalidLimits      = errors.New("limits of a topic cannot be negative and its overflow policy has to be reject, dropOldest or block")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrInvalidClientName  = errors.New("invalid client name")
+	ErrInvalidClientRole  = errors.New("invalid client role")
+	ErrAPIKeyRequired     = errors.New("api key is required")
+)
@@ -0,0 +1,323 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A filter is a selector on the headers of a message such as region = 'eu' AND priority > 3. A comparison is made of
+// a header name, one of the operators = != <> < <= > >= and a value, which is either a quoted string compared as text
+// or a number compared with the header read as a number. Comparisons are combined with AND, OR, NOT and parentheses.
+// A comparison on a header the message does not hold, or holds as text when compared with a number, is false
+
+// maxFilterLength fits the filter column of the SubscriberTopicMap table
+const maxFilterLength = 255
+
+// Filter reports whether a message with the given headers is accepted
+type Filter func(headers map[string]string) bool
+
+// ParseFilter parses the filter expression, ErrInvalidFilter is returned when the expression is malformed
+func ParseFilter(expression string) (Filter, error) {
+	if len(expression) > maxFilterLength {
+		return nil, fmt.Errorf("%w: longer than %v characters", ErrInvalidFilter, maxFilterLength)
+	}
+
+	tokens, err := tokenizeFilter(expression)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	p := &filterParser{tokens: tokens}
+
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+	}
+
+	if tok := p.peek(); tok.kind != tokenEnd {
+		return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFilter, tok.text)
+	}
+
+	return filter, nil
+}
+
+type tokenKind int
+
+const (
+	tokenEnd tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenOperator
+	tokenOpen
+	tokenClose
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+}
+
+// tokenizeFilter splits the expression into header names, keywords, strings, numbers, operators and parentheses
+func tokenizeFilter(expression string) ([]filterToken, error) {
+	tokens := []filterToken{}
+	runes := []rune(expression)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, filterToken{kind: tokenOpen, text: "("})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, filterToken{kind: tokenClose, text: ")"})
+			i++
+
+		case r == '\'':
+			// a quote is written twice inside a string
+			var b strings.Builder
+			closed := false
+			for i++; i < len(runes); i++ {
+				if runes[i] == '\'' {
+					if i+1 < len(runes) && runes[i+1] == '\'' {
+						b.WriteRune('\'')
+						i++
+						continue
+					}
+					closed = true
+					i++
+					break
+				}
+				b.WriteRune(runes[i])
+			}
+			if !closed {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, filterToken{kind: tokenString, text: b.String()})
+
+		case strings.ContainsRune("=!<>", r):
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				op += string(runes[i+1])
+			}
+			if !validOperator(op) {
+				return nil, fmt.Errorf("invalid operator %q", op)
+			}
+			tokens = append(tokens, filterToken{kind: tokenOperator, text: op})
+			i += len(op)
+
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			j := i + 1
+			for j < len(runes) && (unicode.IsDigit(runes[j]) || runes[j] == '.') {
+				j++
+			}
+			if _, err := strconv.ParseFloat(string(runes[i:j]), 64); err != nil {
+				return nil, fmt.Errorf("invalid number %q", string(runes[i:j]))
+			}
+			tokens = append(tokens, filterToken{kind: tokenNumber, text: string(runes[i:j])})
+			i = j
+
+		case unicode.IsLetter(r) || r == '_':
+			j := i + 1
+			for j < len(runes) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j]) || strings.ContainsRune("_-.", runes[j])) {
+				j++
+			}
+			tokens = append(tokens, filterToken{kind: tokenIdent, text: string(runes[i:j])})
+			i = j
+
+		default:
+			return nil, fmt.Errorf("unexpected %q", string(r))
+		}
+	}
+
+	return tokens, nil
+}
+
+// filterParser builds a Filter from the tokens of an expression, NOT binds tighter than AND which binds tighter than OR
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return filterToken{kind: tokenEnd}
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.peek()
+	if p.pos < len(p.tokens) {
+		p.pos++
+	}
+	return tok
+}
+
+// keyword consumes the next token when it is the given keyword, keywords are not case sensitive
+func (p *filterParser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == tokenIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("OR") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(headers map[string]string) bool { return l(headers) || right(headers) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("AND") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		l := left
+		left = func(headers map[string]string) bool { return l(headers) && right(headers) }
+	}
+
+	return left, nil
+}
+
+func (p *filterParser) parseNot() (Filter, error) {
+	if p.keyword("NOT") {
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+
+		return func(headers map[string]string) bool { return !operand(headers) }, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *filterParser) parsePrimary() (Filter, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenOpen:
+		filter, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.next().kind != tokenClose {
+			return nil, errors.New("missing closing parenthesis")
+		}
+
+		return filter, nil
+
+	case tokenIdent:
+		return p.parseComparison(tok.text)
+
+	case tokenEnd:
+		return nil, errors.New("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected %q", tok.text)
+	}
+}
+
+// parseComparison parses the operator and value compared with the header
+func (p *filterParser) parseComparison(header string) (Filter, error) {
+	op := p.next()
+	if op.kind != tokenOperator {
+		return nil, fmt.Errorf("expected an operator after %q", header)
+	}
+
+	value := p.next()
+
+	switch value.kind {
+	case tokenString:
+		return func(headers map[string]string) bool {
+			v, ok := headers[header]
+			return ok && compare(strings.Compare(v, value.text), op.text)
+		}, nil
+
+	case tokenNumber:
+		n, _ := strconv.ParseFloat(value.text, 64)
+		return func(headers map[string]string) bool {
+			v, ok := headers[header]
+			if !ok {
+				return false
+			}
+
+			h, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return false
+			}
+
+			switch {
+			case h < n:
+				return compare(-1, op.text)
+			case h > n:
+				return compare(1, op.text)
+			default:
+				return compare(0, op.text)
+			}
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("expected a string or number after %q", op.text)
+	}
+}
+
+func validOperator(op string) bool {
+	switch op {
+	case "=", "!=", "<>", "<", "<=", ">", ">=":
+		return true
+	default:
+		return false
+	}
+}
+
+// compare reports whether the result of a comparison, negative, zero or positive, satisfies the operator
+func compare(result int, op string) bool {
+	switch op {
+	case "=":
+		return result == 0
+	case "!=", "<>":
+		return result != 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	default:
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+)
+
+func TestParseFilter_Fail(t *testing.T) {
+	expressions := []string{
+		"",
+		"region",
+		"region =",
+		"region = eu",
+		"region == 'eu'",
+		"region = 'eu",
+		"region = 'eu' AND",
+		"(region = 'eu'",
+		"region = 'eu')",
+		"priority > 3 4",
+		"priority ! 3",
+		"priority > -",
+	}
+
+	for _, expression := range expressions {
+		_, err := domain.ParseFilter(expression)
+		if !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Fatalf("%q: expected: %v \n\t got: %v", expression, domain.ErrInvalidFilter, err)
+		}
+	}
+}
+
+func TestParseFilter_Pass(t *testing.T) {
+	headers := map[string]string{"region": "eu", "priority": "5", "x-customer": "o'brien"}
+
+	tests := []struct {
+		expression string
+		expected   bool
+	}{
+		{expression: "region = 'eu'", expected: true},
+		{expression: "region != 'eu'", expected: false},
+		{expression: "region <> 'us'", expected: true},
+		{expression: "priority > 3", expected: true},
+		{expression: "priority >= 5.0", expected: true},
+		{expression: "priority < 5", expected: false},
+		{expression: "priority <= -1", expected: false},
+		{expression: "region = 'eu' AND priority > 3", expected: true},
+		{expression: "region = 'us' and priority > 3", expected: false},
+		{expression: "region = 'us' OR priority > 3", expected: true},
+		{expression: "NOT region = 'us'", expected: true},
+		{expression: "region = 'us' OR region = 'eu' AND priority > 9", expected: false},
+		{expression: "(region = 'us' OR region = 'eu') AND NOT (priority > 9)", expected: true},
+		{expression: "x-customer = 'o''brien'", expected: true},
+		{expression: "missing = 'eu'", expected: false},
+		{expression: "NOT missing = 'eu'", expected: true},
+		{expression: "region > 3", expected: false},
+	}
+
+	for _, tc := range tests {
+		filter, err := domain.ParseFilter(tc.expression)
+		if err != nil {
+			t.Fatalf("%q: expected: nil \n\t got: %v", tc.expression, err)
+		}
+
+		if got := filter(headers); got != tc.expected {
+			t.Fatalf("%q: expected: %v \n\t got: %v", tc.expression, tc.expected, got)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "regexp"
+
+// A consumer group is a named set of subscribers reading a topic together, every message of the topic is delivered
+// to one member of the group while every group and every subscriber reading on its own gets each message
+
+// maxGroupNameLength fits the groupName column of the SubscriberTopicMap and ConsumerGroupOffset tables
+const maxGroupNameLength = 45
+
+// groupNamePattern is the format of a consumer group name
+var groupNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+func validGroupName(groupName string) bool {
+	return len(groupName) <= maxGroupNameLength && groupNamePattern.MatchString(groupName)
+}
@@ -1,9 +1,87 @@
 package domain
 
-// Message is use to hold data sent by client
+import "time"
+
+// Message is use to hold data sent by client, TTL is the time to live requested by the publisher
+// and the default ttl of the topic is used when it is zero. TopicName is set on the messages read from a topic
 type Message struct {
-	MessageID string
-	Data      string
-	CretedAt  string
-	ExpiresAt string
+	MessageID     string
+	TopicName     string
+	Key           string
+	ContentType   string
+	Headers       map[string]string
+	Data          []byte
+	TTL           time.Duration
+	CretedAt      time.Time
+	ExpiresAt     time.Time
+	DeliveryCount int
+}
+
+// DeadMessage is use to hold a message moved to the dead letter queue
+type DeadMessage struct {
+	Message
+	Reason string
+	DeadAt time.Time
+}
+
+// TopicDescription is use to hold the state of a topic
+type TopicDescription struct {
+	Name         string
+	Partitions   int
+	Depth        int
+	Bytes        int
+	DeadMessages int
+	Publishers   int
+	Subscribers  int
+	TTL          TopicTTL
+	Limits       TopicLimits
+}
+
+// Subscription is use to hold the filter and consumer group of a subscription to a topic, both are optional
+type Subscription struct {
+	Filter    string
+	GroupName string
+}
+
+// ConsumerGroup is use to hold the members of a consumer group reading a topic along with how many messages the
+// group has not acknowledged and how many it has not read yet, and the state of the group for every partition
+type ConsumerGroup struct {
+	Name       string
+	Members    []int
+	Pending    int
+	Lag        int
+	Partitions []GroupPartition
+}
+
+// GroupPartition is use to hold the members of a consumer group a partition is assigned to, empty when the group has
+// no member, along with the offset the group resumes reading the partition from, how many messages of the partition
+// it has not acknowledged and how many it has not read yet
+type GroupPartition struct {
+	Partition int
+	Members   []int
+	Offset    int64
+	Pending   int
+	Lag       int
+}
+
+// TopicLimits is use to hold how many messages and bytes every partition of a topic holds at most, zero when
+// not enforced, and what happens to a message published to a partition once it is full. The limits set for a
+// topic fall back to the ones of the server when left at zero or empty
+type TopicLimits struct {
+	MaxMessages int
+	MaxBytes    int
+	Overflow    string
+}
+
+// TopicTTL is use to hold the default and maximum time to live of the messages of a topic, zero when not set
+type TopicTTL struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
+}
+
+// Client is use to hold an authenticated client
+type Client struct {
+	ClientID int
+	Name     string
+	Role     string
 }
@@ -0,0 +1,16 @@
+package domain
+
+// A topic is split into partitions that are read concurrently. The messages published with a key always go to the
+// same partition so they are read in the order they were published, the messages without a key are spread over the
+// partitions in turn. Every partition is read by a single member of a consumer group, so the members of a group read
+// the messages sharing a key in order, unless the group has more members than the topic has partitions. The members
+// sharing a partition then take its messages in turn, so a topic needs as many partitions as the largest group reading
+// it to keep both the order of its keys and every member busy
+
+// maxPartitions is the largest number of partitions a topic is created with
+const maxPartitions = 64
+
+// validPartitions reports whether a topic can be created with the given number of partitions, zero creates a single one
+func validPartitions(partitions int) bool {
+	return partitions >= 0 && partitions <= maxPartitions
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Topic names are hierarchical, made of words separated by dots such as orders.eu.created. A subscription
+// pattern is a topic name where a word can be replaced by a wildcard, * stands for exactly one word and #
+// stands for zero or more words, so orders.* matches orders.eu and orders.# matches orders.eu.created
+const (
+	wildcardWord  = "*"
+	wildcardWords = "#"
+
+	// maxTopicNameLength fits the name column of the Topic table and the pattern column of the SubscriberPatternMap table
+	maxTopicNameLength = 45
+)
+
+// topicNamePattern is the format of a topic name
+var topicNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+(\.[A-Za-z0-9_-]+)*$`)
+
+// subscriptionPattern is the format of a subscription pattern
+var subscriptionPattern = regexp.MustCompile(`^([A-Za-z0-9_-]+|\*|#)(\.([A-Za-z0-9_-]+|\*|#))*$`)
+
+// MatchTopic reports whether the topic name is matched by the subscription pattern,
+// a pattern without wildcards only matches the topic of the same name
+func MatchTopic(pattern string, topicName string) bool {
+	return matchWords(strings.Split(pattern, "."), strings.Split(topicName, "."))
+}
+
+func matchWords(pattern []string, words []string) bool {
+	if len(pattern) == 0 {
+		return len(words) == 0
+	}
+
+	if pattern[0] == wildcardWords {
+		return matchWords(pattern[1:], words) || (len(words) > 0 && matchWords(pattern, words[1:]))
+	}
+
+	if len(words) == 0 {
+		return false
+	}
+
+	return (pattern[0] == wildcardWord || pattern[0] == words[0]) && matchWords(pattern[1:], words[1:])
+}
+
+// isPattern reports whether the name holds a wildcard and so stands for a subscription pattern rather than a topic
+func isPattern(name string) bool {
+	return strings.Contains(name, wildcardWord) || strings.Contains(name, wildcardWords)
+}
+
+func validTopicName(topicName string) bool {
+	return len(topicName) <= maxTopicNameLength && topicNamePattern.MatchString(topicName)
+}
+
+func validPattern(pattern string) bool {
+	return len(pattern) <= maxTopicNameLength && subscriptionPattern.MatchString(pattern)
+}
@@ -0,0 +1,38 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+)
+
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		topicName string
+		expected  bool
+	}{
+		{pattern: "orders.eu.created", topicName: "orders.eu.created", expected: true},
+		{pattern: "orders.eu", topicName: "orders.eu.created", expected: false},
+		{pattern: "orders.*", topicName: "orders.eu", expected: true},
+		{pattern: "orders.*", topicName: "orders", expected: false},
+		{pattern: "orders.*", topicName: "orders.eu.created", expected: false},
+		{pattern: "*.eu.*", topicName: "orders.eu.created", expected: true},
+		{pattern: "*.eu.*", topicName: "orders.us.created", expected: false},
+		{pattern: "orders.#", topicName: "orders", expected: true},
+		{pattern: "orders.#", topicName: "orders.eu.created", expected: true},
+		{pattern: "orders.#", topicName: "billing.eu", expected: false},
+		{pattern: "#.created", topicName: "orders.eu.created", expected: true},
+		{pattern: "#.created", topicName: "orders.eu.deleted", expected: false},
+		{pattern: "orders.#.created", topicName: "orders.created", expected: true},
+		{pattern: "orders.#.created", topicName: "orders.eu.west.created", expected: true},
+		{pattern: "#", topicName: "golang", expected: true},
+	}
+
+	for _, tc := range tests {
+		got := domain.MatchTopic(tc.pattern, tc.topicName)
+		if got != tc.expected {
+			t.Fatalf("%v on %v: expected: %v \n\t got: %v", tc.pattern, tc.topicName, tc.expected, got)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package domain
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
@@ -14,26 +17,76 @@ type TopicService struct {
 	log   *logrus.Logger
 	db    storage.DatabaseIF
 	queue queue.ImqQueueIF
+	opts  TopicOptions
+	// mu guards watchers, the subscribers watching a subscription pattern
+	mu       sync.Mutex
+	watchers map[patternWatch]*patternWatcher
+}
+
+// patternWatch identifies a subscriber watching a subscription pattern
+type patternWatch struct {
+	subscriberID int
+	pattern      string
+}
+
+// patternWatcher holds the function messages of the topics matched by a watched pattern are pushed to,
+// along with the topics it is already registered to in the queue
+type patternWatcher struct {
+	fn       func(Message) error
+	topicIDs map[string]bool
+}
+
+// TopicOptions holds the time to live used for the topics that do not set their own, a zero MaxTTL allows any ttl
+type TopicOptions struct {
+	DefaultTTL time.Duration
+	MaxTTL     time.Duration
 }
 
 // TopicServicesIF is the interaface of topic service
 type TopicServicesIF interface {
 	GetTopics(ctx context.Context, publisherID int) (*[]string, error)
 	RegisterPublisherToTopic(ctx context.Context, publisherID int, topicName string) error
-	DeregisterPublisherFromTopic(ctx context.Context, publisherID int) error
-	AddMessageToTopic(ctx context.Context, publisherID int, message Message) error
+	DeregisterPublisherFromTopic(ctx context.Context, publisherID int, topicName string) error
+	AddMessageToTopic(ctx context.Context, publisherID int, topicName string, message Message) error
+	AddMessagesToTopic(ctx context.Context, publisherID int, topicName string, messages []Message) ([]error, error)
 	GetMessage(ctx context.Context, subscriberID int, topicName string) (*Message, error)
-	RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string) error
+	GetMessages(ctx context.Context, subscriberID int, topicName string, maxCount int, maxBytes int) ([]Message, error)
+	RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string, subscription Subscription) error
 	DeregisterSubscriberFromTopic(ctx context.Context, subscriberID int, topicName string) error
 	GetRegisteredTopic(ctx context.Context, subscriberID int) (*[]string, error)
+	WatchTopic(ctx context.Context, subscriberID int, topicName string, fn func(Message) error) error
+	UnwatchTopic(ctx context.Context, subscriberID int, topicName string) error
+	AckMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error
+	NackMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error
+	ListDeadMessages(ctx context.Context, topicName string) ([]DeadMessage, error)
+	PurgeDeadMessages(ctx context.Context, topicName string, messageID string) (int, error)
+	ReplayDeadMessages(ctx context.Context, topicName string, messageID string) (int, error)
+	CreateTopic(ctx context.Context, topicID string, topicName string, ttl TopicTTL, partitions int, limits TopicLimits) error
+	UpdateTopicTTL(ctx context.Context, topicName string, ttl TopicTTL) error
+	UpdateTopicLimits(ctx context.Context, topicName string, limits TopicLimits) error
+	RenameTopic(ctx context.Context, topicName string, newTopicName string) error
+	DeleteTopic(ctx context.Context, topicName string, force bool) error
+	DescribeTopic(ctx context.Context, topicName string) (*TopicDescription, error)
+	ListConsumerGroups(ctx context.Context, topicName string) ([]ConsumerGroup, error)
 }
 
+// MaxBatchSize is the largest number of messages published or fetched with a single request
+const MaxBatchSize = 100
+
+// MaxKeyLength and MaxContentTypeLength are the longest key and content type, in characters, a message is stored with
+const (
+	MaxKeyLength         = 255
+	MaxContentTypeLength = 100
+)
+
 // NewTopic is the factory function for the TopicService type
-func NewTopic(log *logrus.Logger, db storage.DatabaseIF, queue queue.ImqQueueIF) TopicServicesIF {
+func NewTopic(log *logrus.Logger, db storage.DatabaseIF, queue queue.ImqQueueIF, opts TopicOptions) TopicServicesIF {
 	return &TopicService{
-		log:   log,
-		db:    db,
-		queue: queue,
+		log:      log,
+		db:       db,
+		queue:    queue,
+		opts:     opts,
+		watchers: map[patternWatch]*patternWatcher{},
 	}
 }
 
@@ -47,106 +100,135 @@ func (t *TopicService) GetTopics(ctx context.Context, id int) (*[]string, error)
 	return topics, nil
 }
 
-// RegisterPublisherToTopic register publisher to topic
+// RegisterPublisherToTopic connects the publisher to the topic, alongside the topics it is already connected to
 func (t *TopicService) RegisterPublisherToTopic(ctx context.Context, publisherID int, topicName string) error {
-	topicID, notFound, err := t.db.GetTopicIDFromPublisher(ctx, publisherID)
+	topics, err := t.db.GetPublishedTopics(ctx, publisherID)
 	if err != nil {
-		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to get topicId from Publisher: %v", err)
+		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to get published topics: %v", err)
 		return err
 	}
 
-	if topicID != "" {
-		err := errors.New("cannot register more than one topic at a time")
-		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: already registered to topics: %v", err)
-		return err
+	for _, topic := range topics {
+		if topicName == topic {
+			err := ErrAlreadyRegistered
+			t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: found connected topic: %v", err)
+			return err
+		}
 	}
 
-	topicID, err = t.db.GetTopicIDFromTopic(ctx, topicName)
+	topicID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
 	if err != nil {
 		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to get topicId from Topic: %v", err)
 		return err
 	}
 
 	if topicID == "" {
-		err := errors.New("topic not found")
+		err := ErrTopicNotFound
 		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to get topicId: %v", err)
-		return errors.New("topic not found")
+		return err
 	}
 
-	if notFound {
-		err = t.db.InsertPublisher(ctx, publisherID, topicID)
-		if err != nil {
-			t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to insert publisher to topic: %v", err)
-			return err
-		}
-	} else {
-		err = t.db.UpdateTopicIDIntoPublisher(ctx, publisherID, topicID)
-		if err != nil {
-			t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to update topicId into Publisher: %v", err)
-			return err
-		}
+	err = t.db.InsertPublisherIDIntoPublisher(ctx, publisherID)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to save publisherId: %v", err)
+		return err
+	}
+
+	err = t.db.InsertIntoPublisherTopicMap(ctx, publisherID, topicID)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to save mapped topic for publisher: %v", err)
+		return err
 	}
 
 	return nil
 }
 
-// DeregisterPublisherFromTopic  deregister publisher from topic
-func (t *TopicService) DeregisterPublisherFromTopic(ctx context.Context, publisherID int) error {
-	_, notFound, err := t.db.GetTopicIDFromPublisher(ctx, publisherID)
+// DeregisterPublisherFromTopic disconnects the publisher from the topic, leaving its other topics connected
+func (t *TopicService) DeregisterPublisherFromTopic(ctx context.Context, publisherID int, topicName string) error {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	connected, err := t.isPublishing(ctx, publisherID, topicName)
 	if err != nil {
-		t.log.WithField("publisherId", publisherID).Errorf("RegisterPublisherToTopic: failed to get topicId from Publisher: %v", err)
+		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: failed to get published topics: %v", err)
 		return err
 	}
 
-	if notFound {
-		err := errors.New("you are not registered with any topic")
-		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: record not found in Publisher: %v", err)
+	if !connected {
+		err := ErrNotRegistered
+		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: connected topic not found: %v", err)
 		return err
 	}
 
-	err = t.db.RemoveTopicIDFromPublisher(ctx, publisherID)
+	err = t.db.RemoveTopicIDFromPublisherTopicMap(ctx, publisherID, topicID)
 	if err != nil {
-		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: failed to insert publisher to topic: %v", err)
+		t.log.WithField("publisherId", publisherID).Errorf("DeregisterPublisherFromTopic: failed to remove publisher mapping to topic: %v", err)
 		return err
 	}
 
 	return nil
 }
 
-// AddMessageToTopic publish the messaget to given topic
-func (t *TopicService) AddMessageToTopic(ctx context.Context, publisherID int, message Message) error {
-	topicID, notFound, err := t.db.GetTopicIDFromPublisher(ctx, publisherID)
+// AddMessageToTopic publish the messaget to given topic, the only topic the publisher is connected to when topicName is empty
+func (t *TopicService) AddMessageToTopic(ctx context.Context, publisherID int, topicName string, message Message) error {
+	if err := validateMessage(message); err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to validate message: %v", err)
+		return err
+	}
+
+	topicID, topicName, err := t.publisherTopic(ctx, publisherID, topicName)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to get topic to publish to: %v", err)
+		return err
+	}
+
+	ttl, err := t.getTopicTTL(ctx, topicID)
 	if err != nil {
-		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to get topicId from publisher: %v", err)
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to get topic ttl: %v", err)
 		return err
 	}
 
-	if notFound {
-		err := errors.New("you are not register to any topic")
-		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to publish, not registered to any topic: %v", err)
+	message.CretedAt = time.Now().UTC()
+	message.ExpiresAt, err = ttl.expiresAt(message.CretedAt, message.TTL)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to validate ttl: %v", err)
 		return err
 	}
 
 	sendMessageRequest := queue.SendMessageRequest{
 		TopicID: topicID,
 		Message: queue.Message{
-			MessageID: message.MessageID,
-			Data:      message.Data,
-			CretedAt:  message.CretedAt,
-			ExpiresAt: message.ExpiresAt,
+			MessageID:   message.MessageID,
+			Key:         message.Key,
+			ContentType: message.ContentType,
+			Headers:     message.Headers,
+			Data:        message.Data,
+			CretedAt:    message.CretedAt,
+			ExpiresAt:   message.ExpiresAt,
 		},
 	}
 
+	if err := t.addPatternSubscribers(ctx, topicID, topicName); err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to add pattern subscribers: %v", err)
+		return err
+	}
+
 	if err := t.queue.SendMessage(ctx, sendMessageRequest); err != nil {
 		t.log.WithField("publisherId", publisherID).Errorf("AddMessageToTopic: failed to send message to queue: %v", err)
 		return err
 	}
 
 	msg := storage.Message{
-		MessageID: message.MessageID,
-		Data:      message.Data,
-		CretedAt:  message.CretedAt,
-		ExpiresAt: message.ExpiresAt,
+		MessageID:   message.MessageID,
+		Key:         message.Key,
+		ContentType: message.ContentType,
+		Headers:     message.Headers,
+		Data:        message.Data,
+		CretedAt:    message.CretedAt,
+		ExpiresAt:   message.ExpiresAt,
 	}
 
 	err = t.db.InsertMessageIntoMessage(ctx, publisherID, topicID, msg)
@@ -158,8 +240,112 @@ func (t *TopicService) AddMessageToTopic(ctx context.Context, publisherID int, m
 	return nil
 }
 
-// RegisterSubscriberToTopic add subscriber to the given topic
-func (t *TopicService) RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string) error {
+// AddMessagesToTopic publishes a batch of messages to the given topic, picked as for AddMessageToTopic, the error at an
+// index holds the result of the message at the same index and the returned error is set when the batch as a whole failed
+func (t *TopicService) AddMessagesToTopic(ctx context.Context, publisherID int, topicName string, messages []Message) ([]error, error) {
+	if len(messages) == 0 || len(messages) > MaxBatchSize {
+		err := ErrInvalidBatchSize
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to validate batch: %v", err)
+		return nil, err
+	}
+
+	topicID, topicName, err := t.publisherTopic(ctx, publisherID, topicName)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to get topic to publish to: %v", err)
+		return nil, err
+	}
+
+	ttl, err := t.getTopicTTL(ctx, topicID)
+	if err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to get topic ttl: %v", err)
+		return nil, err
+	}
+
+	errs := make([]error, len(messages))
+	now := time.Now().UTC()
+
+	// sent holds the index in messages of every message handed to the queue
+	sent := []int{}
+	queueMessages := []queue.Message{}
+	for i, message := range messages {
+		if errs[i] = validateMessage(message); errs[i] != nil {
+			t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to validate message: %v", errs[i])
+			continue
+		}
+
+		message.CretedAt = now
+		message.ExpiresAt, errs[i] = ttl.expiresAt(now, message.TTL)
+		if errs[i] != nil {
+			t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to validate ttl: %v", errs[i])
+			continue
+		}
+
+		sent = append(sent, i)
+		queueMessages = append(queueMessages, queue.Message{
+			MessageID:   message.MessageID,
+			Key:         message.Key,
+			ContentType: message.ContentType,
+			Headers:     message.Headers,
+			Data:        message.Data,
+			CretedAt:    message.CretedAt,
+			ExpiresAt:   message.ExpiresAt,
+		})
+	}
+
+	if len(queueMessages) == 0 {
+		return errs, nil
+	}
+
+	if err := t.addPatternSubscribers(ctx, topicID, topicName); err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to add pattern subscribers: %v", err)
+		return nil, err
+	}
+
+	stored := []storage.Message{}
+	for _, message := range queueMessages {
+		stored = append(stored, storage.Message{
+			MessageID:   message.MessageID,
+			Key:         message.Key,
+			ContentType: message.ContentType,
+			Headers:     message.Headers,
+			Data:        message.Data,
+			CretedAt:    message.CretedAt,
+			ExpiresAt:   message.ExpiresAt,
+		})
+	}
+
+	// the messages are stored before they are queued, so none of them is delivered when the batch cannot be stored
+	// and the queue never holds a message that is missing from the store
+	if err := t.db.InsertMessagesIntoMessage(ctx, publisherID, topicID, stored); err != nil {
+		t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to store messages: %v", err)
+		return nil, err
+	}
+
+	for j, err := range t.queue.SendMessages(ctx, topicID, queueMessages) {
+		if err != nil {
+			errs[sent[j]] = err
+			t.log.WithField("publisherId", publisherID).Errorf("AddMessagesToTopic: failed to send message to queue: %v", err)
+		}
+	}
+
+	return errs, nil
+}
+
+// RegisterSubscriberToTopic add subscriber to the given topic, or to every topic matched by topicName
+// when it is a subscription pattern. Only the messages accepted by the filter of the subscription are delivered
+// to the subscriber when one is given, and the subscriber shares the messages of the topic with the other members
+// of the consumer group when one is given. Filters and consumer groups are not supported on subscription patterns
+// and a subscription to a consumer group cannot be filtered
+func (t *TopicService) RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string, subscription Subscription) error {
+	if err := validateSubscription(topicName, subscription); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to validate subscription: %v", err)
+		return err
+	}
+
+	if isPattern(topicName) {
+		return t.registerSubscriberToPattern(ctx, subscriberID, topicName)
+	}
+
 	topics, err := t.db.GetSubscribedTopics(ctx, subscriberID)
 	if err != nil {
 		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to get subscribed topics: %v", err)
@@ -168,7 +354,7 @@ func (t *TopicService) RegisterSubscriberToTopic(ctx context.Context, subscriber
 
 	for _, topic := range topics {
 		if topicName == topic {
-			err := errors.New("you are already subscribed to this topic")
+			err := ErrAlreadySubscribed
 			t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: found subscribed topic: %v", err)
 			return err
 		}
@@ -186,17 +372,30 @@ func (t *TopicService) RegisterSubscriberToTopic(ctx context.Context, subscriber
 		return err
 	}
 
-	err = t.db.InsertIntoSubscriberTopicMap(ctx, subscriberID, topicID)
+	err = t.db.InsertIntoSubscriberTopicMap(ctx, subscriberID, topicID, storage.Subscription{Filter: subscription.Filter, GroupName: subscription.GroupName})
 	if err != nil {
 		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to save mapped topic for subscriber: %v", err)
 		return err
 	}
 
+	if subscription.GroupName != "" {
+		t.queue.JoinGroup(topicID, subscriberID, subscription.GroupName)
+		return nil
+	}
+
+	t.queue.AddSubscriber(topicID, subscriberID)
+
 	return nil
 }
 
-// DeregisterSubscriberFromTopic remove subscriber from topic
+// DeregisterSubscriberFromTopic remove subscriber from topic, or from the subscription pattern when topicName is one.
+// The subscriber keeps reading a topic still matched by another of its subscriptions. A member of a consumer group
+// leaves the group, which is dropped along with its offset once its last member leaves
 func (t *TopicService) DeregisterSubscriberFromTopic(ctx context.Context, subscriberID int, topicName string) error {
+	if isPattern(topicName) {
+		return t.deregisterSubscriberFromPattern(ctx, subscriberID, topicName)
+	}
+
 	topicID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
 	if err != nil {
 		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get topicId from topic: %v", err)
@@ -204,21 +403,45 @@ func (t *TopicService) DeregisterSubscriberFromTopic(ctx context.Context, subscr
 	}
 
 	if topicID == "" {
-		err := errors.New("topic not found")
+		err := ErrTopicNotFound
 		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: no record found for the given topic name: %v", err)
 		return err
 	}
 
+	subscription, err := t.db.GetSubscription(ctx, subscriberID, topicID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get subscription: %v", err)
+		return err
+	}
+
 	err = t.db.RemoveTopicIDFromSubscriberTopicMap(ctx, subscriberID, topicID)
 	if err != nil {
 		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to remove subscriber mapping to topic: %v", err)
 		return err
 	}
 
+	if subscription.GroupName != "" {
+		if err := t.leaveGroup(ctx, subscriberID, topicID, subscription.GroupName); err != nil {
+			t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to leave consumer group: %v", err)
+			return err
+		}
+	}
+
+	subscribed, err := t.isSubscribed(ctx, subscriberID, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get subscribed topics: %v", err)
+		return err
+	}
+
+	if !subscribed {
+		t.queue.RemoveSubscriber(topicID, subscriberID)
+		t.forgetPatternWatchers(subscriberID, topicID)
+	}
+
 	return nil
 }
 
-// GetRegisteredTopic fetches all the registered topics
+// GetRegisteredTopic fetches all the registered topics followed by the subscription patterns
 func (t *TopicService) GetRegisteredTopic(ctx context.Context, subscriberID int) (*[]string, error) {
 	topics, err := t.db.GetSubscribedTopics(ctx, subscriberID)
 	if err != nil {
@@ -226,6 +449,14 @@ func (t *TopicService) GetRegisteredTopic(ctx context.Context, subscriberID int)
 		return nil, err
 	}
 
+	patterns, err := t.db.GetSubscribedPatterns(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetRegisteredTopic: failed to get subscription patterns: %v", err)
+		return nil, err
+	}
+
+	topics = append(topics, patterns...)
+
 	return &topics, nil
 }
 
@@ -237,18 +468,1075 @@ func (t *TopicService) GetMessage(ctx context.Context, subscriberID int, topicNa
 		return nil, err
 	}
 
-	msg, err := t.queue.RetrieveMessage(ctx, topicID)
+	subscribed, err := t.isSubscribed(ctx, subscriberID, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessage: failed to get subscribed topics: %v", err)
+		return nil, err
+	}
+
+	if !subscribed {
+		err := ErrNotSubscribed
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessage: subscribed topic not found: %v", err)
+		return nil, err
+	}
+
+	if err := t.applySubscription(ctx, subscriberID, topicID); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessage: failed to apply subscription: %v", err)
+		return nil, err
+	}
+
+	msg, err := t.queue.RetrieveMessage(ctx, topicID, subscriberID)
 	if err != nil {
 		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessage: failed to retrieve message from queue: %v", err)
 		return nil, err
 	}
 
 	message := Message{
-		MessageID: msg.MessageID,
-		Data:      msg.Data,
-		CretedAt:  msg.CretedAt,
-		ExpiresAt: msg.ExpiresAt,
+		MessageID:     msg.MessageID,
+		TopicName:     topicName,
+		Key:           msg.Key,
+		ContentType:   msg.ContentType,
+		Headers:       msg.Headers,
+		Data:          msg.Data,
+		CretedAt:      msg.CretedAt,
+		ExpiresAt:     msg.ExpiresAt,
+		DeliveryCount: msg.DeliveryCount,
 	}
 
 	return &message, nil
 }
+
+// GetMessages fetches up to maxCount messages of the topic for the subscriber, stopping before their data grows
+// over maxBytes when it is set. A maxCount that is not set or over MaxBatchSize fetches MaxBatchSize messages
+func (t *TopicService) GetMessages(ctx context.Context, subscriberID int, topicName string, maxCount int, maxBytes int) ([]Message, error) {
+	if maxCount <= 0 || maxCount > MaxBatchSize {
+		maxCount = MaxBatchSize
+	}
+
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessages: failed to get topicid from topic: %v", err)
+		return nil, err
+	}
+
+	subscribed, err := t.isSubscribed(ctx, subscriberID, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessages: failed to get subscribed topics: %v", err)
+		return nil, err
+	}
+
+	if !subscribed {
+		err := ErrNotSubscribed
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessages: subscribed topic not found: %v", err)
+		return nil, err
+	}
+
+	if err := t.applySubscription(ctx, subscriberID, topicID); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessages: failed to apply subscription: %v", err)
+		return nil, err
+	}
+
+	msgs, err := t.queue.RetrieveMessages(ctx, topicID, subscriberID, maxCount, maxBytes)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("GetMessages: failed to retrieve messages from queue: %v", err)
+		return nil, err
+	}
+
+	messages := []Message{}
+	for _, msg := range msgs {
+		messages = append(messages, Message{
+			MessageID:     msg.MessageID,
+			TopicName:     topicName,
+			Key:           msg.Key,
+			ContentType:   msg.ContentType,
+			Headers:       msg.Headers,
+			Data:          msg.Data,
+			CretedAt:      msg.CretedAt,
+			ExpiresAt:     msg.ExpiresAt,
+			DeliveryCount: msg.DeliveryCount,
+		})
+	}
+
+	return messages, nil
+}
+
+// WatchTopic registers fn to receive every new message published to the given topic, or to every topic matched by
+// topicName when it is a subscription pattern including the topics created later
+func (t *TopicService) WatchTopic(ctx context.Context, subscriberID int, topicName string, fn func(Message) error) error {
+	if isPattern(topicName) {
+		return t.watchPattern(ctx, subscriberID, topicName, fn)
+	}
+
+	subscribed, err := t.isSubscribed(ctx, subscriberID, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to get subscribed topics: %v", err)
+		return err
+	}
+
+	if !subscribed {
+		err := ErrNotSubscribed
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: subscribed topic not found: %v", err)
+		return err
+	}
+
+	topicID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if err := t.applySubscription(ctx, subscriberID, topicID); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to apply subscription: %v", err)
+		return err
+	}
+
+	t.queue.Watch(topicID, subscriberID, topicWatcher(topicName, fn))
+
+	return nil
+}
+
+// UnwatchTopic stops delivering new messages of the given topic, or of the topics matched by the subscription
+// pattern, to the subscriber
+func (t *TopicService) UnwatchTopic(ctx context.Context, subscriberID int, topicName string) error {
+	if isPattern(topicName) {
+		t.unwatchPattern(subscriberID, topicName)
+		return nil
+	}
+
+	topicID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("UnwatchTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	t.queue.Unwatch(topicID, subscriberID)
+
+	return nil
+}
+
+// AckMessage confirms that the subscriber has processed the message so it is not delivered again
+func (t *TopicService) AckMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("AckMessage: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if err := t.queue.AckMessage(topicID, subscriberID, messageID); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("AckMessage: failed to acknowledge message: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// NackMessage hands the message back to the queue to be delivered to the subscriber again
+func (t *TopicService) NackMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("NackMessage: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if err := t.queue.NackMessage(topicID, subscriberID, messageID); err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("NackMessage: failed to reject message: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ListDeadMessages returns the messages held in the dead letter queue of the topic
+func (t *TopicService) ListDeadMessages(ctx context.Context, topicName string) ([]DeadMessage, error) {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("ListDeadMessages: failed to get topicId from topic: %v", err)
+		return nil, err
+	}
+
+	messages := []DeadMessage{}
+	for _, msg := range t.queue.ListDeadMessages(topicID) {
+		messages = append(messages, DeadMessage{
+			Message: Message{
+				MessageID:   msg.MessageID,
+				Key:         msg.Key,
+				ContentType: msg.ContentType,
+				Headers:     msg.Headers,
+				Data:        msg.Data,
+				CretedAt:    msg.CretedAt,
+				ExpiresAt:   msg.ExpiresAt,
+			},
+			Reason: msg.Reason,
+			DeadAt: msg.DeadAt,
+		})
+	}
+
+	return messages, nil
+}
+
+// PurgeDeadMessages drops the given message, or all of them when messageID is empty, from the dead letter queue of the topic
+func (t *TopicService) PurgeDeadMessages(ctx context.Context, topicName string, messageID string) (int, error) {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("PurgeDeadMessages: failed to get topicId from topic: %v", err)
+		return 0, err
+	}
+
+	return t.queue.PurgeDeadMessages(topicID, messageID), nil
+}
+
+// ReplayDeadMessages publishes the given message, or all of them when messageID is empty, from the dead letter queue back to the topic
+func (t *TopicService) ReplayDeadMessages(ctx context.Context, topicName string, messageID string) (int, error) {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("ReplayDeadMessages: failed to get topicId from topic: %v", err)
+		return 0, err
+	}
+
+	return t.queue.ReplayDeadMessages(topicID, messageID), nil
+}
+
+// CreateTopic creates a new topic with the given name split into the given number of partitions, a single one when
+// zero. The ttl the topic does not set is taken from the options and the limits it does not set from the queue
+func (t *TopicService) CreateTopic(ctx context.Context, topicID string, topicName string, ttl TopicTTL, partitions int, limits TopicLimits) error {
+	if !validTopicName(topicName) {
+		err := ErrInvalidTopicName
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to validate topic name: %v", err)
+		return err
+	}
+
+	if !validPartitions(partitions) {
+		err := ErrInvalidPartitions
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to validate partitions: %v", err)
+		return err
+	}
+
+	if partitions == 0 {
+		partitions = 1
+	}
+
+	if err := t.validateTopicTTL(ttl); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to validate ttl: %v", err)
+		return err
+	}
+
+	if err := validateTopicLimits(limits); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to validate limits: %v", err)
+		return err
+	}
+
+	existingID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if existingID != "" {
+		err := ErrTopicExists
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to create topic: %v", err)
+		return err
+	}
+
+	// the ttl and limits are stored with the topic, so a topic is never left without them
+	if err := t.db.InsertTopic(ctx, topicID, topicName, partitions, toStorageTTL(ttl), toStorageLimits(limits)); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("CreateTopic: failed to insert topic: %v", err)
+		return err
+	}
+
+	t.queue.SetPartitions(topicID, partitions)
+
+	if limits != (TopicLimits{}) {
+		t.queue.SetLimits(topicID, toQueueLimits(limits))
+	}
+
+	return nil
+}
+
+// UpdateTopicTTL sets the default and maximum time to live of the messages published to the topic from now on
+func (t *TopicService) UpdateTopicTTL(ctx context.Context, topicName string, ttl TopicTTL) error {
+	if err := t.validateTopicTTL(ttl); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicTTL: failed to validate ttl: %v", err)
+		return err
+	}
+
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicTTL: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if err := t.db.UpdateTopicTTL(ctx, topicID, toStorageTTL(ttl)); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicTTL: failed to store ttl: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// UpdateTopicLimits sets how many messages and bytes the topic holds at most and what happens to a message published
+// to it once it is full, the limits left at zero or empty fall back to the ones of the server
+func (t *TopicService) UpdateTopicLimits(ctx context.Context, topicName string, limits TopicLimits) error {
+	if err := validateTopicLimits(limits); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicLimits: failed to validate limits: %v", err)
+		return err
+	}
+
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicLimits: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if err := t.db.UpdateTopicLimits(ctx, topicID, toStorageLimits(limits)); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("UpdateTopicLimits: failed to store limits: %v", err)
+		return err
+	}
+
+	t.queue.SetLimits(topicID, toQueueLimits(limits))
+
+	return nil
+}
+
+// RenameTopic changes the name of the topic, publishers and subscribers stay registered to it. The subscribers of a
+// pattern that does not match the new name stop reading the topic unless another of their subscriptions matches it
+func (t *TopicService) RenameTopic(ctx context.Context, topicName string, newTopicName string) error {
+	if !validTopicName(newTopicName) {
+		err := ErrInvalidTopicName
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to validate topic name: %v", err)
+		return err
+	}
+
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	existingID, err := t.db.GetTopicIDFromTopic(ctx, newTopicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	if existingID != "" {
+		err := ErrTopicExists
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to rename topic: %v", err)
+		return err
+	}
+
+	subscribers, err := t.patternSubscribers(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to get pattern subscribers: %v", err)
+		return err
+	}
+
+	if err := t.db.RenameTopic(ctx, topicID, newTopicName); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to rename topic: %v", err)
+		return err
+	}
+
+	for _, subscriberID := range subscribers {
+		subscribed, err := t.isSubscribed(ctx, subscriberID, newTopicName)
+		if err != nil {
+			t.log.WithField("topicName", topicName).Errorf("RenameTopic: failed to get subscribed topics: %v", err)
+			return err
+		}
+
+		if !subscribed {
+			t.queue.RemoveSubscriber(topicID, subscriberID)
+			t.forgetPatternWatchers(subscriberID, topicID)
+		}
+	}
+
+	return nil
+}
+
+// DeleteTopic removes the topic along with its subscriptions, a topic still holding messages is only removed when force is set
+func (t *TopicService) DeleteTopic(ctx context.Context, topicName string, force bool) error {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("DeleteTopic: failed to get topicId from topic: %v", err)
+		return err
+	}
+
+	stats := t.queue.DescribeTopic(topicID)
+	if !force && stats.Depth > 0 {
+		err := ErrTopicNotEmpty
+		t.log.WithField("topicName", topicName).Errorf("DeleteTopic: failed to delete topic: %v", err)
+		return err
+	}
+
+	if err := t.db.DeleteTopic(ctx, topicID); err != nil {
+		t.log.WithField("topicName", topicName).Errorf("DeleteTopic: failed to delete topic: %v", err)
+		return err
+	}
+
+	t.queue.RemoveTopic(topicID)
+	t.forgetTopicWatchers(topicID)
+
+	return nil
+}
+
+// DescribeTopic returns the number of partitions, messages, dead messages, publishers and subscribers of the topic
+func (t *TopicService) DescribeTopic(ctx context.Context, topicName string) (*TopicDescription, error) {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("DescribeTopic: failed to get topicId from topic: %v", err)
+		return nil, err
+	}
+
+	stats, err := t.db.GetTopicStats(ctx, topicID)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("DescribeTopic: failed to get topic stats: %v", err)
+		return nil, err
+	}
+
+	ttl, err := t.getTopicTTL(ctx, topicID)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("DescribeTopic: failed to get topic ttl: %v", err)
+		return nil, err
+	}
+
+	queueStats := t.queue.DescribeTopic(topicID)
+
+	return &TopicDescription{
+		Name:         topicName,
+		Partitions:   queueStats.Partitions,
+		Depth:        queueStats.Depth,
+		Bytes:        queueStats.Bytes,
+		DeadMessages: queueStats.DeadMessages,
+		Publishers:   stats.Publishers,
+		Subscribers:  stats.Subscribers,
+		TTL:          ttl,
+		Limits: TopicLimits{
+			MaxMessages: queueStats.MaxMessages,
+			MaxBytes:    queueStats.MaxBytes,
+			Overflow:    string(queueStats.Overflow),
+		},
+	}, nil
+}
+
+// ListConsumerGroups returns the consumer groups reading the topic with their members, lag and partitions ordered by name
+func (t *TopicService) ListConsumerGroups(ctx context.Context, topicName string) ([]ConsumerGroup, error) {
+	topicID, err := t.getTopicID(ctx, topicName)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("ListConsumerGroups: failed to get topicId from topic: %v", err)
+		return nil, err
+	}
+
+	members, err := t.db.GetConsumerGroups(ctx, topicID)
+	if err != nil {
+		t.log.WithField("topicName", topicName).Errorf("ListConsumerGroups: failed to get consumer groups: %v", err)
+		return nil, err
+	}
+
+	groups := []ConsumerGroup{}
+	for _, m := range members {
+		if n := len(groups); n > 0 && groups[n-1].Name == m.GroupName {
+			groups[n-1].Members = append(groups[n-1].Members, m.SubscriberID)
+			continue
+		}
+
+		stats := t.queue.DescribeGroup(topicID, m.GroupName)
+
+		partitions := make([]GroupPartition, len(stats.Partitions))
+		for i, p := range stats.Partitions {
+			partitions[i] = GroupPartition{
+				Partition: p.Partition,
+				Members:   p.Members,
+				Offset:    p.Offset,
+				Pending:   p.Pending,
+				Lag:       p.Lag,
+			}
+		}
+
+		groups = append(groups, ConsumerGroup{
+			Name:       m.GroupName,
+			Members:    []int{m.SubscriberID},
+			Pending:    stats.Pending,
+			Lag:        stats.Lag,
+			Partitions: partitions,
+		})
+	}
+
+	return groups, nil
+}
+
+// getTopicID returns the topicId of the topic, failing when the topic does not exist
+func (t *TopicService) getTopicID(ctx context.Context, topicName string) (string, error) {
+	topicID, err := t.db.GetTopicIDFromTopic(ctx, topicName)
+	if err != nil {
+		return "", err
+	}
+
+	if topicID == "" {
+		return "", ErrTopicNotFound
+	}
+
+	return topicID, nil
+}
+
+func (t *TopicService) isPublishing(ctx context.Context, publisherID int, topicName string) (bool, error) {
+	topics, err := t.db.GetPublishedTopics(ctx, publisherID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, topic := range topics {
+		if topicName == topic {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// publisherTopic returns the id and name of the topic the publisher publishes to, it has to be connected to the
+// topic. An empty topicName stands for the only topic the publisher is connected to
+func (t *TopicService) publisherTopic(ctx context.Context, publisherID int, topicName string) (string, string, error) {
+	topics, err := t.db.GetPublishedTopics(ctx, publisherID)
+	if err != nil {
+		return "", "", err
+	}
+
+	if topicName == "" {
+		switch len(topics) {
+		case 0:
+			return "", "", ErrNotConnected
+		case 1:
+			topicName = topics[0]
+		default:
+			return "", "", ErrTopicRequired
+		}
+	}
+
+	for _, topic := range topics {
+		if topicName == topic {
+			topicID, err := t.getTopicID(ctx, topicName)
+			return topicID, topicName, err
+		}
+	}
+
+	return "", "", ErrNotRegistered
+}
+
+// isSubscribed reports whether the subscriber is subscribed to the topic itself or to a pattern matching it
+func (t *TopicService) isSubscribed(ctx context.Context, subscriberID int, topicName string) (bool, error) {
+	topics, err := t.db.GetSubscribedTopics(ctx, subscriberID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, topic := range topics {
+		if topicName == topic {
+			return true, nil
+		}
+	}
+
+	patterns, err := t.db.GetSubscribedPatterns(ctx, subscriberID)
+	if err != nil {
+		return false, err
+	}
+
+	for _, pattern := range patterns {
+		if MatchTopic(pattern, topicName) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// applySubscription makes the queue deliver only the messages accepted by the filter of the subscription to the topic.
+// It is applied before every read so the filter is back in place once the server restarts, the consumer group of the
+// subscription is joined when subscribing instead and the queue restores its members when it is loaded
+func (t *TopicService) applySubscription(ctx context.Context, subscriberID int, topicID string) error {
+	subscription, err := t.db.GetSubscription(ctx, subscriberID, topicID)
+	if err != nil {
+		return err
+	}
+
+	if subscription.GroupName != "" {
+		return nil
+	}
+
+	if subscription.Filter == "" {
+		t.queue.SetFilter(topicID, subscriberID, nil)
+		return nil
+	}
+
+	filter, err := ParseFilter(subscription.Filter)
+	if err != nil {
+		return err
+	}
+
+	t.queue.SetFilter(topicID, subscriberID, func(msg queue.Message) bool {
+		return filter(msg.Headers)
+	})
+
+	return nil
+}
+
+// leaveGroup takes the subscriber out of the consumer group reading the topic, the group is dropped from the queue
+// once no subscriber is left in it
+func (t *TopicService) leaveGroup(ctx context.Context, subscriberID int, topicID string, groupName string) error {
+	t.queue.RemoveSubscriber(topicID, subscriberID)
+
+	members, err := t.db.GetConsumerGroups(ctx, topicID)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range members {
+		if m.GroupName == groupName {
+			return nil
+		}
+	}
+
+	t.queue.RemoveGroup(topicID, groupName)
+
+	return nil
+}
+
+// validateSubscription checks the filter and consumer group of a subscription to the topic or subscription pattern
+func validateSubscription(topicName string, subscription Subscription) error {
+	if isPattern(topicName) {
+		if subscription.Filter != "" {
+			return fmt.Errorf("%w: filters are not supported on subscription patterns", ErrInvalidFilter)
+		}
+		if subscription.GroupName != "" {
+			return fmt.Errorf("%w: consumer groups are not supported on subscription patterns", ErrInvalidGroupName)
+		}
+		return nil
+	}
+
+	if subscription.GroupName != "" {
+		if !validGroupName(subscription.GroupName) {
+			return ErrInvalidGroupName
+		}
+		if subscription.Filter != "" {
+			return fmt.Errorf("%w: filters are not supported on consumer groups", ErrInvalidFilter)
+		}
+	}
+
+	if subscription.Filter != "" {
+		if _, err := ParseFilter(subscription.Filter); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// registerSubscriberToPattern subscribes the subscriber to every topic matched by the pattern, the topics created
+// later are matched when a message is first published to them
+func (t *TopicService) registerSubscriberToPattern(ctx context.Context, subscriberID int, pattern string) error {
+	if !validPattern(pattern) {
+		err := ErrInvalidPattern
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to validate pattern: %v", err)
+		return err
+	}
+
+	patterns, err := t.db.GetSubscribedPatterns(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to get subscription patterns: %v", err)
+		return err
+	}
+
+	for _, p := range patterns {
+		if pattern == p {
+			err := ErrAlreadySubscribed
+			t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: found subscription pattern: %v", err)
+			return err
+		}
+	}
+
+	err = t.db.InsertSubscriberIDIntoSubscriber(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to save subscriberId: %v", err)
+		return err
+	}
+
+	err = t.db.InsertIntoSubscriberPatternMap(ctx, subscriberID, pattern)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to save subscription pattern: %v", err)
+		return err
+	}
+
+	topicIDs, err := t.matchingTopicIDs(ctx, subscriberID, pattern)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("RegisterSubscriberToTopic: failed to get matching topics: %v", err)
+		return err
+	}
+
+	for _, topicID := range topicIDs {
+		t.queue.AddSubscriber(topicID, subscriberID)
+	}
+
+	return nil
+}
+
+// deregisterSubscriberFromPattern removes the subscription pattern and stops the subscriber reading the topics
+// it matched that no other subscription of the subscriber matches
+func (t *TopicService) deregisterSubscriberFromPattern(ctx context.Context, subscriberID int, pattern string) error {
+	patterns, err := t.db.GetSubscribedPatterns(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get subscription patterns: %v", err)
+		return err
+	}
+
+	found := false
+	for _, p := range patterns {
+		if pattern == p {
+			found = true
+		}
+	}
+
+	if !found {
+		err := ErrNotSubscribed
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: subscription pattern not found: %v", err)
+		return err
+	}
+
+	err = t.db.RemovePatternFromSubscriberPatternMap(ctx, subscriberID, pattern)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to remove subscription pattern: %v", err)
+		return err
+	}
+
+	topics, err := t.db.FetchAllTopics(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get topics: %v", err)
+		return err
+	}
+
+	for _, topicName := range *topics {
+		if !MatchTopic(pattern, topicName) {
+			continue
+		}
+
+		subscribed, err := t.isSubscribed(ctx, subscriberID, topicName)
+		if err != nil {
+			t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get subscribed topics: %v", err)
+			return err
+		}
+
+		if subscribed {
+			continue
+		}
+
+		topicID, err := t.getTopicID(ctx, topicName)
+		if err != nil {
+			t.log.WithField("subscriberId", subscriberID).Errorf("DeregisterSubscriberFromTopic: failed to get topicId from topic: %v", err)
+			return err
+		}
+
+		t.queue.RemoveSubscriber(topicID, subscriberID)
+		t.forgetPatternWatchers(subscriberID, topicID)
+	}
+
+	t.mu.Lock()
+	delete(t.watchers, patternWatch{subscriberID: subscriberID, pattern: pattern})
+	t.mu.Unlock()
+
+	return nil
+}
+
+// matchingTopicIDs returns the id of every existing topic matched by the pattern
+func (t *TopicService) matchingTopicIDs(ctx context.Context, id int, pattern string) ([]string, error) {
+	topics, err := t.db.FetchAllTopics(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	topicIDs := []string{}
+	for _, topicName := range *topics {
+		if !MatchTopic(pattern, topicName) {
+			continue
+		}
+
+		topicID, err := t.getTopicID(ctx, topicName)
+		if err != nil {
+			return nil, err
+		}
+		topicIDs = append(topicIDs, topicID)
+	}
+
+	return topicIDs, nil
+}
+
+// patternSubscribers returns the subscribers with a subscription pattern matching the topic
+func (t *TopicService) patternSubscribers(ctx context.Context, topicName string) ([]int, error) {
+	patterns, err := t.db.GetSubscriptionPatterns(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	subscribers := []int{}
+	for _, p := range patterns {
+		if !seen[p.SubscriberID] && MatchTopic(p.Pattern, topicName) {
+			seen[p.SubscriberID] = true
+			subscribers = append(subscribers, p.SubscriberID)
+		}
+	}
+
+	return subscribers, nil
+}
+
+// addPatternSubscribers starts the subscribers with a pattern matching the topic reading it before a message is
+// published to it, and pushing it to the ones watching the pattern, so a topic created after the pattern was
+// subscribed to is covered as well
+func (t *TopicService) addPatternSubscribers(ctx context.Context, topicID string, topicName string) error {
+	patterns, err := t.db.GetSubscriptionPatterns(ctx)
+	if err != nil {
+		return err
+	}
+
+	added := map[int]bool{}
+	for _, p := range patterns {
+		if !MatchTopic(p.Pattern, topicName) {
+			continue
+		}
+
+		if !added[p.SubscriberID] {
+			added[p.SubscriberID] = true
+			t.queue.AddSubscriber(topicID, p.SubscriberID)
+		}
+
+		if fn := t.registerPatternWatcher(patternWatch{subscriberID: p.SubscriberID, pattern: p.Pattern}, topicID); fn != nil {
+			t.queue.Watch(topicID, p.SubscriberID, topicWatcher(topicName, fn))
+		}
+	}
+
+	return nil
+}
+
+// watchPattern pushes the messages of every topic matched by the pattern to fn, the topics created later are
+// registered once a message is first published to them
+func (t *TopicService) watchPattern(ctx context.Context, subscriberID int, pattern string, fn func(Message) error) error {
+	patterns, err := t.db.GetSubscribedPatterns(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to get subscription patterns: %v", err)
+		return err
+	}
+
+	subscribed := false
+	for _, p := range patterns {
+		if pattern == p {
+			subscribed = true
+		}
+	}
+
+	if !subscribed {
+		err := ErrNotSubscribed
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: subscription pattern not found: %v", err)
+		return err
+	}
+
+	topics, err := t.db.FetchAllTopics(ctx, subscriberID)
+	if err != nil {
+		t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to get topics: %v", err)
+		return err
+	}
+
+	key := patternWatch{subscriberID: subscriberID, pattern: pattern}
+
+	t.mu.Lock()
+	t.watchers[key] = &patternWatcher{fn: fn, topicIDs: map[string]bool{}}
+	t.mu.Unlock()
+
+	for _, topicName := range *topics {
+		if !MatchTopic(pattern, topicName) {
+			continue
+		}
+
+		topicID, err := t.getTopicID(ctx, topicName)
+		if err != nil {
+			t.log.WithField("subscriberId", subscriberID).Errorf("WatchTopic: failed to get topicId from topic: %v", err)
+			return err
+		}
+
+		if fn := t.registerPatternWatcher(key, topicID); fn != nil {
+			t.queue.Watch(topicID, subscriberID, topicWatcher(topicName, fn))
+		}
+	}
+
+	return nil
+}
+
+// unwatchPattern stops pushing the messages of the topics matched by the pattern to the subscriber
+func (t *TopicService) unwatchPattern(subscriberID int, pattern string) {
+	key := patternWatch{subscriberID: subscriberID, pattern: pattern}
+
+	t.mu.Lock()
+	w, ok := t.watchers[key]
+	delete(t.watchers, key)
+	t.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	for topicID := range w.topicIDs {
+		t.queue.Unwatch(topicID, subscriberID)
+	}
+}
+
+// registerPatternWatcher returns the function of the pattern watcher when it is not registered to the topic yet
+// and marks it as registered, nil is returned when the pattern is not watched
+func (t *TopicService) registerPatternWatcher(key patternWatch, topicID string) func(Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	w, ok := t.watchers[key]
+	if !ok || w.topicIDs[topicID] {
+		return nil
+	}
+	w.topicIDs[topicID] = true
+
+	return w.fn
+}
+
+// forgetPatternWatchers marks the pattern watchers of the subscriber as no longer registered to the topic
+func (t *TopicService) forgetPatternWatchers(subscriberID int, topicID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key, w := range t.watchers {
+		if key.subscriberID == subscriberID {
+			delete(w.topicIDs, topicID)
+		}
+	}
+}
+
+// forgetTopicWatchers marks every pattern watcher as no longer registered to the deleted topic
+func (t *TopicService) forgetTopicWatchers(topicID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, w := range t.watchers {
+		delete(w.topicIDs, topicID)
+	}
+}
+
+// topicWatcher converts the messages the queue pushes for the topic to fn
+func topicWatcher(topicName string, fn func(Message) error) func(queue.Message) error {
+	return func(msg queue.Message) error {
+		return fn(Message{
+			MessageID:     msg.MessageID,
+			TopicName:     topicName,
+			Key:           msg.Key,
+			ContentType:   msg.ContentType,
+			Headers:       msg.Headers,
+			Data:          msg.Data,
+			CretedAt:      msg.CretedAt,
+			ExpiresAt:     msg.ExpiresAt,
+			DeliveryCount: msg.DeliveryCount,
+		})
+	}
+}
+
+// getTopicTTL returns the time to live of the messages of the topic, falling back to the options for what the topic does not set
+func (t *TopicService) getTopicTTL(ctx context.Context, topicID string) (TopicTTL, error) {
+	stored, err := t.db.GetTopicTTL(ctx, topicID)
+	if err != nil {
+		return TopicTTL{}, err
+	}
+
+	ttl := TopicTTL{DefaultTTL: t.opts.DefaultTTL, MaxTTL: t.opts.MaxTTL}
+	if stored.DefaultTTL > 0 {
+		ttl.DefaultTTL = time.Duration(stored.DefaultTTL) * time.Second
+	}
+	if stored.MaxTTL > 0 {
+		ttl.MaxTTL = time.Duration(stored.MaxTTL) * time.Second
+	}
+
+	return ttl, nil
+}
+
+// validateTopicTTL checks that the default ttl of a topic fits its maximum ttl, or the one of the options when not set
+func (t *TopicService) validateTopicTTL(ttl TopicTTL) error {
+	maxTTL := ttl.MaxTTL
+	if maxTTL == 0 {
+		maxTTL = t.opts.MaxTTL
+	}
+
+	if ttl.DefaultTTL < 0 || ttl.MaxTTL < 0 || (maxTTL > 0 && ttl.DefaultTTL > maxTTL) {
+		return ErrInvalidTopicTTL
+	}
+
+	return nil
+}
+
+// validateMessage checks that the key and content type of a message fit the columns they are stored in
+func validateMessage(message Message) error {
+	if utf8.RuneCountInString(message.Key) > MaxKeyLength {
+		return ErrKeyTooLong
+	}
+
+	if utf8.RuneCountInString(message.ContentType) > MaxContentTypeLength {
+		return ErrContentTypeTooLong
+	}
+
+	return nil
+}
+
+// expiresAt returns when a message created at createdAt with the requested ttl expires, the default ttl is used when
+// none is requested and the zero time is returned for a message that never expires
+func (ttl TopicTTL) expiresAt(createdAt time.Time, requested time.Duration) (time.Time, error) {
+	if requested < 0 {
+		return time.Time{}, ErrInvalidTTL
+	}
+
+	if requested == 0 {
+		requested = ttl.DefaultTTL
+	}
+
+	if ttl.MaxTTL > 0 {
+		if requested > ttl.MaxTTL {
+			return time.Time{}, ErrTTLTooLong
+		}
+		if requested == 0 {
+			requested = ttl.MaxTTL
+		}
+	}
+
+	if requested == 0 {
+		return time.Time{}, nil
+	}
+
+	return createdAt.Add(requested), nil
+}
+
+// validateTopicLimits checks that the limits of a topic are not negative and that its overflow policy is one the queue applies
+func validateTopicLimits(limits TopicLimits) error {
+	if limits.MaxMessages < 0 || limits.MaxBytes < 0 || !queue.OverflowPolicy(limits.Overflow).Valid() {
+		return ErrInvalidLimits
+	}
+
+	return nil
+}
+
+func toStorageLimits(limits TopicLimits) storage.TopicLimits {
+	return storage.TopicLimits{
+		MaxMessages: limits.MaxMessages,
+		MaxBytes:    limits.MaxBytes,
+		Overflow:    limits.Overflow,
+	}
+}
+
+func toQueueLimits(limits TopicLimits) queue.Limits {
+	return queue.Limits{
+		MaxMessages: limits.MaxMessages,
+		MaxBytes:    limits.MaxBytes,
+		Overflow:    queue.OverflowPolicy(limits.Overflow),
+	}
+}
+
+func toStorageTTL(ttl TopicTTL) storage.TopicTTL {
+	return storage.TopicTTL{
+		DefaultTTL: int(ttl.DefaultTTL / time.Second),
+		MaxTTL:     int(ttl.MaxTTL / time.Second),
+	}
+}
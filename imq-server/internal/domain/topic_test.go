@@ -4,9 +4,12 @@ import (
 	"context"
 	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
 	"github.com/WinnersonKharsunai/GraduationProject/server/test"
 	"github.com/sirupsen/logrus"
@@ -21,7 +24,7 @@ func TestGetTopics_Fail(t *testing.T) {
 	mockQueue := &test.MockQueueIF{}
 	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, publisherID).Return(&[]string{}, expectedErr)
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 	_, err := topic.GetTopics(context.Background(), publisherID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
@@ -36,7 +39,7 @@ func TestGetTopics_Pass(t *testing.T) {
 	mockQueue := &test.MockQueueIF{}
 	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, publisherID).Return(topics, nil)
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 	resp, err := topic.GetTopics(context.Background(), publisherID)
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
@@ -47,16 +50,16 @@ func TestGetTopics_Pass(t *testing.T) {
 	}
 }
 
-func TestRegisterPublisherToTopic_GetTopicIDFromPublisher_Fail(t *testing.T) {
+func TestRegisterPublisherToTopic_GetPublishedTopics_Fail(t *testing.T) {
 	publisherID := 5000
 	topicName := "golang"
-	expectedErr := errors.New("failed to get topicId")
+	expectedErr := errors.New("failed to get published topics")
 
 	mockDb := &test.MockDatabaseIF{}
 	mockQueue := &test.MockQueueIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", false, expectedErr)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, expectedErr)
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -64,17 +67,16 @@ func TestRegisterPublisherToTopic_GetTopicIDFromPublisher_Fail(t *testing.T) {
 	}
 }
 
-func TestRegisterPublisherToTopic_GetTopicIDFromPublisher_AlreadyRegisteredFail(t *testing.T) {
+func TestRegisterPublisherToTopic_GetPublishedTopics_AlreadyRegisteredFail(t *testing.T) {
 	publisherID := 5000
 	topicName := "golang"
-	topicID := "12345"
-	expectedErr := errors.New("cannot register more than one topic at a time")
+	expectedErr := errors.New("you are already connected to this topic")
 
 	mockDb := &test.MockDatabaseIF{}
 	mockQueue := &test.MockQueueIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return(topicID, false, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"java", topicName}, nil)
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -88,12 +90,12 @@ func TestRegisterPublisherToTopic_GetTopicIDFromTopic_Fail(t *testing.T) {
 	expectedErr := errors.New("failed to get topicId")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", false, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -108,12 +110,12 @@ func TestRegisterPublisherToTopic_GetTopicIDFromTopic_TopicIdNotFoundFail(t *tes
 	expectedErr := errors.New("topic not found")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", false, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -121,21 +123,20 @@ func TestRegisterPublisherToTopic_GetTopicIDFromTopic_TopicIdNotFoundFail(t *tes
 	}
 }
 
-func TestRegisterPublisherToTopic_InsertPublisher_Fail(t *testing.T) {
+func TestRegisterPublisherToTopic_InsertPublisherIDIntoPublisher_Fail(t *testing.T) {
 	publisherID := 5000
-	notFound := true
 	topicName := "golang"
 	topicID := "12345"
 	expectedErr := errors.New("failed to insert publisher")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
-	mockDb.Given(storage.DatabaseIF.InsertPublisher).When(mock.Anything, publisherID, topicID).Return(expectedErr)
+	mockDb.Given(storage.DatabaseIF.InsertPublisherIDIntoPublisher).When(mock.Anything, publisherID).Return(expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -143,21 +144,21 @@ func TestRegisterPublisherToTopic_InsertPublisher_Fail(t *testing.T) {
 	}
 }
 
-func TestRegisterPublisherToTopic_UpdateTopicIDIntoPublisher_Fail(t *testing.T) {
+func TestRegisterPublisherToTopic_InsertIntoPublisherTopicMap_Fail(t *testing.T) {
 	publisherID := 5000
-	notFound := false
 	topicName := "golang"
 	topicID := "12345"
-	expectedErr := errors.New("failed to update publisher")
+	expectedErr := errors.New("failed to insert mapping")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
-	mockDb.Given(storage.DatabaseIF.UpdateTopicIDIntoPublisher).When(mock.Anything, publisherID, topicID).Return(expectedErr)
+	mockDb.Given(storage.DatabaseIF.InsertPublisherIDIntoPublisher).When(mock.Anything, publisherID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoPublisherTopicMap).When(mock.Anything, publisherID, topicID).Return(expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -167,18 +168,18 @@ func TestRegisterPublisherToTopic_UpdateTopicIDIntoPublisher_Fail(t *testing.T)
 
 func TestRegisterPublisherToTopic_Pass(t *testing.T) {
 	publisherID := 5000
-	notFound := false
 	topicName := "golang"
 	topicID := "12345"
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"java"}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
-	mockDb.Given(storage.DatabaseIF.UpdateTopicIDIntoPublisher).When(mock.Anything, publisherID, topicID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertPublisherIDIntoPublisher).When(mock.Anything, publisherID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoPublisherTopicMap).When(mock.Anything, publisherID, topicID).Return(nil)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.RegisterPublisherToTopic(context.Background(), publisherID, topicName)
 	if err != nil {
@@ -186,59 +187,82 @@ func TestRegisterPublisherToTopic_Pass(t *testing.T) {
 	}
 }
 
-func TestDeregisterPublisherFromTopic_GetTopicIDFromPublisher_Fail(t *testing.T) {
+func TestDeregisterPublisherFromTopic_TopicNotFoundFail(t *testing.T) {
+	publisherID := 5000
+	topicName := "golang"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID, topicName)
+	if !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+}
+
+func TestDeregisterPublisherFromTopic_GetPublishedTopics_Fail(t *testing.T) {
 	publisherID := 5000
-	notFound := false
+	topicName := "golang"
+	topicID := "12345"
 
-	expectedErr := errors.New("failed to update publisher")
+	expectedErr := errors.New("failed to get published topics")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, expectedErr)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID)
+	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
 }
 
-func TestDeregisterPublisherFromTopic_GetTopicIDFromPublisher_NotFoundFail(t *testing.T) {
+func TestDeregisterPublisherFromTopic_NotRegisteredFail(t *testing.T) {
 	publisherID := 5000
-	notFound := true
+	topicName := "golang"
+	topicID := "12345"
 
-	expectedErr := errors.New("you are not registered with any topic")
+	expectedErr := errors.New("you are not connected to this topic")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"java"}, nil)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID)
+	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
 }
 
-func TestDeregisterPublisherFromTopic_RemoveTopicIDFromPublisher_Fail(t *testing.T) {
+func TestDeregisterPublisherFromTopic_RemoveTopicIDFromPublisherTopicMap_Fail(t *testing.T) {
 	publisherID := 5000
-	notFound := false
+	topicName := "golang"
+	topicID := "12345"
 
 	expectedErr := errors.New("failed to remove topicID")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
-	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromPublisher).When(mock.Anything, publisherID).Return(expectedErr)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromPublisherTopicMap).When(mock.Anything, publisherID, topicID).Return(expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID)
+	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID, topicName)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
@@ -246,42 +270,306 @@ func TestDeregisterPublisherFromTopic_RemoveTopicIDFromPublisher_Fail(t *testing
 
 func TestDeregisterPublisherFromTopic_Pass(t *testing.T) {
 	publisherID := 5000
-	notFound := false
+	topicName := "golang"
+	topicID := "12345"
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, nil)
-	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromPublisher).When(mock.Anything, publisherID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"java", topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromPublisherTopicMap).When(mock.Anything, publisherID, topicID).Return(nil)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID)
+	err := topic.DeregisterPublisherFromTopic(context.Background(), publisherID, topicName)
 	if err != nil {
 		t.Fatalf("expected: nil \n\t got: %v", err)
 	}
 }
 
-func TestAddMessageToTopic_GetTopicIDFromPublisher_Fail(t *testing.T) {
+func TestAddMessageToTopic_GetPublishedTopics_Fail(t *testing.T) {
 	publisherID := 5000
-	notFound := false
 	msg := domain.Message{}
 
-	expectedErr := errors.New("failed to get topicID")
+	expectedErr := errors.New("failed to get published topics")
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetTopicIDFromPublisher).When(mock.Anything, publisherID).Return("", notFound, expectedErr)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{}, expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.AddMessageToTopic(context.Background(), publisherID, msg)
+	err := topic.AddMessageToTopic(context.Background(), publisherID, "", msg)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
 }
 
+func TestAddMessageToTopic_TopicSelectionFail(t *testing.T) {
+	publisherID := 5000
+
+	tests := []struct {
+		name      string
+		topics    []string
+		topicName string
+		expected  error
+	}{
+		{name: "not connected", topics: []string{}, topicName: "", expected: domain.ErrNotConnected},
+		{name: "several topics", topics: []string{"golang", "java"}, topicName: "", expected: domain.ErrTopicRequired},
+		{name: "other topic", topics: []string{"golang"}, topicName: "java", expected: domain.ErrNotRegistered},
+	}
+
+	for _, tc := range tests {
+		mockDb := &test.MockDatabaseIF{}
+		mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return(tc.topics, nil)
+
+		topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+		err := topic.AddMessageToTopic(context.Background(), publisherID, tc.topicName, domain.Message{Data: []byte("test data")})
+		if !errors.Is(err, tc.expected) {
+			t.Fatalf("%v: expected: %v \n\t got: %v", tc.name, tc.expected, err)
+		}
+	}
+}
+
+func TestAddMessagesToTopic_InvalidBatchSizeFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	_, err := topic.AddMessagesToTopic(context.Background(), 5000, "", []domain.Message{})
+	if !errors.Is(err, domain.ErrInvalidBatchSize) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidBatchSize, err)
+	}
+}
+
+func TestAddMessageToTopic_KeyTooLongFail(t *testing.T) {
+	message := domain.Message{MessageID: "message1", Key: strings.Repeat("k", domain.MaxKeyLength+1), Data: []byte("test data")}
+
+	// the message is rejected before the topic is looked up or the queue is reached
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.AddMessageToTopic(context.Background(), 5000, "", message)
+	if !errors.Is(err, domain.ErrKeyTooLong) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrKeyTooLong, err)
+	}
+}
+
+func TestAddMessagesToTopic_ContentTypeTooLongFail(t *testing.T) {
+	publisherID := 5000
+	topicID := "12345"
+	messages := []domain.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2", ContentType: strings.Repeat("c", domain.MaxContentTypeLength+1), Data: []byte("test data 2")},
+	}
+
+	expected := []error{nil, domain.ErrContentTypeTooLong}
+
+	sentMessages := func(msgs []queue.Message) bool {
+		return len(msgs) == 1 && msgs[0].MessageID == "message1"
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"golang"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "golang").Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessagesIntoMessage).When(mock.Anything, publisherID, topicID, mock.Anything).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SendMessages).When(mock.Anything, topicID, mock.MatchedBy(sentMessages)).Return([]error{nil})
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	errs, err := topic.AddMessagesToTopic(context.Background(), publisherID, "golang", messages)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, errs) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, errs)
+	}
+}
+
+func TestAddMessageToTopic_TTLTooLongFail(t *testing.T) {
+	publisherID := 5000
+	topicName := "golang"
+	topicID := "12345"
+	message := domain.Message{MessageID: "message1", Data: []byte("test data"), TTL: 2 * time.Hour}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{MaxTTL: 3600}, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.AddMessageToTopic(context.Background(), publisherID, "", message)
+	if !errors.Is(err, domain.ErrTTLTooLong) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTTLTooLong, err)
+	}
+}
+
+func TestAddMessageToTopic_DefaultTTLPass(t *testing.T) {
+	publisherID := 5000
+	topicName := "golang"
+	topicID := "12345"
+	message := domain.Message{MessageID: "message1", Data: []byte("test data")}
+
+	hasDefaultTTL := func(req queue.SendMessageRequest) bool {
+		return req.Message.ExpiresAt.Sub(req.Message.CretedAt) == 10*time.Minute
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessageIntoMessage).When(mock.Anything, publisherID, topicID, mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SendMessage).When(mock.Anything, mock.MatchedBy(hasDefaultTTL)).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{DefaultTTL: 10 * time.Minute, MaxTTL: time.Hour})
+
+	err := topic.AddMessageToTopic(context.Background(), publisherID, "", message)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestAddMessageToTopic_NamedTopicPass(t *testing.T) {
+	publisherID := 5000
+	topicName := "java"
+	topicID := "67890"
+	message := domain.Message{MessageID: "message1", Data: []byte("test data")}
+
+	sentToTopic := func(req queue.SendMessageRequest) bool {
+		return req.TopicID == topicID
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"golang", topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessageIntoMessage).When(mock.Anything, publisherID, topicID, mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SendMessage).When(mock.Anything, mock.MatchedBy(sentToTopic)).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.AddMessageToTopic(context.Background(), publisherID, topicName, message)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestAddMessageToTopic_PatternSubscribersPass(t *testing.T) {
+	publisherID := 5000
+	topicName := "orders.eu.created"
+	topicID := "12345"
+	message := domain.Message{MessageID: "message1", Data: []byte("test data")}
+
+	patterns := []storage.SubscriptionPattern{
+		{SubscriberID: 6000, Pattern: "orders.#"},
+		{SubscriberID: 6000, Pattern: "*.eu.*"},
+		{SubscriberID: 7000, Pattern: "orders.*"},
+		{SubscriberID: 8000, Pattern: "#.created"},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return(patterns, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessageIntoMessage).When(mock.Anything, publisherID, topicID, mock.Anything).Return(nil)
+
+	// the subscribers with a matching pattern start reading the topic before the message is sent
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When(topicID, 6000).Return().Once()
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When(topicID, 8000).Return().Once()
+	mockQueue.Given(queue.ImqQueueIF.SendMessage).When(mock.Anything, mock.Anything).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.AddMessageToTopic(context.Background(), publisherID, "", message)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestAddMessagesToTopic_Pass(t *testing.T) {
+	publisherID := 5000
+	topicID := "12345"
+	messages := []domain.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2"},
+		{MessageID: "message3", Data: []byte("test data 3"), TTL: 2 * time.Hour},
+	}
+
+	sendErrs := []error{nil, queue.ErrEmptyMessage}
+	expected := []error{nil, queue.ErrEmptyMessage, domain.ErrTTLTooLong}
+
+	sentMessages := func(msgs []queue.Message) bool {
+		return len(msgs) == 2 && msgs[0].MessageID == "message1" && msgs[1].MessageID == "message2"
+	}
+	// every message handed to the queue is stored first
+	storedMessages := func(msgs []storage.Message) bool {
+		return len(msgs) == 2 && msgs[0].MessageID == "message1" && msgs[0].ExpiresAt.Sub(msgs[0].CretedAt) == time.Hour &&
+			msgs[1].MessageID == "message2"
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"golang", "java"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "golang").Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessagesIntoMessage).When(mock.Anything, publisherID, topicID, mock.MatchedBy(storedMessages)).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SendMessages).When(mock.Anything, topicID, mock.MatchedBy(sentMessages)).Return(sendErrs)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{MaxTTL: time.Hour})
+
+	errs, err := topic.AddMessagesToTopic(context.Background(), publisherID, "golang", messages)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, errs) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, errs)
+	}
+}
+
+func TestAddMessagesToTopic_InsertMessagesIntoMessage_Fail(t *testing.T) {
+	publisherID := 5000
+	topicID := "12345"
+	messages := []domain.Message{{MessageID: "message1", Data: []byte("test data 1")}}
+
+	expectedErr := errors.New("failed to store messages")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"golang"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "golang").Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessagesIntoMessage).When(mock.Anything, publisherID, topicID, mock.Anything).Return(expectedErr)
+
+	// nothing is queued when the batch cannot be stored
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	_, err := topic.AddMessagesToTopic(context.Background(), publisherID, "golang", messages)
+	if err != expectedErr {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockQueue.AssertNotCalled(t, "SendMessages", mock.Anything, topicID, mock.Anything)
+}
+
 func TestRegisterSubscriberToTopic_GetSubscribedTopics_Fail(t *testing.T) {
 	subscriberID := 5000
 	topicName := "test"
@@ -293,9 +581,9 @@ func TestRegisterSubscriberToTopic_GetSubscribedTopics_Fail(t *testing.T) {
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName)
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{})
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
@@ -313,9 +601,9 @@ func TestRegisterSubscriberToTopic_GetTopicIDFromTopic_Fail(t *testing.T) {
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName)
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{})
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
@@ -335,9 +623,9 @@ func TestRegisterSubscriberToTopic_InsertSubscriberIDIntoSubscriber_Fail(t *test
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName)
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{})
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
@@ -354,18 +642,81 @@ func TestRegisterSubscriberToTopic_InsertIntoSubscriberTopicMap_Fail(t *testing.
 	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
 	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
-	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(expectedErr)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID, storage.Subscription{}).Return(expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName)
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{})
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
 }
 
+func TestRegisterSubscriberToTopic_InvalidFilterFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, topicName := range []string{"golang", "orders.*"} {
+		err := topic.RegisterSubscriberToTopic(context.Background(), 5000, topicName, domain.Subscription{Filter: "region = eu"})
+		if !errors.Is(err, domain.ErrInvalidFilter) {
+			t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidFilter, err)
+		}
+	}
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), 5000, "orders.*", domain.Subscription{Filter: "region = 'eu'"})
+	if !errors.Is(err, domain.ErrInvalidFilter) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidFilter, err)
+	}
+}
+
+func TestRegisterSubscriberToTopic_InvalidGroupFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, subscription := range []domain.Subscription{{GroupName: "billing team"}, {GroupName: "billing/eu"}} {
+		err := topic.RegisterSubscriberToTopic(context.Background(), 5000, "golang", subscription)
+		if !errors.Is(err, domain.ErrInvalidGroupName) {
+			t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidGroupName, err)
+		}
+	}
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), 5000, "orders.*", domain.Subscription{GroupName: "billing"})
+	if !errors.Is(err, domain.ErrInvalidGroupName) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidGroupName, err)
+	}
+
+	err = topic.RegisterSubscriberToTopic(context.Background(), 5000, "golang", domain.Subscription{Filter: "region = 'eu'", GroupName: "billing"})
+	if !errors.Is(err, domain.ErrInvalidFilter) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidFilter, err)
+	}
+}
+
+func TestRegisterSubscriberToTopic_GroupPass(t *testing.T) {
+	subscriberID := 5000
+	topicName := "test"
+	topicID := "12345"
+	subscription := storage.Subscription{GroupName: "billing"}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID, subscription).Return(nil)
+
+	// the subscriber reads from the offset of the group rather than its own
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.JoinGroup).When(topicID, subscriberID, "billing").Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{GroupName: "billing"})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	mockQueue.AssertNotCalled(t, "AddSubscriber", topicID, subscriberID)
+}
+
 func TestRegisterSubscriberToTopic_Pass(t *testing.T) {
 	subscriberID := 5000
 	topicName := "test"
@@ -375,13 +726,109 @@ func TestRegisterSubscriberToTopic_Pass(t *testing.T) {
 	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
 	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
-	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID, storage.Subscription{}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When(topicID, subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestRegisterSubscriberToTopic_FilterPass(t *testing.T) {
+	subscriberID := 5000
+	topicName := "test"
+	topicID := "12345"
+	filter := "region = 'eu' AND priority > 3"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberTopicMap).When(mock.Anything, subscriberID, topicID, storage.Subscription{Filter: filter}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When(topicID, subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName, domain.Subscription{Filter: filter})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	mockDb.AssertCalled(t, "InsertIntoSubscriberTopicMap", mock.Anything, subscriberID, topicID, storage.Subscription{Filter: filter})
+}
+func TestRegisterSubscriberToTopic_InvalidPatternFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, pattern := range []string{"orders.*eu", "orders..*", "#.", "orders.e#"} {
+		err := topic.RegisterSubscriberToTopic(context.Background(), 5000, pattern, domain.Subscription{})
+		if !errors.Is(err, domain.ErrInvalidPattern) {
+			t.Fatalf("%v: expected: %v \n\t got: %v", pattern, domain.ErrInvalidPattern, err)
+		}
+	}
+}
+
+func TestRegisterSubscriberToTopic_PatternAlreadySubscribedFail(t *testing.T) {
+	subscriberID := 5000
+	pattern := "orders.*"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{pattern}, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, pattern, domain.Subscription{})
+	if !errors.Is(err, domain.ErrAlreadySubscribed) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrAlreadySubscribed, err)
+	}
+}
+
+func TestRegisterSubscriberToTopic_InsertIntoSubscriberPatternMap_Fail(t *testing.T) {
+	subscriberID := 5000
+	pattern := "orders.*"
+
+	expectedErr := errors.New("failed to insert pattern")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberPatternMap).When(mock.Anything, subscriberID, pattern).Return(expectedErr)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, pattern, domain.Subscription{})
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestRegisterSubscriberToTopic_PatternPass(t *testing.T) {
+	subscriberID := 5000
+	pattern := "orders.#"
+	topics := []string{"golang", "orders.eu", "orders.eu.created"}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"billing.*"}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertSubscriberIDIntoSubscriber).When(mock.Anything, subscriberID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.InsertIntoSubscriberPatternMap).When(mock.Anything, subscriberID, pattern).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, subscriberID).Return(&topics, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "orders.eu").Return("111", nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "orders.eu.created").Return("222", nil)
 
+	// the subscriber starts reading the existing topics matched by the pattern only
 	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When("111", subscriberID).Return()
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When("222", subscriberID).Return()
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, topicName)
+	err := topic.RegisterSubscriberToTopic(context.Background(), subscriberID, pattern, domain.Subscription{})
 	if err != nil {
 		t.Fatalf("expected: nil \n\t got: %v", err)
 	}
@@ -398,7 +845,7 @@ func TestDeregisterSubscriberFromTopic_GetTopicIDFromTopic_Fail(t *testing.T) {
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -417,7 +864,7 @@ func TestDeregisterSubscriberFromTopic_GetTopicIDFromTopic_NotFoundFail(t *testi
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -434,11 +881,12 @@ func TestDeregisterSubscriberFromTopic_RemoveTopicIDFromSubscriberTopicMap_Fail(
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
 	if err.Error() != expectedErr.Error() {
@@ -453,11 +901,15 @@ func TestDeregisterSubscriberFromTopic_Pass(t *testing.T) {
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{}, nil)
 
 	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.RemoveSubscriber).When(topicID, subscriberID).Return()
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
 	if err != nil {
@@ -465,38 +917,135 @@ func TestDeregisterSubscriberFromTopic_Pass(t *testing.T) {
 	}
 }
 
-func TestGetRegisteredTopic_GetSubscribedTopics_Fail(t *testing.T) {
+func TestDeregisterSubscriberFromTopic_LastGroupMemberPass(t *testing.T) {
 	subscriberID := 5000
-
-	expectedErr := errors.New("failed to get subscribed topics")
+	topicName := "test"
+	topicID := "12345"
 
 	mockDb := &test.MockDatabaseIF{}
-	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, expectedErr)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{GroupName: "billing"}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, topicID).Return([]storage.ConsumerGroupMember{{GroupName: "audit", SubscriberID: 6000}}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{}, nil)
 
+	// no member is left in the group so it is dropped from the queue
 	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.RemoveSubscriber).When(topicID, subscriberID).Return()
+	mockQueue.Given(queue.ImqQueueIF.RemoveGroup).When(topicID, "billing").Return()
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
-	_, err := topic.GetRegisteredTopic(context.Background(), subscriberID)
-	if err.Error() != expectedErr.Error() {
-		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
-	}
-}
+	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
 
-func TestGetRegisteredTopic_GetSubscribedTopics_Pass(t *testing.T) {
+	mockQueue.AssertCalled(t, "RemoveGroup", topicID, "billing")
+}
+
+func TestDeregisterSubscriberFromTopic_MatchedByPatternPass(t *testing.T) {
 	subscriberID := 5000
+	topicName := "orders.eu"
+	topicID := "12345"
 
 	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveTopicIDFromSubscriberTopicMap).When(mock.Anything, subscriberID, topicID).Return(nil)
 	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"orders.*"}, nil)
+
+	// the subscriber keeps reading the topic so the queue is not told to remove it
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestDeregisterSubscriberFromTopic_PatternNotSubscribedFail(t *testing.T) {
+	subscriberID := 5000
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"orders.*"}, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, "orders.#")
+	if !errors.Is(err, domain.ErrNotSubscribed) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrNotSubscribed, err)
+	}
+}
+
+func TestDeregisterSubscriberFromTopic_PatternPass(t *testing.T) {
+	subscriberID := 5000
+	pattern := "orders.*"
+	topics := []string{"golang", "orders.eu", "orders.us"}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{pattern}, nil).Once()
+	mockDb.Given(storage.DatabaseIF.RemovePatternFromSubscriberPatternMap).When(mock.Anything, subscriberID, pattern).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, subscriberID).Return(&topics, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{"orders.us"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "orders.eu").Return("111", nil)
+
+	// orders.us is still subscribed to on its own so the subscriber keeps reading it
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.RemoveSubscriber).When("111", subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.DeregisterSubscriberFromTopic(context.Background(), subscriberID, pattern)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestGetRegisteredTopic_GetSubscribedTopics_Fail(t *testing.T) {
+	subscriberID := 5000
+
+	expectedErr := errors.New("failed to get subscribed topics")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{}, expectedErr)
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
 
 	_, err := topic.GetRegisteredTopic(context.Background(), subscriberID)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestGetRegisteredTopic_GetSubscribedTopics_Pass(t *testing.T) {
+	subscriberID := 5000
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{"golang"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"orders.#"}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+
+	expected := []string{"golang", "orders.#"}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	got, err := topic.GetRegisteredTopic(context.Background(), subscriberID)
 	if err != nil {
 		t.Fatalf("expected: nil \n\t got: %v", err)
 	}
+
+	if !reflect.DeepEqual(expected, *got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, *got)
+	}
 }
 
 func TestGetMessage(t *testing.T) {
@@ -510,10 +1059,1030 @@ func TestGetMessage(t *testing.T) {
 
 	mockQueue := &test.MockQueueIF{}
 
-	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue)
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	_, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestGetMessage_NotSubscribedFail(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	expectedErr := errors.New("you are not subscribed to this topic")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{"java"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"golang.*"}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	_, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestGetMessage_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	msg := &queue.Message{
+		MessageID: "message1",
+		Offset:    3,
+		Data:      []byte("test data"),
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetFilter).When(topicID, subscriberID, mock.Anything).Return()
+	mockQueue.Given(queue.ImqQueueIF.RetrieveMessage).When(mock.Anything, topicID, subscriberID).Return(msg, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	resp, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if resp.MessageID != msg.MessageID {
+		t.Fatalf("expected: %v \n\t got: %v", msg.MessageID, resp.MessageID)
+	}
+}
+
+func TestGetMessage_FilterPass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	msg := &queue.Message{
+		MessageID: "message1",
+		Headers:   map[string]string{"region": "eu"},
+		Data:      []byte("test data"),
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{Filter: "region = 'eu'"}, nil)
+
+	var filter func(queue.Message) bool
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetFilter).When(topicID, subscriberID, mock.Anything).Return().Run(func(args mock.Arguments) {
+		filter = args.Get(2).(func(queue.Message) bool)
+	})
+	mockQueue.Given(queue.ImqQueueIF.RetrieveMessage).When(mock.Anything, topicID, subscriberID).Return(msg, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	_, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if filter == nil || !filter(*msg) {
+		t.Fatalf("expected: %v \n\t got: %v", true, false)
+	}
+
+	if filter(queue.Message{Headers: map[string]string{"region": "us"}}) {
+		t.Fatalf("expected: %v \n\t got: %v", false, true)
+	}
+}
+
+func TestGetMessage_GroupPass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	msg := &queue.Message{MessageID: "message1", Data: []byte("test data")}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{GroupName: "billing"}, nil)
+
+	// the subscriber joined its group when subscribing, reading does not rebalance the group
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.RetrieveMessage).When(mock.Anything, topicID, subscriberID).Return(msg, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	got, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != msg.MessageID {
+		t.Fatalf("expected: %v \n\t got: %v", msg.MessageID, got.MessageID)
+	}
+
+	mockQueue.AssertNotCalled(t, "JoinGroup", topicID, subscriberID, "billing")
+}
+
+func TestGetMessage_GetSubscription_Fail(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	expectedErr := errors.New("failed to get filter")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, expectedErr)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
 
 	_, err := topic.GetMessage(context.Background(), subscriberID, topicName)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
 	}
 }
+
+func TestGetMessage_MatchedByPatternPass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "orders.eu.created"
+	topicID := "12345"
+
+	msg := &queue.Message{
+		MessageID: "message1",
+		Offset:    3,
+		Data:      []byte("test data"),
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{"golang"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"orders.*", "orders.#"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetFilter).When(topicID, subscriberID, mock.Anything).Return()
+	mockQueue.Given(queue.ImqQueueIF.RetrieveMessage).When(mock.Anything, topicID, subscriberID).Return(msg, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	resp, err := topic.GetMessage(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if resp.MessageID != msg.MessageID {
+		t.Fatalf("expected: %v \n\t got: %v", msg.MessageID, resp.MessageID)
+	}
+}
+
+func TestGetMessages_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	msgs := []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1"), DeliveryCount: 1},
+		{MessageID: "message2", Data: []byte("test data 2"), DeliveryCount: 1},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetFilter).When(topicID, subscriberID, mock.Anything).Return()
+	mockQueue.Given(queue.ImqQueueIF.RetrieveMessages).When(mock.Anything, topicID, subscriberID, domain.MaxBatchSize, 1024).Return(msgs, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	resp, err := topic.GetMessages(context.Background(), subscriberID, topicName, 0, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := []domain.Message{
+		{MessageID: "message1", TopicName: topicName, Data: []byte("test data 1"), DeliveryCount: 1},
+		{MessageID: "message2", TopicName: topicName, Data: []byte("test data 2"), DeliveryCount: 1},
+	}
+	if !reflect.DeepEqual(expected, resp) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, resp)
+	}
+}
+
+func TestWatchTopic_NotSubscribedFail(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+
+	expectedErr := errors.New("you are not subscribed to this topic")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{"java"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"golang.*"}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.WatchTopic(context.Background(), subscriberID, topicName, func(domain.Message) error { return nil })
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestWatchTopic_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, subscriberID).Return([]string{topicName}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscription).When(mock.Anything, subscriberID, topicID).Return(&storage.Subscription{}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetFilter).When(topicID, subscriberID, mock.Anything).Return()
+	mockQueue.Given(queue.ImqQueueIF.Watch).When(topicID, subscriberID, mock.Anything).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.WatchTopic(context.Background(), subscriberID, topicName, func(domain.Message) error { return nil })
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestWatchTopic_PatternNotSubscribedFail(t *testing.T) {
+	subscriberID := 6000
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{"orders.#"}, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.WatchTopic(context.Background(), subscriberID, "orders.*", func(domain.Message) error { return nil })
+	if !errors.Is(err, domain.ErrNotSubscribed) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrNotSubscribed, err)
+	}
+}
+
+func TestWatchTopic_PatternPass(t *testing.T) {
+	subscriberID := 6000
+	publisherID := 5000
+	pattern := "orders.*"
+	topics := []string{"golang", "orders.eu"}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{pattern}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, subscriberID).Return(&topics, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "orders.eu").Return("111", nil)
+	mockDb.Given(storage.DatabaseIF.GetPublishedTopics).When(mock.Anything, publisherID).Return([]string{"orders.us"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "orders.us").Return("222", nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, "222").Return(&storage.TopicTTL{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{{SubscriberID: subscriberID, Pattern: pattern}}, nil)
+	mockDb.Given(storage.DatabaseIF.InsertMessageIntoMessage).When(mock.Anything, publisherID, "222", mock.Anything).Return(nil)
+
+	pushed := []string{}
+	push := func(args mock.Arguments) {
+		fn := args.Get(2).(func(queue.Message) error)
+		fn(queue.Message{MessageID: "message1"})
+	}
+
+	// orders.us is created after the pattern is watched, it is registered once a message is published to it
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.Watch).When("111", subscriberID, mock.Anything).Return().Run(push).Once()
+	mockQueue.Given(queue.ImqQueueIF.AddSubscriber).When("222", subscriberID).Return()
+	mockQueue.Given(queue.ImqQueueIF.Watch).When("222", subscriberID, mock.Anything).Return().Run(push).Once()
+	mockQueue.Given(queue.ImqQueueIF.SendMessage).When(mock.Anything, mock.Anything).Return(nil)
+	mockQueue.Given(queue.ImqQueueIF.Unwatch).When("111", subscriberID).Return()
+	mockQueue.Given(queue.ImqQueueIF.Unwatch).When("222", subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.WatchTopic(context.Background(), subscriberID, pattern, func(msg domain.Message) error {
+		pushed = append(pushed, msg.TopicName)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := topic.AddMessageToTopic(context.Background(), publisherID, "", domain.Message{Data: []byte("test data")}); err != nil {
+			t.Fatalf("expected: nil \n\t got: %v", err)
+		}
+	}
+
+	expected := []string{"orders.eu", "orders.us"}
+	if !reflect.DeepEqual(expected, pushed) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, pushed)
+	}
+
+	if err := topic.UnwatchTopic(context.Background(), subscriberID, pattern); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	mockQueue.AssertCalled(t, "Unwatch", "111", subscriberID)
+	mockQueue.AssertCalled(t, "Unwatch", "222", subscriberID)
+}
+func TestUnwatchTopic_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.Unwatch).When(topicID, subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.UnwatchTopic(context.Background(), subscriberID, topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestAckMessage_Fail(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+	messageID := "message1"
+
+	expectedErr := errors.New("message is not awaiting acknowledgement")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AckMessage).When(topicID, subscriberID, messageID).Return(expectedErr)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.AckMessage(context.Background(), subscriberID, topicName, messageID)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestAckMessage_TopicNotFoundFail(t *testing.T) {
+	topicName := "golang"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.AckMessage(context.Background(), 6000, topicName, "message1")
+	if !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+}
+
+func TestNackMessage_TopicNotFoundFail(t *testing.T) {
+	topicName := "golang"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.NackMessage(context.Background(), 6000, topicName, "message1")
+	if !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+}
+
+func TestAckMessage_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+	messageID := "message1"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.AckMessage).When(topicID, subscriberID, messageID).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.AckMessage(context.Background(), subscriberID, topicName, messageID)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestNackMessage_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicName := "golang"
+	topicID := "12345"
+	messageID := "message1"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.NackMessage).When(topicID, subscriberID, messageID).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.NackMessage(context.Background(), subscriberID, topicName, messageID)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestListDeadMessages_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	dead := []queue.DeadMessage{
+		{
+			Message: queue.Message{MessageID: "message1"},
+			Reason:  queue.ReasonExpired,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.ListDeadMessages).When(topicID).Return(dead)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	resp, err := topic.ListDeadMessages(context.Background(), topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if len(resp) != 1 || resp[0].MessageID != "message1" || resp[0].Reason != queue.ReasonExpired {
+		t.Fatalf("expected: %v \n\t got: %v", dead, resp)
+	}
+}
+
+func TestDeadMessages_TopicNotFoundFail(t *testing.T) {
+	topicName := "golang"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	if _, err := topic.ListDeadMessages(context.Background(), topicName); !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+
+	if _, err := topic.PurgeDeadMessages(context.Background(), topicName, ""); !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+
+	if _, err := topic.ReplayDeadMessages(context.Background(), topicName, ""); !errors.Is(err, domain.ErrTopicNotFound) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrTopicNotFound, err)
+	}
+}
+
+func TestReplayDeadMessages_GetTopicIDFromTopic_Fail(t *testing.T) {
+	topicName := "golang"
+
+	expectedErr := errors.New("topic not found")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", expectedErr)
+
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	_, err := topic.ReplayDeadMessages(context.Background(), topicName, "")
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestReplayDeadMessages_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.ReplayDeadMessages).When(topicID, "").Return(2)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	n, err := topic.ReplayDeadMessages(context.Background(), topicName, "")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if n != 2 {
+		t.Fatalf("expected: 2 \n\t got: %v", n)
+	}
+}
+
+func TestPurgeDeadMessages_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.PurgeDeadMessages).When(topicID, "message1").Return(1)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	n, err := topic.PurgeDeadMessages(context.Background(), topicName, "message1")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if n != 1 {
+		t.Fatalf("expected: 1 \n\t got: %v", n)
+	}
+}
+
+func TestCreateTopic_InvalidNameFail(t *testing.T) {
+	expectedErr := errors.New("invalid topic name")
+
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), "12345", "go lang", domain.TopicTTL{}, 0, domain.TopicLimits{})
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestCreateTopic_InvalidHierarchicalNameFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, topicName := range []string{".orders", "orders.", "orders..eu", "orders.*", "orders.#"} {
+		err := topic.CreateTopic(context.Background(), "12345", topicName, domain.TopicTTL{}, 0, domain.TopicLimits{})
+		if !errors.Is(err, domain.ErrInvalidTopicName) {
+			t.Fatalf("%v: expected: %v \n\t got: %v", topicName, domain.ErrInvalidTopicName, err)
+		}
+	}
+}
+
+func TestCreateTopic_AlreadyExistsFail(t *testing.T) {
+	topicName := "golang"
+	expectedErr := errors.New("topic already exists")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("12345", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), "67890", topicName, domain.TopicTTL{}, 0, domain.TopicLimits{})
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestCreateTopic_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.InsertTopic).When(mock.Anything, topicID, topicName, 1, storage.TopicTTL{}, storage.TopicLimits{}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetPartitions).When(topicID, 1).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), topicID, topicName, domain.TopicTTL{}, 0, domain.TopicLimits{})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestCreateTopic_InvalidPartitionsFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, partitions := range []int{-1, 65} {
+		err := topic.CreateTopic(context.Background(), "12345", "golang", domain.TopicTTL{}, partitions, domain.TopicLimits{})
+		if !errors.Is(err, domain.ErrInvalidPartitions) {
+			t.Fatalf("%v: expected: %v \n\t got: %v", partitions, domain.ErrInvalidPartitions, err)
+		}
+	}
+}
+
+func TestCreateTopic_WithPartitionsPass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.InsertTopic).When(mock.Anything, topicID, topicName, 8, storage.TopicTTL{}, storage.TopicLimits{}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetPartitions).When(topicID, 8).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), topicID, topicName, domain.TopicTTL{}, 8, domain.TopicLimits{})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestCreateTopic_InvalidTTLFail(t *testing.T) {
+	topicName := "golang"
+	ttl := domain.TopicTTL{DefaultTTL: 2 * time.Hour, MaxTTL: time.Hour}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), "12345", topicName, ttl, 0, domain.TopicLimits{})
+	if !errors.Is(err, domain.ErrInvalidTopicTTL) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidTopicTTL, err)
+	}
+}
+
+func TestCreateTopic_WithTTLPass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+	ttl := domain.TopicTTL{DefaultTTL: time.Minute, MaxTTL: time.Hour}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.InsertTopic).When(mock.Anything, topicID, topicName, 1, storage.TopicTTL{DefaultTTL: 60, MaxTTL: 3600}, storage.TopicLimits{}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetPartitions).When(topicID, 1).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), topicID, topicName, ttl, 0, domain.TopicLimits{})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestUpdateTopicTTL_NegativeFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.UpdateTopicTTL(context.Background(), "golang", domain.TopicTTL{DefaultTTL: -time.Second})
+	if !errors.Is(err, domain.ErrInvalidTopicTTL) {
+		t.Fatalf("expected: %v \n\t got: %v", domain.ErrInvalidTopicTTL, err)
+	}
+}
+
+func TestUpdateTopicTTL_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.UpdateTopicTTL).When(mock.Anything, topicID, storage.TopicTTL{DefaultTTL: 30}).Return(nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{MaxTTL: time.Hour})
+
+	err := topic.UpdateTopicTTL(context.Background(), topicName, domain.TopicTTL{DefaultTTL: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestCreateTopic_WithLimitsPass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+	limits := domain.TopicLimits{MaxMessages: 100, Overflow: "block"}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.InsertTopic).When(mock.Anything, topicID, topicName, 1, storage.TopicTTL{}, storage.TopicLimits{MaxMessages: 100, Overflow: "block"}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetPartitions).When(topicID, 1).Return()
+	mockQueue.Given(queue.ImqQueueIF.SetLimits).When(topicID, queue.Limits{MaxMessages: 100, Overflow: queue.OverflowBlock}).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), topicID, topicName, domain.TopicTTL{}, 0, limits)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestCreateTopic_InsertTopicFail(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+	ttl := domain.TopicTTL{DefaultTTL: time.Minute}
+	limits := domain.TopicLimits{MaxMessages: 100, Overflow: "reject"}
+
+	expectedErr := errors.New("failed to insert topic")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.InsertTopic).When(mock.Anything, topicID, topicName, 1, storage.TopicTTL{DefaultTTL: 60}, storage.TopicLimits{MaxMessages: 100, Overflow: "reject"}).Return(expectedErr)
+
+	// the queue learns nothing of a topic that was not stored
+	mockQueue := &test.MockQueueIF{}
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.CreateTopic(context.Background(), topicID, topicName, ttl, 0, limits)
+	if err != expectedErr {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockQueue.AssertNotCalled(t, "SetLimits", topicID, mock.Anything)
+}
+
+func TestUpdateTopicLimits_InvalidFail(t *testing.T) {
+	topic := domain.NewTopic(&logrus.Logger{}, &test.MockDatabaseIF{}, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	for _, limits := range []domain.TopicLimits{{MaxMessages: -1}, {MaxBytes: -1}, {Overflow: "drop"}} {
+		err := topic.UpdateTopicLimits(context.Background(), "golang", limits)
+		if !errors.Is(err, domain.ErrInvalidLimits) {
+			t.Fatalf("%v: expected: %v \n\t got: %v", limits, domain.ErrInvalidLimits, err)
+		}
+	}
+}
+
+func TestUpdateTopicLimits_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.UpdateTopicLimits).When(mock.Anything, topicID, storage.TopicLimits{MaxBytes: 4096, Overflow: "dropOldest"}).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.SetLimits).When(topicID, queue.Limits{MaxBytes: 4096, Overflow: queue.OverflowDropOldest}).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.UpdateTopicLimits(context.Background(), topicName, domain.TopicLimits{MaxBytes: 4096, Overflow: "dropOldest"})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestRenameTopic_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, "go").Return("", nil)
+	mockDb.Given(storage.DatabaseIF.RenameTopic).When(mock.Anything, topicID, "go").Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return([]storage.SubscriptionPattern{}, nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	err := topic.RenameTopic(context.Background(), topicName, "go")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestRenameTopic_PatternSubscribersPass(t *testing.T) {
+	topicName := "orders.eu"
+	newTopicName := "billing.eu"
+	topicID := "12345"
+
+	patterns := []storage.SubscriptionPattern{
+		{SubscriberID: 6000, Pattern: "orders.*"},
+		{SubscriberID: 7000, Pattern: "*.eu"},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, newTopicName).Return("", nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscriptionPatterns).When(mock.Anything).Return(patterns, nil)
+	mockDb.Given(storage.DatabaseIF.RenameTopic).When(mock.Anything, topicID, newTopicName).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, 6000).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, 6000).Return([]string{"orders.*"}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedTopics).When(mock.Anything, 7000).Return([]string{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, 7000).Return([]string{"*.eu"}, nil)
+
+	// only the subscriber whose pattern does not match the new name stops reading the topic
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.RemoveSubscriber).When(topicID, 6000).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.RenameTopic(context.Background(), topicName, newTopicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestDeleteTopic_HasMessagesFail(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+	expectedErr := errors.New("topic still has messages, use force to delete it")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.DescribeTopic).When(topicID).Return(queue.TopicStats{Depth: 2})
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.DeleteTopic(context.Background(), topicName, false)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestDeleteTopic_ForcePass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.DeleteTopic).When(mock.Anything, topicID).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.DescribeTopic).When(topicID).Return(queue.TopicStats{Depth: 2})
+	mockQueue.Given(queue.ImqQueueIF.RemoveTopic).When(topicID)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	err := topic.DeleteTopic(context.Background(), topicName, true)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestDeleteTopic_PatternWatchersPass(t *testing.T) {
+	subscriberID := 6000
+	pattern := "orders.*"
+	topicName := "orders.eu"
+	topicID := "111"
+	topics := []string{topicName}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetSubscribedPatterns).When(mock.Anything, subscriberID).Return([]string{pattern}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchAllTopics).When(mock.Anything, subscriberID).Return(&topics, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.DeleteTopic).When(mock.Anything, topicID).Return(nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.Watch).When(topicID, subscriberID, mock.Anything).Return()
+	mockQueue.Given(queue.ImqQueueIF.DescribeTopic).When(topicID).Return(queue.TopicStats{})
+	mockQueue.Given(queue.ImqQueueIF.RemoveTopic).When(topicID)
+	mockQueue.Given(queue.ImqQueueIF.Unwatch).When(topicID, subscriberID).Return()
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	if err := topic.WatchTopic(context.Background(), subscriberID, pattern, func(domain.Message) error { return nil }); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if err := topic.DeleteTopic(context.Background(), topicName, false); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if err := topic.UnwatchTopic(context.Background(), subscriberID, pattern); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	mockQueue.AssertNotCalled(t, "Unwatch", topicID, subscriberID)
+}
+
+func TestDescribeTopic_NotFoundFail(t *testing.T) {
+	topicName := "golang"
+	expectedErr := errors.New("topic not found")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return("", nil)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	_, err := topic.DescribeTopic(context.Background(), topicName)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestDescribeTopic_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicStats).When(mock.Anything, topicID).Return(&storage.TopicStats{Publishers: 1, Subscribers: 2}, nil)
+	mockDb.Given(storage.DatabaseIF.GetTopicTTL).When(mock.Anything, topicID).Return(&storage.TopicTTL{DefaultTTL: 60}, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.DescribeTopic).When(topicID).Return(queue.TopicStats{Partitions: 2, Depth: 3, Bytes: 30, DeadMessages: 1, MaxMessages: 10, Overflow: queue.OverflowBlock})
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{DefaultTTL: time.Hour, MaxTTL: 24 * time.Hour})
+
+	expected := &domain.TopicDescription{
+		Name:         topicName,
+		Partitions:   2,
+		Depth:        3,
+		Bytes:        30,
+		DeadMessages: 1,
+		Publishers:   1,
+		Subscribers:  2,
+		TTL:          domain.TopicTTL{DefaultTTL: time.Minute, MaxTTL: 24 * time.Hour},
+		Limits:       domain.TopicLimits{MaxMessages: 10, Overflow: "block"},
+	}
+
+	got, err := topic.DescribeTopic(context.Background(), topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestListConsumerGroups_Fail(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+	expectedErr := errors.New("failed to get consumer groups")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, topicID).Return([]storage.ConsumerGroupMember{}, expectedErr)
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, &test.MockQueueIF{}, domain.TopicOptions{})
+
+	_, err := topic.ListConsumerGroups(context.Background(), topicName)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestListConsumerGroups_Pass(t *testing.T) {
+	topicName := "golang"
+	topicID := "12345"
+
+	members := []storage.ConsumerGroupMember{
+		{GroupName: "audit", SubscriberID: 8000},
+		{GroupName: "billing", SubscriberID: 6000},
+		{GroupName: "billing", SubscriberID: 7000},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.GetTopicIDFromTopic).When(mock.Anything, topicName).Return(topicID, nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, topicID).Return(members, nil)
+
+	mockQueue := &test.MockQueueIF{}
+	mockQueue.Given(queue.ImqQueueIF.DescribeGroup).When(topicID, "audit").Return(queue.GroupStats{
+		Partitions: []queue.GroupPartitionStats{{Partition: 0, Members: []int{8000}, Offset: 10}},
+	})
+	mockQueue.Given(queue.ImqQueueIF.DescribeGroup).When(topicID, "billing").Return(queue.GroupStats{
+		Pending: 2,
+		Lag:     6,
+		Partitions: []queue.GroupPartitionStats{
+			{Partition: 0, Members: []int{6000}, Offset: 4, Pending: 2, Lag: 1},
+			{Partition: 1, Members: []int{7000}, Offset: -1, Lag: 5},
+		},
+	})
+
+	topic := domain.NewTopic(&logrus.Logger{}, mockDb, mockQueue, domain.TopicOptions{})
+
+	expected := []domain.ConsumerGroup{
+		{
+			Name:       "audit",
+			Members:    []int{8000},
+			Partitions: []domain.GroupPartition{{Partition: 0, Members: []int{8000}, Offset: 10}},
+		},
+		{
+			Name:    "billing",
+			Members: []int{6000, 7000},
+			Pending: 2,
+			Lag:     6,
+			Partitions: []domain.GroupPartition{
+				{Partition: 0, Members: []int{6000}, Offset: 4, Pending: 2, Lag: 1},
+				{Partition: 1, Members: []int{7000}, Offset: -1, Lag: 5},
+			},
+		},
+	}
+
+	got, err := topic.ListConsumerGroups(context.Background(), topicName)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
@@ -0,0 +1,159 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// MigratorIF is the interface for the schema migrations
+type MigratorIF interface {
+	Up(ctx context.Context) (int, error)
+	Down(ctx context.Context, steps int) (int, error)
+	Status(ctx context.Context) ([]Status, error)
+}
+
+// Migrator applies the migrations to the db and records the applied versions in the SchemaMigration table
+type Migrator struct {
+	Cxn        *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator is the factory function for the Migrator
+func NewMigrator(cxn *sql.DB, migrations []Migration) MigratorIF {
+	sorted := make([]Migration, len(migrations))
+	copy(sorted, migrations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version < sorted[j].Version
+	})
+
+	return &Migrator{
+		Cxn:        cxn,
+		migrations: sorted,
+	}
+}
+
+// Up applies every migration that has not been applied yet and returns how many were applied
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, mg := range m.migrations {
+		if _, ok := applied[mg.Version]; ok {
+			continue
+		}
+
+		if err := m.exec(ctx, mg.Up); err != nil {
+			return count, errors.Wrapf(err, "failed to apply migration %d %v", mg.Version, mg.Name)
+		}
+
+		stmt := fmt.Sprintf("INSERT INTO %s (version,name) VALUES (?,?)", schemaTable)
+		if _, err := m.Cxn.ExecContext(ctx, stmt, mg.Version, mg.Name); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Down reverts the given number of the most recently applied migrations and returns how many were reverted
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		mg := m.migrations[i]
+		if _, ok := applied[mg.Version]; !ok {
+			continue
+		}
+
+		if err := m.exec(ctx, mg.Down); err != nil {
+			return count, errors.Wrapf(err, "failed to revert migration %d %v", mg.Version, mg.Name)
+		}
+
+		stmt := fmt.Sprintf("DELETE FROM %s WHERE version = ?", schemaTable)
+		if _, err := m.Cxn.ExecContext(ctx, stmt, mg.Version); err != nil {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status returns every migration along with whether it has been applied
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := []Status{}
+	for _, mg := range m.migrations {
+		appliedAt, ok := applied[mg.Version]
+		status = append(status, Status{
+			Version:   mg.Version,
+			Name:      mg.Name,
+			Applied:   ok,
+			AppliedAt: appliedAt,
+		})
+	}
+
+	return status, nil
+}
+
+// applied creates the SchemaMigration table when missing and returns when each applied version was applied
+func (m *Migrator) applied(ctx context.Context) (map[int]string, error) {
+	stmt := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+				version int(10) NOT NULL,
+				name varchar(100) NOT NULL,
+				appliedAt timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+				PRIMARY KEY (version)
+			) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4`, schemaTable)
+
+	if _, err := m.Cxn.ExecContext(ctx, stmt); err != nil {
+		return nil, errors.Wrap(err, "could not create schema table")
+	}
+
+	stmt = fmt.Sprintf("SELECT version,appliedAt FROM %s", schemaTable)
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	applied := map[int]string{}
+	for row.Next() {
+		var (
+			version   int
+			appliedAt string
+		)
+		if err := row.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, nil
+}
+
+func (m *Migrator) exec(ctx context.Context, stmts []string) error {
+	for _, stmt := range stmts {
+		if _, err := m.Cxn.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package migration_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/migration"
+)
+
+var testMigrations = []migration.Migration{
+	{Version: 2, Name: "create Publisher table", Up: []string{"CREATE TABLE Publisher"}, Down: []string{"DROP TABLE Publisher"}},
+	{Version: 1, Name: "create Topic table", Up: []string{"CREATE TABLE Topic"}, Down: []string{"DROP TABLE Topic"}},
+}
+
+func TestUp_Pass(t *testing.T) {
+	dbCxn, mock, _ := sqlmock.New()
+	m := migration.NewMigrator(dbCxn, testMigrations)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS SchemaMigration`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version,appliedAt FROM SchemaMigration`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, "2021-02-27 20:03:09"))
+	mock.ExpectExec(`CREATE TABLE Publisher`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO SchemaMigration \(version,name\) VALUES \(\?,\?\)`).
+		WithArgs(2, "create Publisher table").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	count, err := m.Up(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected: %v, got: %v", 1, count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestUp_Fail(t *testing.T) {
+	dbCxn, mock, _ := sqlmock.New()
+	m := migration.NewMigrator(dbCxn, testMigrations)
+
+	expectedErr := errors.New("failed to apply migration 1 create Topic table: table exists")
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS SchemaMigration`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version,appliedAt FROM SchemaMigration`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}))
+	mock.ExpectExec(`CREATE TABLE Topic`).WillReturnError(errors.New("table exists"))
+
+	count, err := m.Up(context.Background())
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+
+	if count != 0 {
+		t.Fatalf("expected: %v, got: %v", 0, count)
+	}
+}
+
+func TestDown_Pass(t *testing.T) {
+	dbCxn, mock, _ := sqlmock.New()
+	m := migration.NewMigrator(dbCxn, testMigrations)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS SchemaMigration`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version,appliedAt FROM SchemaMigration`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, "2021-02-27 20:03:09").AddRow(2, "2021-02-27 20:03:09"))
+	mock.ExpectExec(`DROP TABLE Publisher`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM SchemaMigration WHERE version = \?`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	count, err := m.Down(context.Background(), 1)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if count != 1 {
+		t.Fatalf("expected: %v, got: %v", 1, count)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestStatus_Pass(t *testing.T) {
+	dbCxn, mock, _ := sqlmock.New()
+	m := migration.NewMigrator(dbCxn, testMigrations)
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS SchemaMigration`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version,appliedAt FROM SchemaMigration`).
+		WillReturnRows(sqlmock.NewRows([]string{"version", "appliedAt"}).AddRow(1, "2021-02-27 20:03:09"))
+
+	expected := []migration.Status{
+		{Version: 1, Name: "create Topic table", Applied: true, AppliedAt: "2021-02-27 20:03:09"},
+		{Version: 2, Name: "create Publisher table"},
+	}
+
+	status, err := m.Status(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, status) {
+		t.Fatalf("expected: %v, got: %v", expected, status)
+	}
+}
+
+func TestMigrations_OrderedVersions(t *testing.T) {
+	for i, m := range migration.Migrations {
+		if m.Version != i+1 {
+			t.Fatalf("expected: %v, got: %v", i+1, m.Version)
+		}
+
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			t.Fatalf("expected: up and down statements for %v, got: %v %v", m.Name, m.Up, m.Down)
+		}
+	}
+}
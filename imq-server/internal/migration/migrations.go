@@ -0,0 +1,325 @@
+package migration
+
+// Migrations holds the schema of the MessagingQueue db in the order it has to be applied
+var Migrations = []Migration{
+	{
+		Version: 1,
+		Name:    "create Topic table",
+		Up: []string{
+			"CREATE TABLE `Topic` (\n" +
+				"  `topicId` varchar(45) NOT NULL,\n" +
+				"  `name` varchar(45) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`topicId`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Topic`"},
+	},
+	{
+		Version: 2,
+		Name:    "create Publisher table",
+		Up: []string{
+			"CREATE TABLE `Publisher` (\n" +
+				"  `publisherId` int(10) NOT NULL,\n" +
+				"  `topicId` varchar(45) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`publisherId`),\n" +
+				"  KEY `topicId_idx` (`topicId`),\n" +
+				"  CONSTRAINT `publisher_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Publisher`"},
+	},
+	{
+		Version: 3,
+		Name:    "create Subscriber table",
+		Up: []string{
+			"CREATE TABLE `Subscriber` (\n" +
+				"  `subscriberId` int(10) NOT NULL,\n" +
+				"  PRIMARY KEY (`subscriberId`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Subscriber`"},
+	},
+	{
+		Version: 4,
+		Name:    "create Message table",
+		Up: []string{
+			"CREATE TABLE `Message` (\n" +
+				"  `messageId` varchar(45) NOT NULL,\n" +
+				"  `data` varchar(500) DEFAULT NULL,\n" +
+				"  `createdAt` timestamp NULL DEFAULT NULL,\n" +
+				"  `expiredAt` timestamp NULL DEFAULT NULL,\n" +
+				"  `pubId` int(10) DEFAULT NULL,\n" +
+				"  `topicId` varchar(45) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`messageId`),\n" +
+				"  KEY `publisherID_idx` (`pubId`),\n" +
+				"  KEY `topicID_idx` (`topicId`),\n" +
+				"  CONSTRAINT `message_publisher` FOREIGN KEY (`pubId`) REFERENCES `Publisher` (`publisherid`),\n" +
+				"  CONSTRAINT `message_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Message`"},
+	},
+	{
+		Version: 5,
+		Name:    "create SubscriberTopicMap table",
+		Up: []string{
+			"CREATE TABLE `SubscriberTopicMap` (\n" +
+				"  `id` int(10) NOT NULL AUTO_INCREMENT,\n" +
+				"  `subscriberId` int(10) NOT NULL,\n" +
+				"  `topicId` varchar(45) NOT NULL,\n" +
+				"  PRIMARY KEY (`id`),\n" +
+				"  KEY `subID_idx` (`subscriberId`),\n" +
+				"  KEY `topicID_idx` (`topicId`),\n" +
+				"  CONSTRAINT `submap_subscriber` FOREIGN KEY (`subscriberId`) REFERENCES `Subscriber` (`subscriberid`),\n" +
+				"  CONSTRAINT `topic_ID` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `SubscriberTopicMap`"},
+	},
+	{
+		Version: 6,
+		Name:    "create Queue table",
+		Up: []string{
+			"CREATE TABLE `Queue` (\n" +
+				"  `queueId` varchar(45) NOT NULL,\n" +
+				"  `topicId` varchar(45) DEFAULT NULL,\n" +
+				"  `messageId` varchar(45) DEFAULT NULL,\n" +
+				"  `messageOffset` bigint(20) NOT NULL DEFAULT 0,\n" +
+				"  PRIMARY KEY (`queueId`),\n" +
+				"  KEY `queue_topic_idx` (`topicId`),\n" +
+				"  KEY `queue_message_idx` (`messageId`),\n" +
+				"  CONSTRAINT `queue_message` FOREIGN KEY (`messageId`) REFERENCES `Message` (`messageid`),\n" +
+				"  CONSTRAINT `queue_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Queue`"},
+	},
+	{
+		Version: 7,
+		Name:    "create DLQ table",
+		Up: []string{
+			"CREATE TABLE `DLQ` (\n" +
+				"  `dlqId` varchar(45) NOT NULL,\n" +
+				"  `topicId` varchar(45) DEFAULT NULL,\n" +
+				"  `messageId` varchar(45) DEFAULT NULL,\n" +
+				"  `reason` varchar(100) DEFAULT NULL,\n" +
+				"  `deadAt` timestamp NULL DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`dlqId`),\n" +
+				"  KEY `dlq_message_idx` (`messageId`),\n" +
+				"  KEY `dlq_topic_idx` (`topicId`),\n" +
+				"  CONSTRAINT `dlq_message` FOREIGN KEY (`messageId`) REFERENCES `Message` (`messageid`),\n" +
+				"  CONSTRAINT `dlq_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `DLQ`"},
+	},
+	{
+		Version: 8,
+		Name:    "create SubscriberOffset table",
+		Up: []string{
+			"CREATE TABLE `SubscriberOffset` (\n" +
+				"  `subscriberId` int(10) NOT NULL,\n" +
+				"  `topicId` varchar(45) NOT NULL,\n" +
+				"  `messageOffset` bigint(20) NOT NULL,\n" +
+				"  PRIMARY KEY (`subscriberId`,`topicId`),\n" +
+				"  KEY `offset_topic_idx` (`topicId`),\n" +
+				"  CONSTRAINT `offset_subscriber` FOREIGN KEY (`subscriberId`) REFERENCES `Subscriber` (`subscriberid`),\n" +
+				"  CONSTRAINT `offset_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `SubscriberOffset`"},
+	},
+	{
+		Version: 9,
+		Name:    "seed Topic table",
+		Up: []string{
+			"INSERT INTO `Topic` (`topicId`,`name`) VALUES " +
+				"('6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0001','golang')," +
+				"('6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0002','java')," +
+				"('6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0003','python')",
+		},
+		Down: []string{
+			"DELETE FROM `Topic` WHERE `topicId` IN (" +
+				"'6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0001'," +
+				"'6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0002'," +
+				"'6f0c5c3e-4d0a-4a51-9d43-3a3c1b0e0003')",
+		},
+	},
+	{
+		Version: 10,
+		Name:    "create Client table",
+		Up: []string{
+			"CREATE TABLE `Client` (\n" +
+				"  `clientId` int(10) NOT NULL AUTO_INCREMENT,\n" +
+				"  `name` varchar(45) NOT NULL,\n" +
+				"  `role` varchar(20) NOT NULL,\n" +
+				"  `apiKeyHash` char(64) NOT NULL,\n" +
+				"  PRIMARY KEY (`clientId`),\n" +
+				"  UNIQUE KEY `client_name` (`name`),\n" +
+				"  UNIQUE KEY `client_apikey` (`apiKeyHash`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=1000 DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `Client`"},
+	},
+	{
+		Version: 11,
+		Name:    "add key, content-type and headers to Message table",
+		Up: []string{
+			"ALTER TABLE `Message`\n" +
+				"  MODIFY `data` blob DEFAULT NULL,\n" +
+				"  ADD COLUMN `messageKey` varchar(255) DEFAULT NULL AFTER `messageId`,\n" +
+				"  ADD COLUMN `contentType` varchar(100) DEFAULT NULL AFTER `messageKey`,\n" +
+				"  ADD COLUMN `headers` text DEFAULT NULL AFTER `contentType`",
+		},
+		Down: []string{
+			"ALTER TABLE `Message`\n" +
+				"  DROP COLUMN `headers`,\n" +
+				"  DROP COLUMN `contentType`,\n" +
+				"  DROP COLUMN `messageKey`,\n" +
+				"  MODIFY `data` varchar(500) DEFAULT NULL",
+		},
+	},
+	{
+		Version: 12,
+		Name:    "add default and maximum ttl to Topic table",
+		Up: []string{
+			"ALTER TABLE `Topic`\n" +
+				"  ADD COLUMN `defaultTtl` int(10) NOT NULL DEFAULT 0,\n" +
+				"  ADD COLUMN `maxTtl` int(10) NOT NULL DEFAULT 0",
+		},
+		Down: []string{
+			"ALTER TABLE `Topic`\n" +
+				"  DROP COLUMN `maxTtl`,\n" +
+				"  DROP COLUMN `defaultTtl`",
+		},
+	},
+	{
+		Version: 13,
+		Name:    "create PublisherTopicMap table",
+		Up: []string{
+			"CREATE TABLE `PublisherTopicMap` (\n" +
+				"  `publisherId` int(10) NOT NULL,\n" +
+				"  `topicId` varchar(45) NOT NULL,\n" +
+				"  PRIMARY KEY (`publisherId`,`topicId`),\n" +
+				"  KEY `pubmap_topic_idx` (`topicId`),\n" +
+				"  CONSTRAINT `pubmap_publisher` FOREIGN KEY (`publisherId`) REFERENCES `Publisher` (`publisherid`),\n" +
+				"  CONSTRAINT `pubmap_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+			"INSERT INTO `PublisherTopicMap` (`publisherId`,`topicId`)\n" +
+				"  SELECT `publisherId`,`topicId` FROM `Publisher` WHERE `topicId` IS NOT NULL",
+			"ALTER TABLE `Publisher`\n" +
+				"  DROP FOREIGN KEY `publisher_topic`,\n" +
+				"  DROP KEY `topicId_idx`,\n" +
+				"  DROP COLUMN `topicId`",
+		},
+		Down: []string{
+			"ALTER TABLE `Publisher`\n" +
+				"  ADD COLUMN `topicId` varchar(45) DEFAULT NULL,\n" +
+				"  ADD KEY `topicId_idx` (`topicId`),\n" +
+				"  ADD CONSTRAINT `publisher_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)",
+			"UPDATE `Publisher` AS P SET `topicId` =\n" +
+				"  (SELECT MIN(`topicId`) FROM `PublisherTopicMap` AS M WHERE M.`publisherId` = P.`publisherId`)",
+			"DROP TABLE `PublisherTopicMap`",
+		},
+	},
+	{
+		Version: 14,
+		Name:    "create SubscriberPatternMap table",
+		Up: []string{
+			"CREATE TABLE `SubscriberPatternMap` (\n" +
+				"  `subscriberId` int(10) NOT NULL,\n" +
+				"  `pattern` varchar(45) NOT NULL,\n" +
+				"  PRIMARY KEY (`subscriberId`,`pattern`),\n" +
+				"  CONSTRAINT `patmap_subscriber` FOREIGN KEY (`subscriberId`) REFERENCES `Subscriber` (`subscriberid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{"DROP TABLE `SubscriberPatternMap`"},
+	},
+	{
+		Version: 15,
+		Name:    "add filter to SubscriberTopicMap table",
+		Up: []string{
+			"ALTER TABLE `SubscriberTopicMap`\n" +
+				"  ADD COLUMN `filter` varchar(255) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE `SubscriberTopicMap`\n" +
+				"  DROP COLUMN `filter`",
+		},
+	},
+	{
+		Version: 16,
+		Name:    "add consumer groups to SubscriberTopicMap table",
+		Up: []string{
+			"ALTER TABLE `SubscriberTopicMap`\n" +
+				"  ADD COLUMN `groupName` varchar(45) NOT NULL DEFAULT ''",
+			"CREATE TABLE `ConsumerGroupOffset` (\n" +
+				"  `groupName` varchar(45) NOT NULL,\n" +
+				"  `topicId` varchar(45) NOT NULL,\n" +
+				"  `messageOffset` bigint(20) NOT NULL,\n" +
+				"  PRIMARY KEY (`groupName`,`topicId`),\n" +
+				"  KEY `groupoffset_topic_idx` (`topicId`),\n" +
+				"  CONSTRAINT `groupoffset_topic` FOREIGN KEY (`topicId`) REFERENCES `Topic` (`topicid`)\n" +
+				") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4",
+		},
+		Down: []string{
+			"DROP TABLE `ConsumerGroupOffset`",
+			"ALTER TABLE `SubscriberTopicMap`\n" +
+				"  DROP COLUMN `groupName`",
+		},
+	},
+	{
+		Version: 17,
+		Name:    "add partitions to Topic, Queue, DLQ and offset tables",
+		Up: []string{
+			"ALTER TABLE `Topic`\n" +
+				"  ADD COLUMN `partitions` int(10) NOT NULL DEFAULT 1",
+			"ALTER TABLE `Queue`\n" +
+				"  ADD COLUMN `partitionId` int(10) NOT NULL DEFAULT 0",
+			"ALTER TABLE `DLQ`\n" +
+				"  ADD COLUMN `partitionId` int(10) NOT NULL DEFAULT 0",
+			"ALTER TABLE `SubscriberOffset`\n" +
+				"  ADD COLUMN `partitionId` int(10) NOT NULL DEFAULT 0,\n" +
+				"  DROP PRIMARY KEY,\n" +
+				"  ADD PRIMARY KEY (`subscriberId`,`topicId`,`partitionId`)",
+			"ALTER TABLE `ConsumerGroupOffset`\n" +
+				"  ADD COLUMN `partitionId` int(10) NOT NULL DEFAULT 0,\n" +
+				"  DROP PRIMARY KEY,\n" +
+				"  ADD PRIMARY KEY (`groupName`,`topicId`,`partitionId`)",
+		},
+		Down: []string{
+			"DELETE FROM `ConsumerGroupOffset` WHERE `partitionId` > 0",
+			"ALTER TABLE `ConsumerGroupOffset`\n" +
+				"  DROP PRIMARY KEY,\n" +
+				"  ADD PRIMARY KEY (`groupName`,`topicId`),\n" +
+				"  DROP COLUMN `partitionId`",
+			"DELETE FROM `SubscriberOffset` WHERE `partitionId` > 0",
+			"ALTER TABLE `SubscriberOffset`\n" +
+				"  DROP PRIMARY KEY,\n" +
+				"  ADD PRIMARY KEY (`subscriberId`,`topicId`),\n" +
+				"  DROP COLUMN `partitionId`",
+			"ALTER TABLE `DLQ`\n" +
+				"  DROP COLUMN `partitionId`",
+			"ALTER TABLE `Queue`\n" +
+				"  DROP COLUMN `partitionId`",
+			"ALTER TABLE `Topic`\n" +
+				"  DROP COLUMN `partitions`",
+		},
+	},
+	{
+		Version: 18,
+		Name:    "add message limits and overflow policy to Topic table",
+		Up: []string{
+			"ALTER TABLE `Topic`\n" +
+				"  ADD COLUMN `maxMessages` int(10) NOT NULL DEFAULT 0,\n" +
+				"  ADD COLUMN `maxBytes` int(10) NOT NULL DEFAULT 0,\n" +
+				"  ADD COLUMN `overflowPolicy` varchar(20) NOT NULL DEFAULT ''",
+		},
+		Down: []string{
+			"ALTER TABLE `Topic`\n" +
+				"  DROP COLUMN `overflowPolicy`,\n" +
+				"  DROP COLUMN `maxBytes`,\n" +
+				"  DROP COLUMN `maxMessages`",
+		},
+	},
+}
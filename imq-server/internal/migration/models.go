@@ -0,0 +1,19 @@
+package migration
+
+// Migration holds the statements to move the schema up to Version and back down
+type Migration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// Status holds a migration and whether it has been applied
+type Status struct {
+	Version   int
+	Name      string
+	Applied   bool
+	AppliedAt string
+}
+
+const schemaTable = "SchemaMigration"
@@ -0,0 +1,151 @@
+package queue
+
+import "encoding/json"
+
+// appendLog writes the change to the write-ahead log before it is applied to the queue
+func (q *Queue) appendLog(record logRecord) error {
+	if q.opts.Log == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	return q.opts.Log.Append(data)
+}
+
+// record writes a change that cannot be refused to the write-ahead log
+func (q *Queue) record(record logRecord) {
+	if err := q.appendLog(record); err != nil {
+		q.log.WithField("topicId", record.TopicID).Errorf("record: failed to write %v to log: %v", record.Type, err)
+	}
+}
+
+// recordCommit writes the offset the consumer has to resume reading from to the write-ahead log
+func (q *Queue) recordCommit(t *topicQueue, c consumer) {
+	if _, ok := t.cursors[c]; !ok {
+		return
+	}
+
+	q.record(logRecord{Type: recordCommit, TopicID: t.id, Partition: t.partition, SubscriberID: c.subscriberID, Group: c.group, Offset: resumeOffset(t, c)})
+}
+
+// replayLog applies every change recorded in the write-ahead log since the last checkpoint on top of the loaded queue
+func (q *Queue) replayLog() error {
+	if q.opts.Log == nil {
+		return nil
+	}
+
+	return q.opts.Log.Replay(func(data []byte) error {
+		record := logRecord{}
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+
+		q.apply(record)
+
+		return nil
+	})
+}
+
+func (q *Queue) apply(record logRecord) {
+	if record.Type == recordRemoveTopic {
+		q.removeTopic(record.TopicID)
+		return
+	}
+
+	t := q.getPartition(record.TopicID, record.Partition)
+
+	switch record.Type {
+	case recordPublish:
+		if record.Message == nil || record.Message.Offset < t.nextOffset {
+			return
+		}
+
+		pushMessage(t, *record.Message)
+		t.nextOffset = record.Message.Offset + 1
+
+	case recordDrop:
+		n := 0
+		for n < len(t.messages) && t.messages[n].Offset <= record.Offset {
+			n++
+		}
+		popMessages(t, n)
+
+	case recordCommit:
+		t.cursors[consumer{subscriberID: record.SubscriberID, group: record.Group}] = record.Offset
+
+		if record.Offset > t.nextOffset {
+			t.nextOffset = record.Offset
+		}
+
+	case recordUnsubscribe:
+		delete(t.cursors, consumer{subscriberID: record.SubscriberID, group: record.Group})
+
+	case recordDead:
+		if record.Message == nil || isDead(t, record.Message.MessageID) {
+			return
+		}
+
+		t.dead = append(t.dead, DeadMessage{
+			Message: *record.Message,
+			Reason:  record.Reason,
+			DeadAt:  record.DeadAt,
+		})
+
+	case recordPurge:
+		takeDeadMessages(t, record.MessageID)
+	}
+}
+
+// checkpoint replaces the write-ahead log with the current state of the queue, dropping every consumed entry.
+// The caller holds the queue for writing so no change is recorded while the snapshot is taken
+func (q *Queue) checkpoint() error {
+	if q.opts.Log == nil {
+		return nil
+	}
+
+	records := []logRecord{}
+
+	partitions := allPartitions(q.topics)
+
+	for _, t := range partitions {
+		for _, m := range t.messages {
+			msg := m
+			records = append(records, logRecord{Type: recordPublish, TopicID: t.id, Partition: t.partition, Message: &msg})
+		}
+	}
+
+	for _, t := range partitions {
+		for _, d := range t.dead {
+			msg := d.Message
+			records = append(records, logRecord{Type: recordDead, TopicID: t.id, Partition: t.partition, Message: &msg, Reason: d.Reason, DeadAt: d.DeadAt})
+		}
+	}
+
+	for _, o := range getSubscriberOffsets(q.topics) {
+		records = append(records, logRecord{Type: recordCommit, TopicID: o.TopicID, Partition: o.Partition, SubscriberID: o.SubscriberID, Group: o.Group, Offset: o.Offset})
+	}
+
+	snapshot := make([][]byte, 0, len(records))
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		snapshot = append(snapshot, data)
+	}
+
+	return q.opts.Log.Compact(snapshot)
+}
+
+func isDead(t *topicQueue, messageID string) bool {
+	for _, d := range t.dead {
+		if d.MessageID == messageID {
+			return true
+		}
+	}
+	return false
+}
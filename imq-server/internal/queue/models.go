@@ -1,11 +1,24 @@
 package queue
 
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/wal"
+)
+
 // Message holds message data
 type Message struct {
-	MessageID string
-	Data      string
-	CretedAt  string
-	ExpiresAt string
+	MessageID     string
+	Offset        int64
+	Key           string
+	ContentType   string
+	Headers       map[string]string
+	Data          []byte
+	CretedAt      time.Time
+	ExpiresAt     time.Time
+	DeliveryCount int
 }
 
 // SendMessageRequest holds data for pusing message to the queue
@@ -13,3 +26,198 @@ type SendMessageRequest struct {
 	TopicID string
 	Message Message
 }
+
+// DeadMessage holds a message moved to the DeadQueue along with why and when it was moved
+type DeadMessage struct {
+	Message
+	Reason string
+	DeadAt time.Time
+}
+
+// TopicStats holds how many messages the queue holds for a topic along with its number of partitions
+// and the limits of every partition
+type TopicStats struct {
+	Partitions   int
+	Depth        int
+	Bytes        int
+	DeadMessages int
+	MaxMessages  int
+	MaxBytes     int
+	Overflow     OverflowPolicy
+}
+
+// GroupStats holds how many messages of a topic a consumer group has not acknowledged yet and how many it has
+// not read yet, along with its read offset for every partition of the topic
+type GroupStats struct {
+	Pending    int
+	Lag        int
+	Partitions []GroupPartitionStats
+}
+
+// GroupPartitionStats holds the read offset of a consumer group for a partition of a topic along with the members
+// the partition is assigned to, empty when the group has no member, and how many messages of the partition the
+// group has not acknowledged yet and how many it has not read yet
+type GroupPartitionStats struct {
+	Partition int
+	Members   []int
+	Offset    int64
+	Pending   int
+	Lag       int
+}
+
+// Limits holds how many messages and bytes every partition of a topic holds at most along with the overflow policy
+// applied to a message published to a full partition. The options of the queue are used for what a topic leaves at zero
+type Limits struct {
+	MaxMessages int
+	MaxBytes    int
+	Overflow    OverflowPolicy
+}
+
+// OverflowPolicy decides what happens to a message published to a topic that is full
+type OverflowPolicy string
+
+const (
+	// OverflowReject refuses the message with ErrQueueFull, the publisher may send it again later
+	OverflowReject OverflowPolicy = "reject"
+	// OverflowDropOldest moves the oldest messages of the topic to the DeadQueue until the message fits
+	OverflowDropOldest OverflowPolicy = "dropOldest"
+	// OverflowBlock holds the publisher until the message fits or the overflow timeout elapses
+	OverflowBlock OverflowPolicy = "block"
+)
+
+// Valid reports whether the policy is one the queue applies, an empty policy leaves the choice to the queue
+func (p OverflowPolicy) Valid() bool {
+	switch p {
+	case "", OverflowReject, OverflowDropOldest, OverflowBlock:
+		return true
+	}
+
+	return false
+}
+
+// Options holds the delivery settings of the queue
+type Options struct {
+	VisibilityTimeout time.Duration
+	MaxDeliveries     int
+	// MaxMessages and MaxBytes bound the messages held for every partition of a topic that sets no limits of its
+	// own, a zero limit is not enforced
+	MaxMessages int
+	MaxBytes    int
+	// Overflow is applied to a message published to a full topic that sets no policy of its own, OverflowReject when empty
+	Overflow OverflowPolicy
+	// OverflowTimeout is how long a publish waits for room under OverflowBlock
+	OverflowTimeout time.Duration
+	// Log records every change of the queue before it is applied, the queue is kept in memory only when nil
+	Log wal.WriteAheadLogIF
+}
+
+// delivery holds a message handed to a subscriber that has not been acknowledged yet
+type delivery struct {
+	message  Message
+	attempts int
+	deadline time.Time
+}
+
+// consumer identifies the owner of a read offset and of unacknowledged messages, which is either a subscriber
+// reading on its own or a consumer group whose members share them
+type consumer struct {
+	subscriberID int
+	group        string
+}
+
+// topic holds the partitions of a topic. Every partition is locked on its own so the partitions of a topic are served
+// concurrently, the messages of a partition are read in the order they were published
+type topic struct {
+	id         string
+	partitions []*topicQueue
+	// next is the partition the next message published without a key is pushed to
+	next int
+	// reads is the partition the next read of the topic starts from
+	reads int
+	// members holds the members of every consumer group reading the topic in order of subscriber id
+	members map[string][]int
+	// limits holds the limits set for the topic, every partition keeps a copy of them
+	limits Limits
+	// mu is held while the consumer groups of the topic change so partitions are assigned from a consistent view
+	mu sync.Mutex
+}
+
+// topicQueue holds the messages, dead messages, subscribers, watchers, filters and consumer groups of a partition of
+// a topic, a partition is only read or changed while its own lock is held
+type topicQueue struct {
+	id         string
+	partition  int
+	mu         sync.Mutex
+	messages   []Message
+	bytes      int
+	dead       []DeadMessage
+	cursors    map[consumer]int64
+	nextOffset int64
+	pending    map[consumer][]*delivery
+	watchers   map[int]func(Message) error
+	// filters holds the messages each subscriber reads, the messages a filter rejects are skipped for the subscriber
+	filters map[int]func(Message) bool
+	// groups holds the consumer group of every subscriber reading the topic as a member of one
+	groups map[int]string
+	// owners holds the members of every consumer group the partition is assigned to, only they read the partition
+	owners map[string][]int
+	// turns holds the member of every consumer group a message of the partition was pushed to last
+	turns map[string]int
+	// limits holds the limits set for the topic, the zero ones fall back to the options of the queue
+	limits Limits
+	// room is closed once messages are dropped from the topic, waking the publishers blocked on a full topic
+	room chan struct{}
+	// removed is set once the topic is dropped from the queue, a caller still holding it has to look the topic up again
+	removed bool
+}
+
+// logRecord holds a change of the queue written to the write-ahead log
+type logRecord struct {
+	Type         string    `json:"type"`
+	TopicID      string    `json:"topicId"`
+	Partition    int       `json:"partition,omitempty"`
+	SubscriberID int       `json:"subscriberId,omitempty"`
+	Group        string    `json:"group,omitempty"`
+	Offset       int64     `json:"offset,omitempty"`
+	MessageID    string    `json:"messageId,omitempty"`
+	Message      *Message  `json:"message,omitempty"`
+	Reason       string    `json:"reason,omitempty"`
+	DeadAt       time.Time `json:"deadAt"`
+}
+
+const (
+	recordPublish     = "publish"
+	recordCommit      = "commit"
+	recordUnsubscribe = "unsubscribe"
+	recordDead        = "dead"
+	recordDrop        = "drop"
+	recordPurge       = "purge"
+	recordRemoveTopic = "removeTopic"
+)
+
+const (
+	// ReasonExpired is recorded for messages that expired before every subscriber read them
+	ReasonExpired = "expired"
+	// ReasonMaxDeliveries is recorded for messages that were delivered the maximum number of times without being acknowledged
+	ReasonMaxDeliveries = "maximum deliveries exceeded"
+	// ReasonOverflow is recorded for messages dropped from a full topic to make room for newer ones
+	ReasonOverflow = "dropped to make room for newer messages"
+
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxDeliveries     = 5
+	defaultOverflowTimeout   = 5 * time.Second
+	redeliveryInterval       = time.Second
+	maxLogSegments           = 4
+)
+
+// errors returned by the queue, callers tell them apart with errors.Is
+var (
+	ErrQueueEmpty            = errors.New("no message present in queue")
+	ErrNoTopic               = errors.New("you are not register to any topics")
+	ErrEmptyMessage          = errors.New("message cannot be empty")
+	ErrPersistFailed         = errors.New("failed to persist message")
+	ErrNotAwaitingAck        = errors.New("message is not awaiting acknowledgement")
+	ErrQueueFull             = errors.New("topic is full, try again later")
+	ErrMessageTooLarge       = errors.New("message is larger than the topic can hold")
+	ErrInvalidOverflowPolicy = errors.New("invalid overflow policy")
+)
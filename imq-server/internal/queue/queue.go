@@ -2,9 +2,10 @@ package queue
 
 import (
 	"context"
-	"errors"
+	"hash/fnv"
+	"sort"
+	"sync"
 	"time"
-	"fmt"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
 	"github.com/google/uuid"
@@ -14,7 +15,26 @@ import (
 // ImqQueueIF is the inteerface for the Queue
 type ImqQueueIF interface {
 	SendMessage(ctx context.Context, message SendMessageRequest) error
-	RetrieveMessage(ctx context.Context, topicID string) (*Message, error)
+	SendMessages(ctx context.Context, topicID string, messages []Message) []error
+	RetrieveMessage(ctx context.Context, topicID string, subscriberID int) (*Message, error)
+	RetrieveMessages(ctx context.Context, topicID string, subscriberID int, maxCount int, maxBytes int) ([]Message, error)
+	AddSubscriber(topicID string, subscriberID int)
+	RemoveSubscriber(topicID string, subscriberID int)
+	AckMessage(topicID string, subscriberID int, messageID string) error
+	NackMessage(topicID string, subscriberID int, messageID string) error
+	Watch(topicID string, subscriberID int, fn func(Message) error)
+	Unwatch(topicID string, subscriberID int)
+	SetFilter(topicID string, subscriberID int, filter func(Message) bool)
+	SetPartitions(topicID string, partitions int)
+	SetLimits(topicID string, limits Limits)
+	JoinGroup(topicID string, subscriberID int, group string)
+	RemoveGroup(topicID string, group string)
+	DescribeGroup(topicID string, group string) GroupStats
+	ListDeadMessages(topicID string) []DeadMessage
+	PurgeDeadMessages(topicID string, messageID string) int
+	ReplayDeadMessages(topicID string, messageID string) int
+	DescribeTopic(topicID string) TopicStats
+	RemoveTopic(topicID string)
 	BackUpQueue(ctx context.Context) error
 	loadQueue() error
 	saveQueue(ctx context.Context) error
@@ -23,19 +43,43 @@ type ImqQueueIF interface {
 
 // Queue is the concrete implementztion for Queue
 type Queue struct {
-	log       *logrus.Logger
-	db        storage.DatabaseIF
-	LiveQueue map[string][]Message
-	DeadQueue map[string][]Message
+	log      *logrus.Logger
+	db       storage.DatabaseIF
+	topics   map[string]*topic
+	topicsMu sync.Mutex
+	opts     Options
+	// mu is held for reading while a partition is read or changed and for writing while the whole queue is
+	// checkpointed or backed up, so a snapshot never misses a change written to the log
+	mu sync.RWMutex
 }
 
 // NewQueue is the factory function for the Queue
-func NewQueue(log *logrus.Logger, db storage.DatabaseIF) (ImqQueueIF, error) {
+func NewQueue(log *logrus.Logger, db storage.DatabaseIF, opts Options) (ImqQueueIF, error) {
+	if opts.VisibilityTimeout <= 0 {
+		opts.VisibilityTimeout = defaultVisibilityTimeout
+	}
+
+	if opts.MaxDeliveries <= 0 {
+		opts.MaxDeliveries = defaultMaxDeliveries
+	}
+
+	if !opts.Overflow.Valid() {
+		return nil, ErrInvalidOverflowPolicy
+	}
+
+	if opts.Overflow == "" {
+		opts.Overflow = OverflowReject
+	}
+
+	if opts.OverflowTimeout <= 0 {
+		opts.OverflowTimeout = defaultOverflowTimeout
+	}
+
 	q := &Queue{
-		log:       log,
-		db:        db,
-		LiveQueue: map[string][]Message{},
-		DeadQueue: map[string][]Message{},
+		log:    log,
+		db:     db,
+		topics: map[string]*topic{},
+		opts:   opts,
 	}
 
 	if err := q.loadQueue(); err != nil {
@@ -43,59 +87,634 @@ func NewQueue(log *logrus.Logger, db storage.DatabaseIF) (ImqQueueIF, error) {
 		return nil, err
 	}
 
-	q.log.Infof("QUEUE: %v", q.LiveQueue)
+	if err := q.replayLog(); err != nil {
+		q.log.Errorf("failed to replay queue log: %v", err)
+		return nil, err
+	}
+
+	if err := q.checkpoint(); err != nil {
+		q.log.Errorf("failed to checkpoint queue log: %v", err)
+		return nil, err
+	}
+
+	q.log.Infof("QUEUE: loaded %v topic(s)", len(q.topics))
 
 	if err := q.clearQueueFromDb(); err != nil {
 		q.log.Errorf("failed to clear queue table: %v", err)
 		return nil, err
 	}
 
+	go q.redeliver()
+
 	return q, nil
 }
 
-// SendMessage push message to the queue
+// SendMessage push message to the queue, the message is written to the log before it is accepted
 func (q *Queue) SendMessage(ctx context.Context, request SendMessageRequest) error {
 	if request.TopicID == "" {
-		return errors.New("you are not register to any topics")
+		return ErrNoTopic
+	}
+
+	return q.sendAll(ctx, request.TopicID, []Message{request.Message})[0]
+}
+
+// SendMessages pushes every message of a batch to the queue, each message is accepted or refused on
+// its own and the error at an index holds the result of the message at the same index
+func (q *Queue) SendMessages(ctx context.Context, topicID string, messages []Message) []error {
+	if topicID == "" {
+		errs := make([]error, len(messages))
+		for i := range errs {
+			errs[i] = ErrNoTopic
+		}
+		return errs
+	}
+
+	return q.sendAll(ctx, topicID, messages)
+}
+
+// sendAll routes every message to a partition of the topic and pushes the messages of each partition in order,
+// the overflow timeout of the call is shared by the partitions
+func (q *Queue) sendAll(ctx context.Context, topicID string, messages []Message) []error {
+	errs := make([]error, len(messages))
+	deadline := time.Now().Add(q.opts.OverflowTimeout)
+
+	partitions := []int{}
+	batches := map[int][]int{}
+	for i, msg := range messages {
+		p := q.route(topicID, msg.Key)
+		if _, ok := batches[p]; !ok {
+			partitions = append(partitions, p)
+		}
+		batches[p] = append(batches[p], i)
+	}
+
+	for _, p := range partitions {
+		q.sendPartition(ctx, topicID, p, messages, batches[p], errs, deadline)
+	}
+
+	return errs
+}
+
+// sendPartition pushes the messages at the given indexes to the partition in order and sets their result in errs.
+// Under OverflowBlock a message that does not fit waits for room until the deadline, the messages accepted so far
+// are pushed to the watching subscribers first so they can make room
+func (q *Queue) sendPartition(ctx context.Context, topicID string, partition int, messages []Message, indexes []int, errs []error, deadline time.Time) {
+	t := q.lockPartition(topicID, partition)
+	defer func() {
+		q.unlockPartition(t)
+	}()
+
+	accepted := false
+	for _, i := range indexes {
+		errs[i] = q.send(t, messages[i])
+		for errs[i] == ErrQueueFull && q.limits(t).Overflow == OverflowBlock && time.Now().Before(deadline) && ctx.Err() == nil {
+			if accepted {
+				q.notifyWatchers(t)
+				accepted = false
+			}
+
+			t = q.waitForRoom(ctx, t, deadline)
+			errs[i] = q.send(t, messages[i])
+		}
+
+		if errs[i] == nil {
+			accepted = true
+		}
+	}
+
+	if accepted {
+		q.notifyWatchers(t)
+	}
+}
+
+// send appends the message to the partition once it is written to the log, applying the overflow policy
+// when the partition is full
+func (q *Queue) send(t *topicQueue, msg Message) error {
+	if msg.MessageID == "" || len(msg.Data) == 0 {
+		return ErrEmptyMessage
+	}
+
+	limits := q.limits(t)
+
+	if limits.MaxBytes > 0 && len(msg.Data) > limits.MaxBytes {
+		return ErrMessageTooLarge
 	}
 
-	if request.Message.MessageID == "" || request.Message.Data == "" {
-		return errors.New("message cannot be empty")
+	if !q.hasRoom(t, msg) {
+		q.compact(t)
+
+		for limits.Overflow == OverflowDropOldest && !q.hasRoom(t, msg) {
+			q.dropOldest(t)
+		}
+
+		if !q.hasRoom(t, msg) {
+			return ErrQueueFull
+		}
 	}
-	
-	length := len(request.Message.Data) 
 
-	fmt.Println(length)
+	msg.Offset = t.nextOffset
+
+	if err := q.appendLog(logRecord{Type: recordPublish, TopicID: t.id, Partition: t.partition, Message: &msg}); err != nil {
+		q.log.WithField("topicId", t.id).Errorf("send: failed to write message to log: %v", err)
+		return ErrPersistFailed
+	}
 
-	q.LiveQueue[request.TopicID] = append(q.LiveQueue[request.TopicID], request.Message)
+	t.nextOffset++
 
-	q.log.Infof("QUEUE: %v", q.LiveQueue)
+	pushMessage(t, msg)
 
 	return nil
 }
 
-// RetrieveMessage pull message from the queue
-func (q *Queue) RetrieveMessage(ctx context.Context, topicID string) (*Message, error) {
-	for {
-		msg, err := peekMessage(q.LiveQueue[topicID])
+// waitForRoom hands the partition back and waits until messages are dropped from it, the deadline passes
+// or ctx is done, the partition is returned locked again
+func (q *Queue) waitForRoom(ctx context.Context, t *topicQueue, deadline time.Time) *topicQueue {
+	if t.room == nil {
+		t.room = make(chan struct{})
+	}
+	room := t.room
+
+	q.unlockPartition(t)
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case <-room:
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	return q.lockPartition(t.id, t.partition)
+}
+
+// RetrieveMessage pull the next unread message of the subscriber from the partitions of the topic it reads. The
+// message stays invisible to the subscriber until it is acknowledged or its visibility timeout elapses
+func (q *Queue) RetrieveMessage(ctx context.Context, topicID string, subscriberID int) (*Message, error) {
+	for _, p := range q.readOrder(topicID) {
+		messages, _, _ := q.retrieve(topicID, p, subscriberID, 1, 0, []Message{}, 0)
+		if len(messages) > 0 {
+			return &messages[0], nil
+		}
+	}
+
+	return nil, ErrQueueEmpty
+}
+
+// RetrieveMessages pulls up to maxCount unread messages of the subscriber from the partitions of the topic it reads,
+// stopping before the data of the messages grows over maxBytes when it is set. The first message is always returned
+// so a message larger than maxBytes cannot hold up the subscriber
+func (q *Queue) RetrieveMessages(ctx context.Context, topicID string, subscriberID int, maxCount int, maxBytes int) ([]Message, error) {
+	messages := []Message{}
+	size := 0
+	for _, p := range q.readOrder(topicID) {
+		full := false
+		messages, size, full = q.retrieve(topicID, p, subscriberID, maxCount, maxBytes, messages, size)
+		if full || len(messages) >= maxCount {
+			break
+		}
+	}
+
+	if len(messages) == 0 {
+		return nil, ErrQueueEmpty
+	}
+
+	return messages, nil
+}
+
+// retrieve appends the unread messages of the subscriber held in the partition to messages until maxCount messages
+// are pulled or the data of the messages, size so far, would grow over maxBytes, in which case full is set
+func (q *Queue) retrieve(topicID string, partition int, subscriberID int, maxCount int, maxBytes int, messages []Message, size int) ([]Message, int, bool) {
+	t := q.lockPartition(topicID, partition)
+	defer q.unlockPartition(t)
+
+	if !reads(t, subscriberID) {
+		return messages, size, false
+	}
+
+	q.compact(t)
+
+	c := consumerOf(t, subscriberID)
+
+	full := false
+	for len(messages) < maxCount {
+		d, err := q.receive(t, c)
 		if err != nil {
-			q.log.Infof("QUEUE: %v", q.LiveQueue)
-			q.log.Infof("DLQ: %v", q.DeadQueue)
-			return nil, err
+			break
+		}
+
+		if maxBytes > 0 && len(messages) > 0 && size+len(d.message.Data) > maxBytes {
+			// the message is handed back visible without counting the attempt
+			d.attempts--
+			d.deadline = time.Time{}
+			full = true
+			break
+		}
+
+		msg := d.message
+		msg.DeliveryCount = d.attempts
+
+		messages = append(messages, msg)
+		size += len(msg.Data)
+	}
+
+	q.compact(t)
+
+	q.log.Infof("QUEUE %v/%v: %v", t.id, t.partition, t.messages)
+	q.log.Infof("DLQ %v/%v: %v", t.id, t.partition, t.dead)
+
+	return messages, size, full
+}
+
+// AckMessage marks the message as processed by the subscriber so it is never delivered to it again, a member of a
+// consumer group acknowledges the message for the whole group
+func (q *Queue) AckMessage(topicID string, subscriberID int, messageID string) error {
+	err := ErrNotAwaitingAck
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		c := consumerOf(t, subscriberID)
+
+		i := findDelivery(t, c, messageID)
+		if i < 0 {
+			return false
+		}
+
+		q.removeDelivery(t, c, i)
+		q.compact(t)
+
+		err = nil
+		return true
+	})
+
+	return err
+}
+
+// NackMessage makes the message visible to the subscriber again straight away, or moves it
+// to the DeadQueue when it has already been delivered the maximum number of times
+func (q *Queue) NackMessage(topicID string, subscriberID int, messageID string) error {
+	err := ErrNotAwaitingAck
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		c := consumerOf(t, subscriberID)
+
+		i := findDelivery(t, c, messageID)
+		if i < 0 {
+			return false
 		}
 
-		if isExpired(msg.ExpiresAt) {
-			pushToDeadMessage(msg, q.DeadQueue[topicID])
-			copy(q.LiveQueue[topicID][0:], q.LiveQueue[topicID][1:])
-			q.LiveQueue[topicID] = q.LiveQueue[topicID][:len(q.LiveQueue[topicID])-1]
+		d := t.pending[c][i]
+		if d.attempts >= q.opts.MaxDeliveries {
+			q.pushToDeadMessage(t, d.message, ReasonMaxDeliveries)
+			q.removeDelivery(t, c, i)
 		} else {
-			q.log.Infof("QUEUE: %v", q.LiveQueue)
-			q.log.Infof("DLQ: %v", q.DeadQueue)
-			return &msg, nil
+			d.deadline = time.Time{}
+		}
+
+		if fn, ok := t.watchers[subscriberID]; ok {
+			q.deliver(t, subscriberID, fn)
+		}
+
+		q.compact(t)
+
+		err = nil
+		return true
+	})
+
+	return err
+}
+
+// AddSubscriber starts the subscriber reading every partition of the topic from the next published message,
+// a subscriber already reading the topic keeps its place
+func (q *Queue) AddSubscriber(topicID string, subscriberID int) {
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		c := consumer{subscriberID: subscriberID}
+		if _, ok := t.cursors[c]; ok {
+			return false
+		}
+
+		t.cursors[c] = t.nextOffset
+
+		q.recordCommit(t, c)
+
+		return false
+	})
+}
+
+// RemoveSubscriber drops the read offset, unacknowledged messages, watcher and filter of the subscriber for the topic.
+// A member of a consumer group only leaves the group and its partitions are assigned to the other members, the group
+// keeps its read offset and unacknowledged messages for them until it is removed with RemoveGroup
+func (q *Queue) RemoveSubscriber(topicID string, subscriberID int) {
+	tp := q.getTopic(topicID)
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		delete(t.watchers, subscriberID)
+		delete(t.filters, subscriberID)
+
+		if _, ok := t.groups[subscriberID]; ok {
+			delete(t.groups, subscriberID)
+			return false
+		}
+
+		q.removeConsumer(t, consumer{subscriberID: subscriberID})
+
+		return false
+	})
+
+	for group, members := range tp.members {
+		if i := sort.SearchInts(members, subscriberID); i < len(members) && members[i] == subscriberID {
+			tp.members[group] = append(members[:i:i], members[i+1:]...)
+			q.rebalance(topicID, group, tp.members[group])
 		}
 	}
 }
 
+// Watch registers fn to be called with every unread message of the subscriber for the given topic,
+// replacing any previous registration of the subscriber. A pushed message still has to be acknowledged
+// and is pushed again once its visibility timeout elapses. fn must not block nor call back into the queue.
+func (q *Queue) Watch(topicID string, subscriberID int, fn func(Message) error) {
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		t.watchers[subscriberID] = fn
+
+		q.deliver(t, subscriberID, fn)
+		q.compact(t)
+
+		return false
+	})
+}
+
+// Unwatch removes the subscriber registration for the given topic
+func (q *Queue) Unwatch(topicID string, subscriberID int) {
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		delete(t.watchers, subscriberID)
+		return false
+	})
+}
+
+// SetFilter makes the subscriber read only the messages of the topic accepted by filter, the unread messages it
+// rejects are skipped and never delivered to the subscriber. A nil filter makes the subscriber read every message
+func (q *Queue) SetFilter(topicID string, subscriberID int, filter func(Message) bool) {
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		if filter == nil {
+			delete(t.filters, subscriberID)
+		} else {
+			t.filters[subscriberID] = filter
+		}
+		return false
+	})
+}
+
+// SetPartitions splits the topic into the given number of partitions when it is created. A topic never loses
+// partitions so a smaller number is ignored, the subscribers already reading the topic read the partitions
+// added to it from their oldest message
+func (q *Queue) SetPartitions(topicID string, partitions int) {
+	tp := q.getTopic(topicID)
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	if partitions <= q.partitionCount(topicID) {
+		return
+	}
+
+	q.getPartition(topicID, partitions-1)
+
+	for group, members := range tp.members {
+		q.rebalance(topicID, group, members)
+	}
+}
+
+// SetLimits sets how many messages and bytes every partition of the topic holds at most and the overflow policy
+// applied once a partition is full, the options of the queue are used for the limits left at zero. Publishers
+// blocked on a full partition are woken up to try again under the new limits
+func (q *Queue) SetLimits(topicID string, limits Limits) {
+	tp := q.getTopic(topicID)
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	q.topicsMu.Lock()
+	tp.limits = limits
+	q.topicsMu.Unlock()
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		t.limits = limits
+		signalRoom(t)
+
+		return false
+	})
+}
+
+// JoinGroup makes the subscriber read the topic as a member of the consumer group. The members of a group share
+// its read offset and unacknowledged messages so every message is delivered to one member of the group. Every
+// partition of the topic is assigned to a single member so the messages of a partition are read in order, unless
+// the group has more members than the topic has partitions, in which case the members sharing a partition compete
+// for its messages. The partitions are assigned again whenever a member joins or leaves, joining again as a member
+// of the group changes nothing. A group reads the topic from the next published message once its first member joins
+func (q *Queue) JoinGroup(topicID string, subscriberID int, group string) {
+	tp := q.getTopic(topicID)
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	members := tp.members[group]
+	i := sort.SearchInts(members, subscriberID)
+	if i < len(members) && members[i] == subscriberID {
+		return
+	}
+
+	members = append(members, 0)
+	copy(members[i+1:], members[i:])
+	members[i] = subscriberID
+	tp.members[group] = members
+
+	q.rebalance(topicID, group, members)
+}
+
+// RemoveGroup drops the read offset and unacknowledged messages of the consumer group for the topic
+// along with the members left in it
+func (q *Queue) RemoveGroup(topicID string, group string) {
+	tp := q.getTopic(topicID)
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	delete(tp.members, group)
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		for subscriberID, g := range t.groups {
+			if g == group {
+				delete(t.groups, subscriberID)
+				delete(t.watchers, subscriberID)
+			}
+		}
+		delete(t.owners, group)
+		delete(t.turns, group)
+
+		q.removeConsumer(t, consumer{group: group})
+
+		return false
+	})
+}
+
+// DescribeGroup returns how many messages of the topic the consumer group has not acknowledged and how many unexpired
+// messages it has not read yet, along with the offset it resumes reading every partition from and the members the
+// partition is assigned to
+func (q *Queue) DescribeGroup(topicID string, group string) GroupStats {
+	stats := GroupStats{Partitions: []GroupPartitionStats{}}
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		c := consumer{group: group}
+
+		partition := GroupPartitionStats{Partition: t.partition, Members: append([]int(nil), t.owners[group]...), Offset: t.nextOffset}
+		if offset, ok := t.cursors[c]; ok {
+			partition.Offset = resumeOffset(t, c)
+			partition.Pending = len(t.pending[c])
+			for _, m := range t.messages {
+				if m.Offset >= offset && !isExpired(m.ExpiresAt) {
+					partition.Lag++
+				}
+			}
+		}
+
+		stats.Pending += partition.Pending
+		stats.Lag += partition.Lag
+		stats.Partitions = append(stats.Partitions, partition)
+
+		return false
+	})
+
+	return stats
+}
+
+// ListDeadMessages returns the messages held in the DeadQueue of every partition of the topic in the order they were moved to it
+func (q *Queue) ListDeadMessages(topicID string) []DeadMessage {
+	messages := []DeadMessage{}
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		q.compact(t)
+
+		messages = append(messages, t.dead...)
+
+		return false
+	})
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].DeadAt.Before(messages[j].DeadAt)
+	})
+
+	return messages
+}
+
+// PurgeDeadMessages drops the message with the given id, or every message when messageID is empty,
+// from the DeadQueue of the topic and returns how many were dropped
+func (q *Queue) PurgeDeadMessages(topicID string, messageID string) int {
+	purged := 0
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		n := len(takeDeadMessages(t, messageID))
+		if n > 0 {
+			q.record(logRecord{Type: recordPurge, TopicID: t.id, Partition: t.partition, MessageID: messageID})
+		}
+
+		purged += n
+
+		return false
+	})
+
+	return purged
+}
+
+// ReplayDeadMessages moves the message with the given id, or every message when messageID is empty,
+// from the DeadQueue back to the end of the partition it was held in and returns how many were moved. Expired
+// messages are given their original time to live again, replayed messages are not held to the limits of the topic
+func (q *Queue) ReplayDeadMessages(topicID string, messageID string) int {
+	total := 0
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		replayed := takeDeadMessages(t, messageID)
+		if len(replayed) > 0 {
+			q.record(logRecord{Type: recordPurge, TopicID: t.id, Partition: t.partition, MessageID: messageID})
+		}
+
+		for _, d := range replayed {
+			msg := d.Message
+			if isExpired(msg.ExpiresAt) {
+				msg.ExpiresAt = time.Now().UTC().Add(msg.ExpiresAt.Sub(msg.CretedAt))
+			}
+
+			msg.Offset = t.nextOffset
+			t.nextOffset++
+
+			q.record(logRecord{Type: recordPublish, TopicID: t.id, Partition: t.partition, Message: &msg})
+
+			pushMessage(t, msg)
+		}
+
+		if len(replayed) > 0 {
+			q.notifyWatchers(t)
+		}
+
+		total += len(replayed)
+
+		return false
+	})
+
+	return total
+}
+
+// DescribeTopic returns how many partitions the topic has and how many messages are held in the queue
+// and the DeadQueue of all of them, along with the limits applied to the topic
+func (q *Queue) DescribeTopic(topicID string) TopicStats {
+	stats := TopicStats{}
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		q.compact(t)
+
+		limits := q.limits(t)
+		stats.MaxMessages = limits.MaxMessages
+		stats.MaxBytes = limits.MaxBytes
+		stats.Overflow = limits.Overflow
+
+		stats.Partitions++
+		stats.Depth += len(t.messages)
+		stats.Bytes += t.bytes
+		stats.DeadMessages += len(t.dead)
+
+		return false
+	})
+
+	return stats
+}
+
+// RemoveTopic drops every message, subscriber and watcher of the topic. Every partition is held while the topic
+// is dropped so no change to the topic is written to the log after it
+func (q *Queue) RemoveTopic(topicID string) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	q.topicsMu.Lock()
+	partitions := []*topicQueue{}
+	if tp, ok := q.topics[topicID]; ok {
+		partitions = append(partitions, tp.partitions...)
+	}
+	q.topicsMu.Unlock()
+
+	for _, t := range partitions {
+		t.mu.Lock()
+	}
+
+	q.record(logRecord{Type: recordRemoveTopic, TopicID: topicID})
+
+	q.removeTopic(topicID)
+
+	for _, t := range partitions {
+		t.removed = true
+
+		// publishers waiting for room look the topic up again
+		popMessages(t, len(t.messages))
+
+		t.mu.Unlock()
+	}
+}
+
 // BackUpQueue store the data from queue to db
 func (q *Queue) BackUpQueue(ctx context.Context) error {
 	done := make(chan struct{})
@@ -119,59 +738,791 @@ func (q *Queue) BackUpQueue(ctx context.Context) error {
 }
 
 func (q *Queue) loadQueue() error {
+	partitions, err := q.db.FetchTopicPartitions(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for topicID, n := range partitions {
+		if n > 0 {
+			q.getPartition(topicID, n-1)
+		}
+	}
+
+	limits, err := q.db.FetchTopicLimits(context.Background())
+	if err != nil {
+		return err
+	}
+
+	for topicID, l := range limits {
+		q.SetLimits(topicID, Limits{MaxMessages: l.MaxMessages, MaxBytes: l.MaxBytes, Overflow: OverflowPolicy(l.Overflow)})
+	}
+
 	liveQueue, err := q.db.FetchQueues(context.Background())
 	if err != nil {
-		return nil
+		return err
 	}
 
 	if len(liveQueue.Topic) > 0 {
 		for k, m := range liveQueue.Topic {
 			for _, msg := range m {
+				t := q.getPartition(k, msg.Partition)
+
 				mm := Message{
-					MessageID: msg.MessageID,
-					Data:      msg.Data,
-					CretedAt:  msg.CretedAt,
-					ExpiresAt: msg.ExpiresAt,
+					MessageID:   msg.MessageID,
+					Offset:      msg.Offset,
+					Key:         msg.Key,
+					ContentType: msg.ContentType,
+					Headers:     msg.Headers,
+					Data:        msg.Data,
+					CretedAt:    msg.CretedAt,
+					ExpiresAt:   msg.ExpiresAt,
+				}
+
+				// messages stored before offsets existed are numbered in the order they were loaded
+				if n := len(t.messages); n > 0 && mm.Offset <= t.messages[n-1].Offset {
+					mm.Offset = t.messages[n-1].Offset + 1
 				}
-				q.LiveQueue[k] = append(q.LiveQueue[k], mm)
+
+				pushMessage(t, mm)
+				t.nextOffset = mm.Offset + 1
 			}
 		}
 	}
 
-	return nil
-}
-
-func (q *Queue) saveQueue(ctx context.Context) error {
-	liveQueueData := getQueueData(q.LiveQueue)
-	if err := q.db.SaveQueues(ctx, &liveQueueData, true); err != nil {
+	deadQueue, err := q.db.FetchDeadQueues(context.Background())
+	if err != nil {
 		return err
 	}
 
-	deadQueueData := getQueueData(q.LiveQueue)
-	if err := q.db.SaveQueues(ctx, &deadQueueData, false); err != nil {
-		return err
+	for k, m := range deadQueue.Topic {
+		for _, msg := range m {
+			t := q.getPartition(k, msg.Partition)
+			t.dead = append(t.dead, DeadMessage{
+				Message: Message{
+					MessageID:   msg.MessageID,
+					Key:         msg.Key,
+					ContentType: msg.ContentType,
+					Headers:     msg.Headers,
+					Data:        msg.Data,
+					CretedAt:    msg.CretedAt,
+					ExpiresAt:   msg.ExpiresAt,
+				},
+				Reason: msg.Reason,
+				DeadAt: msg.DeadAt,
+			})
+		}
 	}
 
-	return nil
-}
-
-func (q *Queue) clearQueueFromDb() error {
-	err := q.db.RemoveMessagesFromQueue(context.Background())
+	offsets, err := q.db.FetchSubscriberOffsets(context.Background())
 	if err != nil {
 		return err
 	}
 
-	return nil
+	for _, o := range *offsets {
+		if t := q.getPartition(o.TopicID, o.Partition); o.Offset > t.nextOffset {
+			t.nextOffset = o.Offset
+		}
+	}
+
+	for _, o := range *offsets {
+		c := consumer{subscriberID: o.SubscriberID, group: o.Group}
+		if o.Offset < 0 {
+			// a subscription without a stored offset starts from the oldest message of every partition
+			for p := 0; p < q.partitionCount(o.TopicID); p++ {
+				cursor(q.getPartition(o.TopicID, p), c)
+			}
+			continue
+		}
+
+		q.getPartition(o.TopicID, o.Partition).cursors[c] = o.Offset
+	}
+
+	// the consumer groups resume from the offsets loaded above
+	for topicID := range partitions {
+		members, err := q.db.GetConsumerGroups(context.Background(), topicID)
+		if err != nil {
+			return err
+		}
+
+		for _, m := range members {
+			q.JoinGroup(topicID, m.SubscriberID, m.GroupName)
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) saveQueue(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	liveQueueData := getQueueData(q.topics)
+	if err := q.db.SaveQueues(ctx, &liveQueueData, true); err != nil {
+		return err
+	}
+
+	deadQueueData := getDeadQueueData(q.topics)
+	if err := q.db.SaveQueues(ctx, &deadQueueData, false); err != nil {
+		return err
+	}
+
+	offsets := getSubscriberOffsets(q.topics)
+	if err := q.db.SaveSubscriberOffsets(ctx, &offsets); err != nil {
+		return err
+	}
+
+	// the backup holds every change recorded so far
+	if q.opts.Log != nil {
+		if err := q.opts.Log.Compact(nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) clearQueueFromDb() error {
+	err := q.db.RemoveMessagesFromQueue(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = q.db.RemoveMessagesFromDLQ(context.Background())
+	if err != nil {
+		return err
+	}
+
+	err = q.db.RemoveSubscriberOffsets(context.Background())
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// getTopic returns the topic with the given id, creating it with a single partition when missing
+func (q *Queue) getTopic(topicID string) *topic {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	return q.topicOf(topicID)
+}
+
+// topicOf returns the topic with the given id, creating it with a single partition when missing. The caller holds topicsMu
+func (q *Queue) topicOf(topicID string) *topic {
+	tp, ok := q.topics[topicID]
+	if !ok {
+		tp = &topic{
+			id:      topicID,
+			members: map[string][]int{},
+		}
+		q.topics[topicID] = tp
+	}
+
+	if len(tp.partitions) == 0 {
+		tp.partitions = append(tp.partitions, newPartition(topicID, 0, tp.limits))
+	}
+
+	return tp
+}
+
+// getPartition returns the partition of the topic with the given id, creating the topic and its partitions up to the
+// given one when missing
+func (q *Queue) getPartition(topicID string, partition int) *topicQueue {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	tp := q.topicOf(topicID)
+	for len(tp.partitions) <= partition {
+		tp.partitions = append(tp.partitions, newPartition(topicID, len(tp.partitions), tp.limits))
+	}
+
+	return tp.partitions[partition]
+}
+
+func newPartition(topicID string, partition int, limits Limits) *topicQueue {
+	return &topicQueue{
+		id:        topicID,
+		partition: partition,
+		limits:    limits,
+		cursors:   map[consumer]int64{},
+		pending:   map[consumer][]*delivery{},
+		watchers:  map[int]func(Message) error{},
+		filters:   map[int]func(Message) bool{},
+		groups:    map[int]string{},
+		owners:    map[string][]int{},
+		turns:     map[string]int{},
+	}
+}
+
+// partitionCount returns the number of partitions of the topic with the given id
+func (q *Queue) partitionCount(topicID string) int {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	return len(q.topicOf(topicID).partitions)
+}
+
+// route returns the partition of the topic a message with the given key is published to. The messages sharing a key
+// always go to the same partition so they are read in the order they were published, the messages without a key are
+// spread over the partitions in turn
+func (q *Queue) route(topicID string, key string) int {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	tp := q.topicOf(topicID)
+	n := len(tp.partitions)
+
+	if key != "" {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return int(h.Sum32() % uint32(n))
+	}
+
+	p := tp.next % n
+	tp.next = (p + 1) % n
+
+	return p
+}
+
+// readOrder returns the partitions of the topic in the order they are read, every read starts one partition
+// further so the messages of the first partitions do not hold up the others
+func (q *Queue) readOrder(topicID string) []int {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	tp := q.topicOf(topicID)
+	n := len(tp.partitions)
+
+	start := tp.reads % n
+	tp.reads = (start + 1) % n
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = (start + i) % n
+	}
+
+	return order
+}
+
+// lockPartition returns the partition of the topic with the given id locked, creating it when missing.
+// Checkpoints and backups wait until the partition is handed back with unlockPartition
+func (q *Queue) lockPartition(topicID string, partition int) *topicQueue {
+	q.mu.RLock()
+
+	for {
+		t := q.getPartition(topicID, partition)
+
+		t.mu.Lock()
+		if !t.removed {
+			return t
+		}
+		t.mu.Unlock()
+	}
+}
+
+func (q *Queue) unlockPartition(t *topicQueue) {
+	t.mu.Unlock()
+	q.mu.RUnlock()
+}
+
+// eachPartition calls fn with every partition of the topic locked in turn until fn returns true
+func (q *Queue) eachPartition(topicID string, fn func(t *topicQueue) bool) {
+	for p := 0; p < q.partitionCount(topicID); p++ {
+		t := q.lockPartition(topicID, p)
+		done := fn(t)
+		q.unlockPartition(t)
+
+		if done {
+			return
+		}
+	}
+}
+
+// reads reports whether the subscriber reads the partition, a member of a consumer group only reads the partitions assigned to it
+func reads(t *topicQueue, subscriberID int) bool {
+	group, ok := t.groups[subscriberID]
+	if !ok {
+		return true
+	}
+
+	owners := t.owners[group]
+	i := sort.SearchInts(owners, subscriberID)
+	return i < len(owners) && owners[i] == subscriberID
+}
+
+// consumerOf returns the consumer the subscriber reads the topic as, which is its consumer group when it is a member of one
+func consumerOf(t *topicQueue, subscriberID int) consumer {
+	if group, ok := t.groups[subscriberID]; ok {
+		return consumer{group: group}
+	}
+	return consumer{subscriberID: subscriberID}
+}
+
+// cursor returns the next offset to be read by the consumer, a consumer
+// without one starts from the oldest message held for the topic
+func cursor(t *topicQueue, c consumer) int64 {
+	offset, ok := t.cursors[c]
+	if !ok {
+		offset = t.nextOffset
+		if len(t.messages) > 0 {
+			offset = t.messages[0].Offset
+		}
+		t.cursors[c] = offset
+	}
+
+	return offset
+}
+
+// resumeOffset returns the offset the consumer has to resume reading from, which is the oldest message it has not acknowledged yet
+func resumeOffset(t *topicQueue, c consumer) int64 {
+	offset := t.cursors[c]
+	for _, d := range t.pending[c] {
+		if d.message.Offset < offset {
+			offset = d.message.Offset
+		}
+	}
+	return offset
+}
+
+// nextMessage returns the first unexpired message at or after the consumer cursor accepted by the filter of the
+// subscriber, the cursor is moved past the messages the filter rejects and the move is committed. Consumer groups
+// are not filtered
+func (q *Queue) nextMessage(t *topicQueue, c consumer) (*Message, error) {
+	offset := cursor(t, c)
+
+	var filter func(Message) bool
+	if c.group == "" {
+		filter = t.filters[c.subscriberID]
+	}
+
+	skipped := false
+	defer func() {
+		if skipped {
+			q.recordCommit(t, c)
+		}
+	}()
+
+	if len(t.messages) > 0 {
+		i := offset - t.messages[0].Offset
+		if i < 0 {
+			i = 0
+		}
+
+		for ; i < int64(len(t.messages)); i++ {
+			if isExpired(t.messages[i].ExpiresAt) {
+				continue
+			}
+
+			if filter != nil && !filter(t.messages[i]) {
+				t.cursors[c] = t.messages[i].Offset + 1
+				skipped = true
+				continue
+			}
+
+			msg := t.messages[i]
+			return &msg, nil
+		}
+	}
+
+	return nil, ErrQueueEmpty
+}
+
+// receive hands the consumer its oldest message whose visibility timeout has elapsed, or else its next
+// unread message, and hides it from the consumer until the visibility timeout elapses again
+func (q *Queue) receive(t *topicQueue, c consumer) (*delivery, error) {
+	d := q.expiredDelivery(t, c)
+	if d == nil {
+		msg, err := q.nextMessage(t, c)
+		if err != nil {
+			return nil, err
+		}
+
+		t.cursors[c] = msg.Offset + 1
+
+		d = &delivery{message: *msg}
+		t.pending[c] = append(t.pending[c], d)
+	}
+
+	d.attempts++
+	d.deadline = time.Now().Add(q.opts.VisibilityTimeout)
+
+	return d, nil
+}
+
+// expiredDelivery returns the first unacknowledged message of the consumer whose visibility timeout
+// has elapsed, moving the ones that ran out of delivery attempts or expired to the DeadQueue
+func (q *Queue) expiredDelivery(t *topicQueue, c consumer) *delivery {
+	now := time.Now()
+
+	for i := 0; i < len(t.pending[c]); {
+		d := t.pending[c][i]
+		if d.deadline.After(now) {
+			i++
+			continue
+		}
+
+		if isExpired(d.message.ExpiresAt) {
+			q.pushToDeadMessage(t, d.message, ReasonExpired)
+			q.removeDelivery(t, c, i)
+			continue
+		}
+
+		if d.attempts >= q.opts.MaxDeliveries {
+			q.pushToDeadMessage(t, d.message, ReasonMaxDeliveries)
+			q.removeDelivery(t, c, i)
+			continue
+		}
+
+		return d
+	}
+
+	return nil
+}
+
+func findDelivery(t *topicQueue, c consumer, messageID string) int {
+	for i, d := range t.pending[c] {
+		if d.message.MessageID == messageID {
+			return i
+		}
+	}
+	return -1
+}
+
+func (q *Queue) removeDelivery(t *topicQueue, c consumer, i int) {
+	deliveries := t.pending[c]
+	t.pending[c] = append(deliveries[:i], deliveries[i+1:]...)
+
+	q.recordCommit(t, c)
+}
+
+// removeConsumer drops the read offset and unacknowledged messages of the consumer
+func (q *Queue) removeConsumer(t *topicQueue, c consumer) {
+	q.record(logRecord{Type: recordUnsubscribe, TopicID: t.id, Partition: t.partition, SubscriberID: c.subscriberID, Group: c.group})
+
+	delete(t.cursors, c)
+	delete(t.pending, c)
+
+	q.compact(t)
 }
 
-func getQueueData(queue map[string][]Message) []storage.StoreQueue {
+// deliver hands every visible message of the subscriber to fn until fn fails, the visible messages of a
+// consumer group are handed to the members the partition is assigned to instead
+func (q *Queue) deliver(t *topicQueue, subscriberID int, fn func(Message) error) {
+	if group, ok := t.groups[subscriberID]; ok {
+		q.deliverGroup(t, group)
+		return
+	}
+
+	for {
+		if err := q.push(t, subscriberID, fn); err != nil {
+			return
+		}
+	}
+}
+
+// deliverGroup hands every visible message of the consumer group to the watching members the partition is assigned
+// to, one message per member in turn, starting after the member a message was pushed to last. A member that fails
+// to take a message is skipped until another member takes one
+func (q *Queue) deliverGroup(t *topicQueue, group string) {
+	members := []int{}
+	for _, subscriberID := range t.owners[group] {
+		if _, ok := t.watchers[subscriberID]; ok {
+			members = append(members, subscriberID)
+		}
+	}
+
+	if len(members) == 0 {
+		return
+	}
+
+	i := sort.SearchInts(members, t.turns[group]+1)
+	for failed := 0; failed < len(members); i++ {
+		subscriberID := members[i%len(members)]
+
+		err := q.push(t, subscriberID, t.watchers[subscriberID])
+		if err == ErrQueueEmpty {
+			return
+		}
+
+		if err != nil {
+			failed++
+			continue
+		}
+
+		failed = 0
+		t.turns[group] = subscriberID
+	}
+}
+
+// rebalance assigns the partitions of the topic to the members of the consumer group in turn and pushes the visible
+// messages of every partition to its members. A partition is assigned to every member whose index matches it modulo
+// the number of partitions, so no member is left idle when the group has more members than the topic has partitions.
+// The messages of a partition a member has not acknowledged stay with the group and are delivered to the new members
+// of the partition once their visibility timeout elapses
+func (q *Queue) rebalance(topicID string, group string, members []int) {
+	partitions := q.partitionCount(topicID)
+
+	q.eachPartition(topicID, func(t *topicQueue) bool {
+		for _, subscriberID := range members {
+			t.groups[subscriberID] = group
+			delete(t.filters, subscriberID)
+		}
+
+		if len(members) == 0 {
+			delete(t.owners, group)
+			delete(t.turns, group)
+			return false
+		}
+
+		c := consumer{group: group}
+		if _, ok := t.cursors[c]; !ok {
+			t.cursors[c] = t.nextOffset
+			q.recordCommit(t, c)
+		}
+
+		owners := []int{members[t.partition%len(members)]}
+		for i := t.partition + partitions; i < len(members); i += partitions {
+			owners = append(owners, members[i])
+		}
+		t.owners[group] = owners
+
+		q.deliverGroup(t, group)
+		q.compact(t)
+
+		return false
+	})
+}
+
+// push hands the next visible message of the subscriber to fn, a message fn fails to take is left visible
+// without counting the attempt. ErrQueueEmpty is returned when no message is visible
+func (q *Queue) push(t *topicQueue, subscriberID int, fn func(Message) error) error {
+	d, err := q.receive(t, consumerOf(t, subscriberID))
+	if err != nil {
+		return err
+	}
+
+	msg := d.message
+	msg.DeliveryCount = d.attempts
+
+	if err := fn(msg); err != nil {
+		q.log.Warnf("failed to push message to subscriber %v: %v", subscriberID, err)
+		d.attempts--
+		d.deadline = time.Time{}
+		return err
+	}
+
+	return nil
+}
+
+// redeliver periodically pushes the messages whose visibility timeout elapsed to the watching subscribers
+// and checkpoints the log once it grows over maxLogSegments segments. Partitions are locked one at a time
+func (q *Queue) redeliver() {
+	ticker := time.NewTicker(redeliveryInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		q.topicsMu.Lock()
+		partitions := []*topicQueue{}
+		for _, tp := range q.topics {
+			partitions = append(partitions, tp.partitions...)
+		}
+		q.topicsMu.Unlock()
+
+		for _, t := range partitions {
+			q.mu.RLock()
+			t.mu.Lock()
+			if !t.removed {
+				for subscriberID, fn := range t.watchers {
+					q.deliver(t, subscriberID, fn)
+				}
+				q.compact(t)
+			}
+			q.unlockPartition(t)
+		}
+
+		if q.opts.Log != nil && q.opts.Log.Segments() > maxLogSegments {
+			q.mu.Lock()
+			if err := q.checkpoint(); err != nil {
+				q.log.Errorf("failed to checkpoint queue log: %v", err)
+			}
+			q.mu.Unlock()
+		}
+	}
+}
+
+func (q *Queue) removeTopic(topicID string) {
+	q.topicsMu.Lock()
+	defer q.topicsMu.Unlock()
+
+	delete(q.topics, topicID)
+}
+
+// compact drops the expired messages and the ones already read and acknowledged by every subscriber from the head of the topic
+func (q *Queue) compact(t *topicQueue) {
+	messages := t.messages
+
+	minOffset, hasCursors := int64(0), false
+	for _, offset := range t.cursors {
+		if !hasCursors || offset < minOffset {
+			minOffset, hasCursors = offset, true
+		}
+	}
+
+	for _, deliveries := range t.pending {
+		for _, d := range deliveries {
+			if !hasCursors || d.message.Offset < minOffset {
+				minOffset, hasCursors = d.message.Offset, true
+			}
+		}
+	}
+
+	n := 0
+	for ; n < len(messages); n++ {
+		if isExpired(messages[n].ExpiresAt) {
+			q.pushToDeadMessage(t, messages[n], ReasonExpired)
+			continue
+		}
+
+		if !hasCursors || messages[n].Offset >= minOffset {
+			break
+		}
+	}
+
+	if n > 0 {
+		popMessages(t, n)
+	}
+}
+
+// hasRoom reports whether msg fits in the partition without going over the limits of the topic
+func (q *Queue) hasRoom(t *topicQueue, msg Message) bool {
+	limits := q.limits(t)
+
+	if limits.MaxMessages > 0 && len(t.messages) >= limits.MaxMessages {
+		return false
+	}
+
+	return limits.MaxBytes <= 0 || t.bytes+len(msg.Data) <= limits.MaxBytes
+}
+
+// limits returns the limits of the partition, falling back to the options of the queue for what the topic does not set
+func (q *Queue) limits(t *topicQueue) Limits {
+	limits := t.limits
+
+	if limits.MaxMessages <= 0 {
+		limits.MaxMessages = q.opts.MaxMessages
+	}
+
+	if limits.MaxBytes <= 0 {
+		limits.MaxBytes = q.opts.MaxBytes
+	}
+
+	if limits.Overflow == "" {
+		limits.Overflow = q.opts.Overflow
+	}
+
+	return limits
+}
+
+// dropOldest moves the oldest message of the topic to the DeadQueue to make room for a newer one,
+// the subscribers that have not read or acknowledged it yet skip it
+func (q *Queue) dropOldest(t *topicQueue) {
+	msg := t.messages[0]
+
+	q.pushToDeadMessage(t, msg, ReasonOverflow)
+	q.record(logRecord{Type: recordDrop, TopicID: t.id, Partition: t.partition, Offset: msg.Offset})
+
+	for c := range t.pending {
+		if i := findDelivery(t, c, msg.MessageID); i >= 0 {
+			q.removeDelivery(t, c, i)
+		}
+	}
+
+	popMessages(t, 1)
+}
+
+func pushMessage(t *topicQueue, msg Message) {
+	t.messages = append(t.messages, msg)
+	t.bytes += len(msg.Data)
+}
+
+// popMessages drops the first n messages of the topic by moving the slice forward so no message is
+// copied, and wakes the publishers waiting for room
+func popMessages(t *topicQueue, n int) {
+	for i := 0; i < n; i++ {
+		t.bytes -= len(t.messages[i].Data)
+		// the dropped messages are cleared so their data can be collected before the slice is reallocated
+		t.messages[i] = Message{}
+	}
+	t.messages = t.messages[n:]
+
+	signalRoom(t)
+}
+
+// signalRoom wakes the publishers waiting for room in the topic
+func signalRoom(t *topicQueue) {
+	if t.room != nil {
+		close(t.room)
+		t.room = nil
+	}
+}
+
+func (q *Queue) notifyWatchers(t *topicQueue) {
+	for subscriberID, fn := range t.watchers {
+		q.deliver(t, subscriberID, fn)
+	}
+
+	q.compact(t)
+}
+
+// takeDeadMessages removes the matching messages from the DeadQueue of the topic and returns them
+func takeDeadMessages(t *topicQueue, messageID string) []DeadMessage {
+	taken, kept := []DeadMessage{}, []DeadMessage{}
+
+	for _, d := range t.dead {
+		if messageID == "" || d.MessageID == messageID {
+			taken = append(taken, d)
+		} else {
+			kept = append(kept, d)
+		}
+	}
+
+	if len(kept) == 0 {
+		kept = nil
+	}
+	t.dead = kept
+
+	return taken
+}
+
+func (q *Queue) pushToDeadMessage(t *topicQueue, msg Message, reason string) {
+	if isDead(t, msg.MessageID) {
+		return
+	}
+
+	msg.DeliveryCount = 0
+	deadAt := time.Now().UTC()
+
+	q.record(logRecord{Type: recordDead, TopicID: t.id, Partition: t.partition, Message: &msg, Reason: reason, DeadAt: deadAt})
+
+	t.dead = append(t.dead, DeadMessage{
+		Message: msg,
+		Reason:  reason,
+		DeadAt:  deadAt,
+	})
+}
+
+func getQueueData(topics map[string]*topic) []storage.StoreQueue {
 	data := []storage.StoreQueue{}
-	for topicID, messages := range queue {
-		for _, m := range messages {
+	for _, t := range allPartitions(topics) {
+		for _, m := range t.messages {
 			msg := storage.StoreQueue{
 				QueuID:    uuid.New().String(),
-				TopicID:   topicID,
+				TopicID:   t.id,
+				Partition: t.partition,
 				MessageID: m.MessageID,
+				Offset:    m.Offset,
 			}
 			data = append(data, msg)
 		}
@@ -179,33 +1530,52 @@ func getQueueData(queue map[string][]Message) []storage.StoreQueue {
 	return data
 }
 
-func pushToDeadMessage(msg Message, msgs []Message) {
-	msgs = append(msgs, msg)
-}
-
-func peekMessage(msg []Message) (Message, error) {
-	if len(msg) <= 0 {
-		return Message{}, errors.New("no message present in queue")
+func getDeadQueueData(topics map[string]*topic) []storage.StoreQueue {
+	data := []storage.StoreQueue{}
+	for _, t := range allPartitions(topics) {
+		for _, m := range t.dead {
+			msg := storage.StoreQueue{
+				QueuID:    uuid.New().String(),
+				TopicID:   t.id,
+				Partition: t.partition,
+				MessageID: m.MessageID,
+				Reason:    m.Reason,
+				DeadAt:    m.DeadAt,
+			}
+			data = append(data, msg)
+		}
 	}
-	return msg[0], nil
+	return data
 }
 
-func isExpired(t string) bool {
-	expTime := getEpochTime(t)
-	curTime := getCurrentTime()
-	if curTime > expTime {
-		return true
+// getSubscriberOffsets returns the offset each subscriber and consumer group has to resume reading every partition
+// from, which is the oldest message of the partition it has not acknowledged yet
+func getSubscriberOffsets(topics map[string]*topic) []storage.SubscriberOffset {
+	data := []storage.SubscriberOffset{}
+	for _, t := range allPartitions(topics) {
+		for c := range t.cursors {
+			data = append(data, storage.SubscriberOffset{
+				SubscriberID: c.subscriberID,
+				Group:        c.group,
+				TopicID:      t.id,
+				Partition:    t.partition,
+				Offset:       resumeOffset(t, c),
+			})
+		}
 	}
-	return false
+	return data
 }
 
-func getEpochTime(t string) int64 {
-	thetime, _ := time.Parse("2006-01-02 15:04:05", t)
-	return thetime.Unix()
+// allPartitions returns the partitions of every topic, the caller holds the queue for writing
+func allPartitions(topics map[string]*topic) []*topicQueue {
+	partitions := []*topicQueue{}
+	for _, tp := range topics {
+		partitions = append(partitions, tp.partitions...)
+	}
+	return partitions
 }
 
-func getCurrentTime() int64 {
-	now := time.Now().UTC().Format("2006-01-02 15:04:05")
-	thetime, _ := time.Parse("2006-01-02 15:04:05", now)
-	return thetime.Unix()
+// isExpired reports whether the message expiring at t has expired, the zero time never expires
+func isExpired(t time.Time) bool {
+	return !t.IsZero() && time.Now().After(t)
 }
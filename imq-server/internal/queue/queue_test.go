@@ -3,11 +3,17 @@ package queue_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/queue"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/wal"
 	"github.com/WinnersonKharsunai/GraduationProject/server/test"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/mock"
@@ -19,9 +25,9 @@ func TestSendMessage_EmptyTopicId_Fail(t *testing.T) {
 		TopicID: "",
 		Message: queue.Message{
 			MessageID: "message1",
-			Data:      "test data 1",
-			CretedAt:  "2021-02-27 20:03:09",
-			ExpiresAt: "2021-02-27 20:04:09",
+			Data:      []byte("test data 1"),
+			CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+			ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 		},
 	}
 
@@ -29,9 +35,15 @@ func TestSendMessage_EmptyTopicId_Fail(t *testing.T) {
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
@@ -52,9 +64,15 @@ func TestSendMessage_EmptyMessage_Fail(t *testing.T) {
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
@@ -71,17 +89,23 @@ func TestSendMessage_Pass(t *testing.T) {
 		TopicID: "12345",
 		Message: queue.Message{
 			MessageID: "message1",
-			Data:      "test data 1",
-			CretedAt:  "2021-02-27 20:03:09",
-			ExpiresAt: "2021-02-27 20:04:09",
+			Data:      []byte("test data 1"),
+			CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+			ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 		},
 	}
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
@@ -100,14 +124,20 @@ func TestRetrieveMessage_Fail(t *testing.T) {
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
 
-	_, err = q.RetrieveMessage(context.Background(), topicID)
+	_, err = q.RetrieveMessage(context.Background(), topicID, 6000)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
 	}
@@ -118,29 +148,1402 @@ func TestRetrieveMessage_Pass(t *testing.T) {
 	topicID := "golang123"
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
 
-	_, err = q.RetrieveMessage(context.Background(), topicID)
+	_, err = q.RetrieveMessage(context.Background(), topicID, 6000)
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
 }
 
+func TestWatch_Pass(t *testing.T) {
+	queueData := getQueue()
+	msg := queue.SendMessageRequest{
+		TopicID: "python123",
+		Message: queue.Message{
+			MessageID: "message3",
+			Data:      []byte("test data 3"),
+			CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+			ExpiresAt: time.Date(2099, 2, 27, 20, 4, 9, 0, time.UTC),
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	var received []queue.Message
+	q.Watch(msg.TopicID, 6000, func(m queue.Message) error {
+		received = append(received, m)
+		return nil
+	})
+
+	if err := q.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if len(received) != 1 || received[0].MessageID != msg.Message.MessageID {
+		t.Fatalf("\nexpected: %v \n\t got: %v", msg.Message, received)
+	}
+
+	q.Unwatch(msg.TopicID, 6000)
+
+	if err := q.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if len(received) != 1 {
+		t.Fatalf("\nexpected: 1 message \n\t got: %v", received)
+	}
+}
+
+func TestRetrieveMessage_EverySubscriberReceivesEveryMessage(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+		{
+			SubscriberID: 6001,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	for _, subscriberID := range []int{6000, 6001} {
+		msg, err := q.RetrieveMessage(context.Background(), topicID, subscriberID)
+		if err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+
+		if msg.MessageID != "message2" {
+			t.Fatalf("\nexpected: message2 \n\t got: %v", msg.MessageID)
+		}
+
+		_, err = q.RetrieveMessage(context.Background(), topicID, subscriberID)
+		if err == nil {
+			t.Fatalf("\nexpected: no message present in queue \n\t got: nil")
+		}
+	}
+}
+
+func TestRetrieveMessage_NewSubscriberStartsAtNextMessage(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+	msg := queue.SendMessageRequest{
+		TopicID: topicID,
+		Message: queue.Message{
+			MessageID: "message3",
+			Data:      []byte("test data 3"),
+			CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+			ExpiresAt: time.Date(2099, 2, 27, 20, 4, 9, 0, time.UTC),
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	if err := q.SendMessage(context.Background(), msg); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	got, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != msg.Message.MessageID {
+		t.Fatalf("\nexpected: %v \n\t got: %v", msg.Message.MessageID, got.MessageID)
+	}
+}
+
+func TestAddSubscriber_KeepsPlacePass(t *testing.T) {
+	topicID := "golang123"
+	first := queue.SendMessageRequest{
+		TopicID: topicID,
+		Message: queue.Message{MessageID: "message1", Data: []byte("test data 1")},
+	}
+	second := queue.SendMessageRequest{
+		TopicID: topicID,
+		Message: queue.Message{MessageID: "message2", Data: []byte("test data 2")},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	if err := q.SendMessage(context.Background(), first); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	if err := q.SendMessage(context.Background(), second); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	got, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != first.Message.MessageID {
+		t.Fatalf("\nexpected: %v \n\t got: %v", first.Message.MessageID, got.MessageID)
+	}
+}
+
+func TestSetFilter_Pass(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+	q.AddSubscriber(topicID, 7000)
+	q.SetFilter(topicID, 6000, func(msg queue.Message) bool { return msg.Headers["region"] == "eu" })
+
+	for _, region := range []string{"us", "eu", "us"} {
+		msg := queue.Message{MessageID: "message-" + region, Headers: map[string]string{"region": region}, Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	got, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != "message-eu" {
+		t.Fatalf("\nexpected: %v \n\t got: %v", "message-eu", got.MessageID)
+	}
+
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err != queue.ErrQueueEmpty {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrQueueEmpty, err)
+	}
+
+	// the other subscriber is not filtered
+	msgs, err := q.RetrieveMessages(context.Background(), topicID, 7000, 10, 0)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if len(msgs) != 3 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 3, len(msgs))
+	}
+}
+
+func TestJoinGroup_Pass(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.SetPartitions(topicID, 2)
+	q.JoinGroup(topicID, 6000, "billing")
+	q.JoinGroup(topicID, 7000, "billing")
+	q.JoinGroup(topicID, 8000, "audit")
+
+	pushed := map[int][]string{}
+	for _, subscriberID := range []int{6000, 7000} {
+		id := subscriberID
+		q.Watch(topicID, id, func(msg queue.Message) error {
+			pushed[id] = append(pushed[id], msg.MessageID)
+			return nil
+		})
+	}
+
+	for i := 0; i < 4; i++ {
+		msg := queue.Message{MessageID: fmt.Sprintf("message%v", i), Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	// every member reads its own partition
+	if len(pushed[6000]) != 2 || len(pushed[7000]) != 2 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", "two messages per member", pushed)
+	}
+
+	seen := map[string]bool{}
+	for _, id := range append(pushed[6000], pushed[7000]...) {
+		if seen[id] {
+			t.Fatalf("\nexpected: %v \n\t got: %v", "every message pushed once", pushed)
+		}
+		seen[id] = true
+	}
+
+	expected := queue.GroupStats{
+		Pending: 4,
+		Partitions: []queue.GroupPartitionStats{
+			{Partition: 0, Members: []int{6000}, Offset: 0, Pending: 2},
+			{Partition: 1, Members: []int{7000}, Offset: 0, Pending: 2},
+		},
+	}
+
+	if stats := q.DescribeGroup(topicID, "billing"); !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expected, stats)
+	}
+
+	// a member acknowledges for the whole group
+	if err := q.AckMessage(topicID, 7000, pushed[6000][0]); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	// another group reads every message
+	msgs, err := q.RetrieveMessages(context.Background(), topicID, 8000, 10, 0)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if len(msgs) != 4 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 4, len(msgs))
+	}
+
+	expected = queue.GroupStats{
+		Pending: 4,
+		Partitions: []queue.GroupPartitionStats{
+			{Partition: 0, Members: []int{8000}, Offset: 0, Pending: 2},
+			{Partition: 1, Members: []int{8000}, Offset: 0, Pending: 2},
+		},
+	}
+
+	if stats := q.DescribeGroup(topicID, "audit"); !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expected, stats)
+	}
+
+	// a member leaving does not drop the group
+	q.RemoveSubscriber(topicID, 8000)
+
+	if stats := q.DescribeGroup(topicID, "audit"); stats.Pending != 4 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 4, stats.Pending)
+	}
+
+	q.RemoveGroup(topicID, "audit")
+
+	expected = queue.GroupStats{
+		Partitions: []queue.GroupPartitionStats{
+			{Partition: 0, Offset: 2},
+			{Partition: 1, Offset: 2},
+		},
+	}
+
+	if stats := q.DescribeGroup(topicID, "audit"); !reflect.DeepEqual(expected, stats) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expected, stats)
+	}
+}
+
+func TestJoinGroup_ReplaysLogAfterCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	topicID := "java123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	log, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.JoinGroup(topicID, 6000, "billing")
+
+	for _, id := range []string{"message1", "message2"} {
+		msg := queue.Message{MessageID: id, Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if err := q.AckMessage(topicID, 6000, msg.MessageID); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	log.Close()
+
+	log, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer log.Close()
+
+	q, err = queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	// another member resumes from the offset of the group
+	q.JoinGroup(topicID, 7000, "billing")
+
+	msg, err = q.RetrieveMessage(context.Background(), topicID, 7000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if msg.MessageID != "message2" {
+		t.Fatalf("\nexpected: %v \n\t got: %v", "message2", msg.MessageID)
+	}
+}
+
+func TestSendMessage_KeyKeepsOrderAcrossPartitions(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{topicID: 4}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, mock.Anything).Return([]storage.ConsumerGroupMember{}, nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if stats := q.DescribeTopic(topicID); stats.Partitions != 4 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 4, stats.Partitions)
+	}
+
+	q.JoinGroup(topicID, 6000, "billing")
+	q.JoinGroup(topicID, 7000, "billing")
+
+	received := map[int][]queue.Message{}
+	for _, subscriberID := range []int{6000, 7000} {
+		id := subscriberID
+		q.Watch(topicID, id, func(msg queue.Message) error {
+			received[id] = append(received[id], msg)
+			return nil
+		})
+	}
+
+	keys := []string{"order1", "order2", "order3", "order4"}
+	for i := 0; i < 20; i++ {
+		msg := queue.Message{MessageID: fmt.Sprintf("message%02d", i), Key: keys[i%len(keys)], Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	// the messages sharing a key are read by a single member in the order they were published
+	readers := map[string]int{}
+	last := map[string]string{}
+	for subscriberID, msgs := range received {
+		for _, msg := range msgs {
+			if reader, ok := readers[msg.Key]; ok && reader != subscriberID {
+				t.Fatalf("\nexpected: %v \n\t got: %v", "one member per key", received)
+			}
+			readers[msg.Key] = subscriberID
+
+			if msg.MessageID < last[msg.Key] {
+				t.Fatalf("\nexpected: %v \n\t got: %v", "messages of a key in order", received)
+			}
+			last[msg.Key] = msg.MessageID
+		}
+	}
+
+	if len(received[6000])+len(received[7000]) != 20 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 20, len(received[6000])+len(received[7000]))
+	}
+}
+
+func TestJoinGroup_RebalancesPartitions(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	owners := func() []int {
+		ids := []int{}
+		for _, p := range q.DescribeGroup(topicID, "billing").Partitions {
+			ids = append(ids, p.Members...)
+		}
+		return ids
+	}
+
+	q.SetPartitions(topicID, 2)
+	q.JoinGroup(topicID, 7000, "billing")
+
+	if got := owners(); !reflect.DeepEqual([]int{7000, 7000}, got) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{7000, 7000}, got)
+	}
+
+	q.JoinGroup(topicID, 6000, "billing")
+
+	if got := owners(); !reflect.DeepEqual([]int{6000, 7000}, got) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{6000, 7000}, got)
+	}
+
+	// a topic never loses partitions
+	q.SetPartitions(topicID, 3)
+	q.SetPartitions(topicID, 1)
+
+	if got := owners(); !reflect.DeepEqual([]int{6000, 7000, 6000}, got) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{6000, 7000, 6000}, got)
+	}
+
+	q.RemoveSubscriber(topicID, 6000)
+
+	if got := owners(); !reflect.DeepEqual([]int{7000, 7000, 7000}, got) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{7000, 7000, 7000}, got)
+	}
+
+	// the messages of a partition go to its new owner
+	msg := queue.Message{MessageID: "message1", Key: "order1", Data: []byte("test data")}
+	if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	got, err := q.RetrieveMessage(context.Background(), topicID, 7000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != "message1" {
+		t.Fatalf("\nexpected: %v \n\t got: %v", "message1", got.MessageID)
+	}
+}
+
+func TestJoinGroup_MoreMembersThanPartitions(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.JoinGroup(topicID, 6000, "billing")
+	q.JoinGroup(topicID, 7000, "billing")
+
+	pushed := map[int][]string{}
+	for _, subscriberID := range []int{6000, 7000} {
+		id := subscriberID
+		q.Watch(topicID, id, func(msg queue.Message) error {
+			pushed[id] = append(pushed[id], msg.MessageID)
+			return nil
+		})
+	}
+
+	for i := 0; i < 4; i++ {
+		msg := queue.Message{MessageID: fmt.Sprintf("message%v", i), Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	// the members sharing the single partition take its messages in turn
+	expected := map[int][]string{6000: {"message0", "message2"}, 7000: {"message1", "message3"}}
+	if !reflect.DeepEqual(expected, pushed) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expected, pushed)
+	}
+
+	stats := q.DescribeGroup(topicID, "billing")
+	if len(stats.Partitions) != 1 || !reflect.DeepEqual([]int{6000, 7000}, stats.Partitions[0].Members) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{6000, 7000}, stats.Partitions)
+	}
+
+	// a third member shares the first of two partitions
+	q.SetPartitions(topicID, 2)
+	q.JoinGroup(topicID, 8000, "billing")
+
+	members := [][]int{}
+	for _, p := range q.DescribeGroup(topicID, "billing").Partitions {
+		members = append(members, p.Members)
+	}
+
+	if !reflect.DeepEqual([][]int{{6000, 8000}, {7000}}, members) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", [][]int{{6000, 8000}, {7000}}, members)
+	}
+}
+
+func TestNewQueue_RestoresGroupMembers(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, topicID, storage.Subscription{GroupName: "billing"})
+	db.InsertIntoSubscriberTopicMap(context.Background(), 7000, topicID, storage.Subscription{GroupName: "billing"})
+
+	q, err := queue.NewQueue(&logrus.Logger{}, db, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	stats := q.DescribeGroup(topicID, "billing")
+	if len(stats.Partitions) != 1 || !reflect.DeepEqual([]int{6000, 7000}, stats.Partitions[0].Members) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", []int{6000, 7000}, stats.Partitions)
+	}
+
+	msg := queue.Message{MessageID: "message1", Data: []byte("test data")}
+	if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	// the members read with the offset of the group without joining it again
+	if got, err := q.RetrieveMessage(context.Background(), topicID, 7000); err != nil || got.MessageID != "message1" {
+		t.Fatalf("\nexpected: message1 \n\t got: %v %v", got, err)
+	}
+
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); !errors.Is(err, queue.ErrQueueEmpty) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrQueueEmpty, err)
+	}
+}
+
+func TestRetrieveMessage_LoadedOffset(t *testing.T) {
+	now := time.Now().UTC()
+	queueData := storage.Queue{
+		Topic: map[string][]storage.Message{
+			"java123": {
+				{
+					MessageID: "message1",
+					Offset:    4,
+					Data:      []byte("test data 1"),
+					CretedAt:  now,
+					ExpiresAt: now.Add(time.Duration(time.Second * 60)),
+				},
+				{
+					MessageID: "message2",
+					Offset:    5,
+					Data:      []byte("test data 2"),
+					CretedAt:  now,
+					ExpiresAt: now.Add(time.Duration(time.Second * 60)),
+				},
+			},
+		},
+	}
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      "java123",
+			Offset:       5,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	got, err := q.RetrieveMessage(context.Background(), "java123", 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if got.MessageID != "message2" {
+		t.Fatalf("\nexpected: message2 \n\t got: %v", got.MessageID)
+	}
+}
+
+func TestAckMessage_Pass(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{VisibilityTimeout: time.Millisecond})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if err := q.AckMessage(topicID, 6000, msg.MessageID); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	time.Sleep(time.Millisecond * 5)
+
+	expectedErr := errors.New("no message present in queue")
+
+	_, err = q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	expectedErr = errors.New("message is not awaiting acknowledgement")
+
+	err = q.AckMessage(topicID, 6000, msg.MessageID)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestRetrieveMessage_RedeliveredAfterVisibilityTimeout(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{VisibilityTimeout: time.Millisecond * 10})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	first, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err == nil {
+		t.Fatalf("\nexpected: no message present in queue \n\t got: nil")
+	}
+
+	time.Sleep(time.Millisecond * 20)
+
+	second, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if second.MessageID != first.MessageID || second.DeliveryCount != 2 {
+		t.Fatalf("\nexpected: %v (2) \n\t got: %v (%v)", first.MessageID, second.MessageID, second.DeliveryCount)
+	}
+}
+
+func TestNackMessage_MaxDeliveries_MovedToDeadQueue(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxDeliveries: 2})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+		if err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+
+		if msg.DeliveryCount != attempt {
+			t.Fatalf("\nexpected: %v \n\t got: %v", attempt, msg.DeliveryCount)
+		}
+
+		if err := q.NackMessage(topicID, 6000, msg.MessageID); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err == nil {
+		t.Fatalf("\nexpected: no message present in queue \n\t got: nil")
+	}
+
+	dead := q.ListDeadMessages(topicID)
+	if len(dead) != 1 || dead[0].MessageID != "message2" || dead[0].Reason != queue.ReasonMaxDeliveries {
+		t.Fatalf("\nexpected: [message2] \n\t got: %v", dead)
+	}
+}
+
+func TestListDeadMessages_ExpiredMessage(t *testing.T) {
+	queueData := storage.Queue{
+		Topic: map[string][]storage.Message{
+			"java123": {
+				{
+					MessageID: "message1",
+					Data:      []byte("test data 1"),
+					CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+					ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	dead := q.ListDeadMessages("java123")
+	if len(dead) != 1 || dead[0].MessageID != "message1" || dead[0].Reason != queue.ReasonExpired || dead[0].DeadAt.IsZero() {
+		t.Fatalf("\nexpected: [message1 expired] \n\t got: %v", dead)
+	}
+}
+
+func TestRetrieveMessage_NeverExpires(t *testing.T) {
+	topicID := "java123"
+	queueData := storage.Queue{
+		Topic: map[string][]storage.Message{
+			topicID: {
+				{
+					MessageID: "message1",
+					Data:      []byte("test data 1"),
+					CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+				},
+			},
+		},
+	}
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if dead := q.ListDeadMessages(topicID); len(dead) != 0 {
+		t.Fatalf("\nexpected: [] \n\t got: %v", dead)
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if msg.MessageID != "message1" {
+		t.Fatalf("\nexpected: message1 \n\t got: %v", msg.MessageID)
+	}
+}
+
+func TestReplayDeadMessages_Pass(t *testing.T) {
+	topicID := "java123"
+	deadQueue := storage.DeadQueue{
+		Topic: map[string][]storage.DeadMessage{
+			topicID: {
+				{
+					MessageID: "message1",
+					Data:      []byte("test data 1"),
+					CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+					ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
+					Reason:    queue.ReasonExpired,
+					DeadAt:    time.Date(2021, 2, 27, 20, 4, 10, 0, time.UTC),
+				},
+			},
+		},
+	}
+	offsets := []storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      topicID,
+			Offset:       -1,
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&deadQueue, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&offsets, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if n := q.ReplayDeadMessages(topicID, ""); n != 1 {
+		t.Fatalf("\nexpected: 1 \n\t got: %v", n)
+	}
+
+	if dead := q.ListDeadMessages(topicID); len(dead) != 0 {
+		t.Fatalf("\nexpected: [] \n\t got: %v", dead)
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if msg.MessageID != "message1" {
+		t.Fatalf("\nexpected: message1 \n\t got: %v", msg.MessageID)
+	}
+}
+
+func TestPurgeDeadMessages_Pass(t *testing.T) {
+	topicID := "java123"
+	deadQueue := storage.DeadQueue{
+		Topic: map[string][]storage.DeadMessage{
+			topicID: {
+				{
+					MessageID: "message1",
+					Reason:    queue.ReasonExpired,
+				},
+				{
+					MessageID: "message2",
+					Reason:    queue.ReasonMaxDeliveries,
+				},
+			},
+		},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&deadQueue, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if n := q.PurgeDeadMessages(topicID, "message1"); n != 1 {
+		t.Fatalf("\nexpected: 1 \n\t got: %v", n)
+	}
+
+	dead := q.ListDeadMessages(topicID)
+	if len(dead) != 1 || dead[0].MessageID != "message2" {
+		t.Fatalf("\nexpected: [message2] \n\t got: %v", dead)
+	}
+}
+
+func TestNewQueue_ReplaysLogAfterCrash(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	topicID := "java123"
+	now := time.Now().UTC()
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	log, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	for _, id := range []string{"message1", "message2"} {
+		err := q.SendMessage(context.Background(), queue.SendMessageRequest{
+			TopicID: topicID,
+			Message: queue.Message{
+				MessageID: id,
+				Data:      []byte("test data"),
+				CretedAt:  now,
+				ExpiresAt: now.Add(time.Duration(time.Second * 60)),
+			},
+		})
+		if err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if err := q.AckMessage(topicID, 6000, msg.MessageID); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	log.Close()
+
+	log, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer log.Close()
+
+	q, err = queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	msg, err = q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if msg.MessageID != "message2" {
+		t.Fatalf("\nexpected: %v \n\t got: %v", "message2", msg.MessageID)
+	}
+}
+
+func TestNewQueue_ReplaysFilteredSkip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	topicID := "java123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	log, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+	q.SetFilter(topicID, 6000, func(msg queue.Message) bool { return msg.Headers["region"] == "eu" })
+
+	for _, id := range []string{"message1", "message2"} {
+		msg := queue.Message{MessageID: id, Headers: map[string]string{"region": "us"}, Data: []byte("test data")}
+		if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: topicID, Message: msg}); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err != queue.ErrQueueEmpty {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrQueueEmpty, err)
+	}
+
+	log.Close()
+
+	log, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer log.Close()
+
+	q, err = queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	// the skipped messages stay skipped once the subscriber reads without its filter
+	if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err != queue.ErrQueueEmpty {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrQueueEmpty, err)
+	}
+}
+
+func TestSendMessage_LogFailed_Fail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	queueData := getQueue()
+	msg := queue.SendMessageRequest{
+		TopicID: "12345",
+		Message: queue.Message{
+			MessageID: "message1",
+			Data:      []byte("test data 1"),
+			CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+			ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
+		},
+	}
+
+	expectedErr := errors.New("failed to persist message")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	log, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{Log: log})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	log.Close()
+
+	err = q.SendMessage(context.Background(), msg)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
+func TestSendMessages_PartialFail(t *testing.T) {
+	queueData := getQueue()
+	topicID := "12345"
+	expiresAt := time.Now().UTC().Add(time.Minute)
+	messages := []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1"), ExpiresAt: expiresAt},
+		{MessageID: "message2", ExpiresAt: expiresAt},
+		{MessageID: "message3", Data: []byte("test data 3"), ExpiresAt: expiresAt},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	errs := q.SendMessages(context.Background(), topicID, messages)
+	if len(errs) != 3 || errs[0] != nil || !errors.Is(errs[1], queue.ErrEmptyMessage) || errs[2] != nil {
+		t.Fatalf("\nexpected: [nil %v nil] \n\t got: %v", queue.ErrEmptyMessage, errs)
+	}
+
+	got, err := q.RetrieveMessages(context.Background(), topicID, 6000, 10, 0)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if len(got) != 2 || got[0].MessageID != "message1" || got[1].MessageID != "message3" {
+		t.Fatalf("\nexpected: [message1 message3] \n\t got: %v", got)
+	}
+}
+
+func TestRetrieveMessages_MaxBytes(t *testing.T) {
+	queueData := getQueue()
+	topicID := "12345"
+	expiresAt := time.Now().UTC().Add(time.Minute)
+	messages := []queue.Message{
+		{MessageID: "message1", Data: []byte("0123456789"), ExpiresAt: expiresAt},
+		{MessageID: "message2", Data: []byte("0123456789"), ExpiresAt: expiresAt},
+		{MessageID: "message3", Data: []byte("0123456789"), ExpiresAt: expiresAt},
+	}
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+	q.SendMessages(context.Background(), topicID, messages)
+
+	got, err := q.RetrieveMessages(context.Background(), topicID, 6000, 10, 25)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("\nexpected: 2 messages \n\t got: %v %v", got, err)
+	}
+
+	got, err = q.RetrieveMessages(context.Background(), topicID, 6000, 10, 5)
+	if err != nil || len(got) != 1 || got[0].MessageID != "message3" || got[0].DeliveryCount != 1 {
+		t.Fatalf("\nexpected: message3 delivered once \n\t got: %v %v", got, err)
+	}
+
+	_, err = q.RetrieveMessages(context.Background(), topicID, 6000, 10, 0)
+	if !errors.Is(err, queue.ErrQueueEmpty) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrQueueEmpty, err)
+	}
+}
+
+func TestRemoveTopic_Pass(t *testing.T) {
+	queueData := getQueue()
+	topicID := "java123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	stats := q.DescribeTopic(topicID)
+	if stats.Depth != 1 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", 1, stats.Depth)
+	}
+
+	q.RemoveTopic(topicID)
+
+	stats = q.DescribeTopic(topicID)
+	if stats.Depth != 0 || stats.DeadMessages != 0 {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.TopicStats{}, stats)
+	}
+
+	expectedErr := errors.New("no message present in queue")
+
+	_, err = q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+}
+
 func TestBackUpQueue_Pass(t *testing.T) {
 	queueData := getQueue()
 
 	mockDb := &test.MockDatabaseIF{}
 	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&queueData, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
 	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
 	mockDb.Given(storage.DatabaseIF.SaveQueues).When(mock.Anything, mock.Anything, true).Return(nil)
 	mockDb.Given(storage.DatabaseIF.SaveQueues).When(mock.Anything, mock.Anything, false).Return(nil)
+	mockDb.Given(storage.DatabaseIF.SaveSubscriberOffsets).When(mock.Anything, mock.Anything).Return(nil)
 
-	q, err := queue.NewQueue(&logrus.Logger{}, mockDb)
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
 	if err != nil {
 		t.Fatalf("\nexpected: nil \n\t got: %v", err)
 	}
@@ -151,6 +1554,639 @@ func TestBackUpQueue_Pass(t *testing.T) {
 	}
 }
 
+func TestBackUpQueue_PatternSubscriberRestartPass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"orders.eu"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "orders.eu")
+	db.InsertIntoSubscriberPatternMap(context.Background(), 6000, "orders.*")
+
+	q, err := queue.NewQueue(&logrus.Logger{}, db, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+	for _, messageID := range []string{"m1", "m2"} {
+		msg := queue.SendMessageRequest{
+			TopicID: topicID,
+			Message: queue.Message{MessageID: messageID, Data: []byte(messageID), CretedAt: time.Now(), ExpiresAt: time.Now().Add(time.Hour)},
+		}
+		if err := q.SendMessage(context.Background(), msg); err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+
+		db.InsertMessageIntoMessage(context.Background(), 5000, topicID, storage.Message{MessageID: messageID, Data: msg.Message.Data, CretedAt: msg.Message.CretedAt, ExpiresAt: msg.Message.ExpiresAt})
+	}
+
+	if err := q.BackUpQueue(context.Background()); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q, err = queue.NewQueue(&logrus.Logger{}, db, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	// the pattern subscription is matched again on the next publish to the topic
+	q.AddSubscriber(topicID, 6000)
+
+	got, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil || got.MessageID != "m1" {
+		t.Fatalf("\nexpected: m1 \n\t got: %v %v", got, err)
+	}
+}
+
+func TestNewQueue_FetchTopicPartitionsFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch partitions")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int(nil), expectedErr)
+
+	// the stored queue is kept when it cannot be loaded
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveMessagesFromQueue", mock.Anything)
+}
+
+func TestNewQueue_FetchTopicLimitsFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch limits")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits(nil), expectedErr)
+
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveMessagesFromQueue", mock.Anything)
+}
+
+func TestNewQueue_FetchQueuesFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch queues")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return((*storage.Queue)(nil), expectedErr)
+
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveMessagesFromQueue", mock.Anything)
+}
+
+func TestNewQueue_FetchDeadQueuesFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch dead queues")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return((*storage.DeadQueue)(nil), expectedErr)
+
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveMessagesFromDLQ", mock.Anything)
+}
+
+func TestNewQueue_FetchSubscriberOffsetsFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch offsets")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return((*[]storage.SubscriberOffset)(nil), expectedErr)
+
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveSubscriberOffsets", mock.Anything)
+}
+
+func TestNewQueue_GetConsumerGroupsFail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch consumer groups")
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{"golang123": 1}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, "golang123").Return([]storage.ConsumerGroupMember(nil), expectedErr)
+
+	_, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != expectedErr {
+		t.Fatalf("\nexpected: %v \n\t got: %v", expectedErr, err)
+	}
+
+	mockDb.AssertNotCalled(t, "RemoveSubscriberOffsets", mock.Anything)
+}
+
+func TestNewQueue_InvalidOverflowPolicyFail(t *testing.T) {
+	_, err := queue.NewQueue(&logrus.Logger{}, &test.MockDatabaseIF{}, queue.Options{Overflow: "dropNewest"})
+	if !errors.Is(err, queue.ErrInvalidOverflowPolicy) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrInvalidOverflowPolicy, err)
+	}
+}
+
+func TestSendMessage_QueueFull_RejectFail(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxMessages: 2})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	errs := q.SendMessages(context.Background(), topicID, []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2", Data: []byte("test data 2")},
+		{MessageID: "message3", Data: []byte("test data 3")},
+	})
+	if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], queue.ErrQueueFull) {
+		t.Fatalf("\nexpected: [<nil> <nil> %v] \n\t got: %v", queue.ErrQueueFull, errs)
+	}
+
+	stats := q.DescribeTopic(topicID)
+	if stats.Depth != 2 || stats.Bytes != 22 || stats.MaxMessages != 2 || stats.Overflow != queue.OverflowReject {
+		t.Fatalf("\nexpected: {Depth:2 Bytes:22 MaxMessages:2 Overflow:reject} \n\t got: %+v", stats)
+	}
+}
+
+func TestSetLimits_PerTopic(t *testing.T) {
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{
+		"java123": {MaxMessages: 1, Overflow: "dropOldest"},
+	}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxMessages: 2})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	messages := []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2", Data: []byte("test data 2")},
+		{MessageID: "message3", Data: []byte("test data 3")},
+	}
+
+	// a topic without limits of its own falls back to the options
+	errs := q.SendMessages(context.Background(), "golang123", messages)
+	if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], queue.ErrQueueFull) {
+		t.Fatalf("\nexpected: [<nil> <nil> %v] \n\t got: %v", queue.ErrQueueFull, errs)
+	}
+
+	// the limits loaded for a topic replace the options
+	errs = q.SendMessages(context.Background(), "java123", messages)
+	if errs[0] != nil || errs[1] != nil || errs[2] != nil {
+		t.Fatalf("\nexpected: [<nil> <nil> <nil>] \n\t got: %v", errs)
+	}
+
+	stats := q.DescribeTopic("java123")
+	if stats.Depth != 1 || stats.DeadMessages != 2 || stats.MaxMessages != 1 || stats.Overflow != queue.OverflowDropOldest {
+		t.Fatalf("\nexpected: {Depth:1 DeadMessages:2 MaxMessages:1 Overflow:dropOldest} \n\t got: %+v", stats)
+	}
+
+	q.SetLimits("golang123", queue.Limits{MaxMessages: 3})
+
+	if err := q.SendMessage(context.Background(), queue.SendMessageRequest{TopicID: "golang123", Message: messages[2]}); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	stats = q.DescribeTopic("golang123")
+	if stats.Depth != 3 || stats.MaxMessages != 3 || stats.Overflow != queue.OverflowReject {
+		t.Fatalf("\nexpected: {Depth:3 MaxMessages:3 Overflow:reject} \n\t got: %+v", stats)
+	}
+}
+
+func TestSendMessages_LimitsPerPartition(t *testing.T) {
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{"orders123": 2}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.GetConsumerGroups).When(mock.Anything, mock.Anything).Return([]storage.ConsumerGroupMember{}, nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxMessages: 1})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	messages := []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2", Data: []byte("test data 2")},
+		{MessageID: "message3", Data: []byte("test data 3")},
+	}
+
+	// every partition holds up to the limit, so the topic holds up to twice as many messages
+	errs := q.SendMessages(context.Background(), "orders123", messages)
+	if errs[0] != nil || errs[1] != nil || !errors.Is(errs[2], queue.ErrQueueFull) {
+		t.Fatalf("\nexpected: [<nil> <nil> %v] \n\t got: %v", queue.ErrQueueFull, errs)
+	}
+
+	stats := q.DescribeTopic("orders123")
+	if stats.Partitions != 2 || stats.Depth != 2 || stats.MaxMessages != 1 {
+		t.Fatalf("\nexpected: {Partitions:2 Depth:2 MaxMessages:1} \n\t got: %+v", stats)
+	}
+}
+
+func TestSendMessage_MessageTooLargeFail(t *testing.T) {
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxBytes: 4, Overflow: queue.OverflowDropOldest})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	err = q.SendMessage(context.Background(), queue.SendMessageRequest{
+		TopicID: "golang123",
+		Message: queue.Message{MessageID: "message1", Data: []byte("test data 1")},
+	})
+	if !errors.Is(err, queue.ErrMessageTooLarge) {
+		t.Fatalf("\nexpected: %v \n\t got: %v", queue.ErrMessageTooLarge, err)
+	}
+}
+
+func TestSendMessage_QueueFull_DropOldest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "queue")
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	log, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	opts := queue.Options{MaxBytes: 22, Overflow: queue.OverflowDropOldest}
+	opts.Log = log
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, opts)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	for _, id := range []string{"message1", "message2", "message3"} {
+		err := q.SendMessage(context.Background(), queue.SendMessageRequest{
+			TopicID: topicID,
+			Message: queue.Message{MessageID: id, Data: []byte("test data 1")},
+		})
+		if err != nil {
+			t.Fatalf("\nexpected: nil \n\t got: %v", err)
+		}
+	}
+
+	dead := q.ListDeadMessages(topicID)
+	if len(dead) != 1 || dead[0].MessageID != "message1" || dead[0].Reason != queue.ReasonOverflow {
+		t.Fatalf("\nexpected: [message1 %v] \n\t got: %v", queue.ReasonOverflow, dead)
+	}
+
+	log.Close()
+
+	log, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+	defer log.Close()
+
+	opts.Log = log
+	q, err = queue.NewQueue(&logrus.Logger{}, mockDb, opts)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if stats := q.DescribeTopic(topicID); stats.Depth != 2 || stats.DeadMessages != 1 {
+		t.Fatalf("\nexpected: {Depth:2 DeadMessages:1} \n\t got: %+v", stats)
+	}
+
+	msg, err := q.RetrieveMessage(context.Background(), topicID, 6000)
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if msg.MessageID != "message2" {
+		t.Fatalf("\nexpected: message2 \n\t got: %v", msg.MessageID)
+	}
+}
+
+func TestSendMessage_QueueFull_BlockUntilRoom(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxMessages: 1, Overflow: queue.OverflowBlock, OverflowTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	msg1 := queue.SendMessageRequest{TopicID: topicID, Message: queue.Message{MessageID: "message1", Data: []byte("test data 1")}}
+	if err := q.SendMessage(context.Background(), msg1); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		if _, err := q.RetrieveMessage(context.Background(), topicID, 6000); err == nil {
+			q.AckMessage(topicID, 6000, "message1")
+		}
+	}()
+
+	start := time.Now()
+	msg2 := queue.SendMessageRequest{TopicID: topicID, Message: queue.Message{MessageID: "message2", Data: []byte("test data 2")}}
+	if err := q.SendMessage(context.Background(), msg2); err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond || elapsed > 5*time.Second {
+		t.Fatalf("\nexpected: blocked until message1 was acknowledged \n\t got: %v", elapsed)
+	}
+}
+
+func TestSendMessage_QueueFull_BlockTimeoutFail(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{MaxMessages: 1, Overflow: queue.OverflowBlock, OverflowTimeout: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	errs := q.SendMessages(context.Background(), topicID, []queue.Message{
+		{MessageID: "message1", Data: []byte("test data 1")},
+		{MessageID: "message2", Data: []byte("test data 2")},
+	})
+	if errs[0] != nil || !errors.Is(errs[1], queue.ErrQueueFull) {
+		t.Fatalf("\nexpected: [<nil> %v] \n\t got: %v", queue.ErrQueueFull, errs)
+	}
+}
+
+func TestQueue_ConcurrentPublishConsume(t *testing.T) {
+	topics := []string{"golang123", "java123", "python123", "rust123"}
+	subscribers := []int{6000, 6001}
+	publishers, perPublisher := 4, 50
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.SaveQueues).When(mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.SaveSubscriberOffsets).When(mock.Anything, mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	for _, topicID := range topics {
+		for _, subscriberID := range subscribers {
+			q.AddSubscriber(topicID, subscriberID)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for _, topicID := range topics {
+		for p := 0; p < publishers; p++ {
+			wg.Add(1)
+			go func(topicID string, p int) {
+				defer wg.Done()
+				for i := 0; i < perPublisher; i++ {
+					err := q.SendMessage(context.Background(), queue.SendMessageRequest{
+						TopicID: topicID,
+						Message: queue.Message{MessageID: fmt.Sprintf("%v-%v-%v", topicID, p, i), Data: []byte("test data")},
+					})
+					if err != nil {
+						t.Errorf("\nexpected: nil \n\t got: %v", err)
+						return
+					}
+				}
+			}(topicID, p)
+		}
+	}
+
+	received := map[string]map[int]map[string]int{}
+	receivedMu := sync.Mutex{}
+	for _, topicID := range topics {
+		received[topicID] = map[int]map[string]int{}
+		for _, subscriberID := range subscribers {
+			received[topicID][subscriberID] = map[string]int{}
+		}
+	}
+
+	for _, topicID := range topics {
+		for _, subscriberID := range subscribers {
+			wg.Add(1)
+			go func(topicID string, subscriberID int) {
+				defer wg.Done()
+				deadline := time.Now().Add(10 * time.Second)
+				for n := 0; n < publishers*perPublisher && time.Now().Before(deadline); {
+					msg, err := q.RetrieveMessage(context.Background(), topicID, subscriberID)
+					if errors.Is(err, queue.ErrQueueEmpty) {
+						continue
+					}
+					if err != nil {
+						t.Errorf("\nexpected: nil \n\t got: %v", err)
+						return
+					}
+
+					if err := q.AckMessage(topicID, subscriberID, msg.MessageID); err != nil {
+						t.Errorf("\nexpected: nil \n\t got: %v", err)
+						return
+					}
+
+					receivedMu.Lock()
+					received[topicID][subscriberID][msg.MessageID]++
+					receivedMu.Unlock()
+					n++
+				}
+			}(topicID, subscriberID)
+		}
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			if err := q.BackUpQueue(context.Background()); err != nil {
+				t.Errorf("\nexpected: nil \n\t got: %v", err)
+			}
+			q.DescribeTopic(topics[i%len(topics)])
+		}
+	}()
+
+	wg.Wait()
+
+	for _, topicID := range topics {
+		for _, subscriberID := range subscribers {
+			got := received[topicID][subscriberID]
+			if len(got) != publishers*perPublisher {
+				t.Fatalf("\nexpected: %v messages \n\t got: %v", publishers*perPublisher, len(got))
+			}
+
+			for messageID, count := range got {
+				if count != 1 {
+					t.Fatalf("\nexpected: %v received once \n\t got: %v", messageID, count)
+				}
+			}
+		}
+
+		if stats := q.DescribeTopic(topicID); stats.Depth != 0 {
+			t.Fatalf("\nexpected: 0 \n\t got: %v", stats.Depth)
+		}
+	}
+}
+
+func TestQueue_ConcurrentWatchAndRemoveTopic(t *testing.T) {
+	topicID := "golang123"
+
+	mockDb := &test.MockDatabaseIF{}
+	mockDb.Given(storage.DatabaseIF.FetchQueues).When(mock.Anything).Return(&storage.Queue{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicPartitions).When(mock.Anything).Return(map[string]int{}, nil)
+	mockDb.Given(storage.DatabaseIF.FetchTopicLimits).When(mock.Anything).Return(map[string]storage.TopicLimits{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromQueue).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchDeadQueues).When(mock.Anything).Return(&storage.DeadQueue{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveMessagesFromDLQ).When(mock.Anything).Return(nil)
+	mockDb.Given(storage.DatabaseIF.FetchSubscriberOffsets).When(mock.Anything).Return(&[]storage.SubscriberOffset{}, nil)
+	mockDb.Given(storage.DatabaseIF.RemoveSubscriberOffsets).When(mock.Anything).Return(nil)
+
+	q, err := queue.NewQueue(&logrus.Logger{}, mockDb, queue.Options{})
+	if err != nil {
+		t.Fatalf("\nexpected: nil \n\t got: %v", err)
+	}
+
+	q.AddSubscriber(topicID, 6000)
+
+	pushed := make(chan queue.Message, 100)
+	q.Watch(topicID, 6000, func(msg queue.Message) error {
+		select {
+		case pushed <- msg:
+			return nil
+		default:
+			return errors.New("subscriber is busy")
+		}
+	})
+
+	wg := sync.WaitGroup{}
+	wg.Add(3)
+
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			q.SendMessage(context.Background(), queue.SendMessageRequest{
+				TopicID: topicID,
+				Message: queue.Message{MessageID: fmt.Sprintf("message%v", i), Data: []byte("test data")},
+			})
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case msg := <-pushed:
+				q.AckMessage(topicID, 6000, msg.MessageID)
+			case <-timeout:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+		time.Sleep(10 * time.Millisecond)
+		q.RemoveTopic(topicID)
+		q.Unwatch(topicID, 6000)
+	}()
+
+	wg.Wait()
+
+	q.RemoveTopic(topicID)
+	if stats := q.DescribeTopic(topicID); stats.Depth != 0 || stats.DeadMessages != 0 {
+		t.Fatalf("\nexpected: {0 0} \n\t got: %v", stats)
+	}
+}
+
 func getQueue() storage.Queue {
 	now := time.Now().UTC()
 	return storage.Queue{
@@ -158,23 +2194,23 @@ func getQueue() storage.Queue {
 			"golang123": {
 				{
 					MessageID: "message1",
-					Data:      "test data 1",
-					CretedAt:  now.Format("2006-01-02 15:04:05"),
-					ExpiresAt: now.Add(time.Duration(time.Second * 60)).Format("2006-01-02 15:04:05"),
+					Data:      []byte("test data 1"),
+					CretedAt:  now,
+					ExpiresAt: now.Add(time.Duration(time.Second * 60)),
 				},
 				{
 					MessageID: "message1",
-					Data:      "test data 1",
-					CretedAt:  "2021-02-27 20:03:09",
-					ExpiresAt: "2021-02-27 20:04:09",
+					Data:      []byte("test data 1"),
+					CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+					ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 				},
 			},
 			"java123": {
 				{
 					MessageID: "message2",
-					Data:      "test data 2",
-					CretedAt:  now.Format("2006-01-02 15:04:05"),
-					ExpiresAt: now.Add(time.Duration(time.Second * 60)).Format("2006-01-02 15:04:05"),
+					Data:      []byte("test data 2"),
+					CretedAt:  now,
+					ExpiresAt: now.Add(time.Duration(time.Second * 60)),
 				},
 			},
 		},
@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// FileDB is the file-backed implementation of DatabaseIF, every change is written to a single json file
+type FileDB struct {
+	*MemoryDB
+	Path string
+}
+
+// NewFileDB creates a new DatabaseIF stored in the file at path with the given topics
+func NewFileDB(path string, topics []string) (DatabaseIF, error) {
+	f := &FileDB{
+		MemoryDB: &MemoryDB{data: newMemoryData()},
+		Path:     path,
+	}
+	f.persist = f.save
+
+	if err := f.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := f.addTopics(topics); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Connect loads the tables from the file, creating the file when missing
+func (f *FileDB) Connect() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(f.Path), 0755); err != nil {
+		return errors.Wrap(err, "could not create directory for db file")
+	}
+
+	content, err := ioutil.ReadFile(f.Path)
+	if os.IsNotExist(err) {
+		return f.save(&f.data)
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read db file")
+	}
+
+	data := newMemoryData()
+	if err := json.Unmarshal(content, &data); err != nil {
+		return errors.Wrap(err, "could not parse db file")
+	}
+
+	if data.Publishers == nil {
+		data.Publishers = map[int]bool{}
+	}
+	if data.Messages == nil {
+		data.Messages = map[string]messageRow{}
+	}
+	if data.Subscribers == nil {
+		data.Subscribers = map[int]bool{}
+	}
+
+	// files written before a publisher could connect to several topics keep a single topic per publisher
+	publisherIDs := []int{}
+	for publisherID := range data.LegacyPublishers {
+		publisherIDs = append(publisherIDs, publisherID)
+	}
+	sort.Ints(publisherIDs)
+
+	for _, publisherID := range publisherIDs {
+		topicID := data.LegacyPublishers[publisherID]
+		data.Publishers[publisherID] = true
+		if topicID != "" {
+			data.PublisherTopicMap = append(data.PublisherTopicMap, publisherTopicRow{PublisherID: publisherID, TopicID: topicID})
+		}
+	}
+	data.LegacyPublishers = nil
+
+	f.data = data
+
+	return nil
+}
+
+// Test checks the db file can still be read
+func (f *FileDB) Test() error {
+	if _, err := os.Stat(f.Path); err != nil {
+		return errors.Wrap(err, "could not access db file")
+	}
+	return nil
+}
+
+// save replaces the file with the given tables, the file is never left half written
+func (f *FileDB) save(data *memoryData) error {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	tmp := f.Path + ".tmp"
+
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, f.Path)
+}
@@ -0,0 +1,888 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// MemoryDB is the in-memory implementation of DatabaseIF, every table is held in memoryData
+type MemoryDB struct {
+	mu      sync.Mutex
+	data    memoryData
+	persist func(data *memoryData) error
+}
+
+// memoryData holds the rows of every table
+type memoryData struct {
+	Topics               []topicRow            `json:"topics"`
+	Publishers           map[int]bool          `json:"publisherIds"`
+	PublisherTopicMap    []publisherTopicRow   `json:"publisherTopicMap"`
+	Messages             map[string]messageRow `json:"messages"`
+	Subscribers          map[int]bool          `json:"subscribers"`
+	SubscriberTopicMap   []subscriberTopicRow  `json:"subscriberTopicMap"`
+	SubscriberPatternMap []SubscriptionPattern `json:"subscriberPatternMap"`
+	Queue                []StoreQueue          `json:"queue"`
+	DLQ                  []StoreQueue          `json:"dlq"`
+	SubscriberOffsets    []SubscriberOffset    `json:"subscriberOffsets"`
+	Clients              []Client              `json:"clients"`
+
+	// LegacyPublishers holds the single topic of every publisher in the files written before a publisher could
+	// connect to several topics, it is moved to PublisherTopicMap once the file is loaded
+	LegacyPublishers map[int]string `json:"publishers,omitempty"`
+}
+
+type topicRow struct {
+	TopicID    string `json:"topicId"`
+	Name       string `json:"name"`
+	DefaultTTL int    `json:"defaultTtl,omitempty"`
+	MaxTTL     int    `json:"maxTtl,omitempty"`
+	// Partitions is left out of the files written before topics had partitions, such topics have a single one
+	Partitions     int    `json:"partitions,omitempty"`
+	MaxMessages    int    `json:"maxMessages,omitempty"`
+	MaxBytes       int    `json:"maxBytes,omitempty"`
+	OverflowPolicy string `json:"overflowPolicy,omitempty"`
+}
+
+type messageRow struct {
+	Message
+	PublisherID int    `json:"publisherId"`
+	TopicID     string `json:"topicId"`
+}
+
+type publisherTopicRow struct {
+	PublisherID int    `json:"publisherId"`
+	TopicID     string `json:"topicId"`
+}
+
+type subscriberTopicRow struct {
+	SubscriberID int    `json:"subscriberId"`
+	TopicID      string `json:"topicId"`
+	Filter       string `json:"filter,omitempty"`
+	GroupName    string `json:"groupName,omitempty"`
+}
+
+// firstClientID matches the AUTO_INCREMENT start of the Client table
+const firstClientID = 1000
+
+// NewMemoryDB creates a new DatabaseIF held in memory with the given topics
+func NewMemoryDB(topics []string) (DatabaseIF, error) {
+	m := &MemoryDB{
+		data:    newMemoryData(),
+		persist: func(*memoryData) error { return nil },
+	}
+
+	if err := m.Connect(); err != nil {
+		return nil, err
+	}
+
+	if err := m.addTopics(topics); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func newMemoryData() memoryData {
+	return memoryData{
+		Publishers:  map[int]bool{},
+		Messages:    map[string]messageRow{},
+		Subscribers: map[int]bool{},
+	}
+}
+
+// Connect has nothing to connect to for the in-memory db
+func (m *MemoryDB) Connect() error {
+	return nil
+}
+
+// Test has nothing to test for the in-memory db
+func (m *MemoryDB) Test() error {
+	return nil
+}
+
+// FetchAllTopics fetches all the topics
+func (m *MemoryDB) FetchAllTopics(ctx context.Context, id int) (*[]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var topics []string
+	for _, t := range m.data.Topics {
+		topics = append(topics, t.Name)
+	}
+
+	return &topics, nil
+}
+
+// GetPublishedTopics fetches all the topics the publisher is connected to
+func (m *MemoryDB) GetPublishedTopics(ctx context.Context, publisherID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var topics []string
+	for _, p := range m.data.PublisherTopicMap {
+		if p.PublisherID == publisherID {
+			topics = append(topics, m.topicName(p.TopicID))
+		}
+	}
+
+	return topics, nil
+}
+
+// InsertPublisherIDIntoPublisher inserts new publisher if it does not exist yet
+func (m *MemoryDB) InsertPublisherIDIntoPublisher(ctx context.Context, publisherID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.Publishers[publisherID] {
+		return nil
+	}
+	m.data.Publishers[publisherID] = true
+
+	return m.persist(&m.data)
+}
+
+// InsertIntoPublisherTopicMap inserts publisher to topic mapping
+func (m *MemoryDB) InsertIntoPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.data.PublisherTopicMap {
+		if p.PublisherID == publisherID && p.TopicID == topicID {
+			return errors.Errorf("duplicate publisher %v for topic %v", publisherID, topicID)
+		}
+	}
+	m.data.PublisherTopicMap = append(m.data.PublisherTopicMap, publisherTopicRow{PublisherID: publisherID, TopicID: topicID})
+
+	return m.persist(&m.data)
+}
+
+// RemoveTopicIDFromPublisherTopicMap removes publisher to topic mapping
+func (m *MemoryDB) RemoveTopicIDFromPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.PublisherTopicMap = removePublisherTopic(m.data.PublisherTopicMap, func(p publisherTopicRow) bool {
+		return p.PublisherID == publisherID && p.TopicID == topicID
+	})
+
+	return m.persist(&m.data)
+}
+
+// GetTopicIDFromTopic gets topicId of the given topicName
+func (m *MemoryDB) GetTopicIDFromTopic(ctx context.Context, topicName string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.topicID(topicName), nil
+}
+
+// InsertMessageIntoMessage persists message info
+func (m *MemoryDB) InsertMessageIntoMessage(ctx context.Context, publisherID int, topicID string, message Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.data.Messages[message.MessageID]; ok {
+		return errors.Errorf("duplicate message %v", message.MessageID)
+	}
+	m.data.Messages[message.MessageID] = messageRow{Message: message, PublisherID: publisherID, TopicID: topicID}
+
+	return m.persist(&m.data)
+}
+
+// InsertMessagesIntoMessage persists the info of every message of a batch, none of them is stored when one is a duplicate
+func (m *MemoryDB) InsertMessagesIntoMessage(ctx context.Context, publisherID int, topicID string, messages []Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, message := range messages {
+		if _, ok := m.data.Messages[message.MessageID]; ok {
+			return errors.Errorf("duplicate message %v", message.MessageID)
+		}
+	}
+
+	for _, message := range messages {
+		m.data.Messages[message.MessageID] = messageRow{Message: message, PublisherID: publisherID, TopicID: topicID}
+	}
+
+	return m.persist(&m.data)
+}
+
+// GetSubscribedTopics fetches all the topics subscribed by client
+func (m *MemoryDB) GetSubscribedTopics(ctx context.Context, subscriberID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var topics []string
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.SubscriberID == subscriberID {
+			topics = append(topics, m.topicName(s.TopicID))
+		}
+	}
+
+	return topics, nil
+}
+
+// InsertSubscriberIDIntoSubscriber inserts new subscriber if it does not exist yet
+func (m *MemoryDB) InsertSubscriberIDIntoSubscriber(ctx context.Context, subscriberID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.data.Subscribers[subscriberID] {
+		return nil
+	}
+	m.data.Subscribers[subscriberID] = true
+
+	return m.persist(&m.data)
+}
+
+// InsertIntoSubscriberTopicMap inserts susbscriber to topic mapping along with the filter and consumer group of the subscription
+func (m *MemoryDB) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string, subscription Subscription) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.SubscriberTopicMap = append(m.data.SubscriberTopicMap, subscriberTopicRow{
+		SubscriberID: subscriberID,
+		TopicID:      topicID,
+		Filter:       subscription.Filter,
+		GroupName:    subscription.GroupName,
+	})
+
+	return m.persist(&m.data)
+}
+
+// GetSubscription gets the filter and consumer group of the subscription of the subscriber to the topic,
+// both are empty when the subscriber is not subscribed
+func (m *MemoryDB) GetSubscription(ctx context.Context, subscriberID int, topicID string) (*Subscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.SubscriberID == subscriberID && s.TopicID == topicID {
+			return &Subscription{Filter: s.Filter, GroupName: s.GroupName}, nil
+		}
+	}
+
+	return &Subscription{}, nil
+}
+
+// GetConsumerGroups gets the members of every consumer group reading the topic ordered by group
+func (m *MemoryDB) GetConsumerGroups(ctx context.Context, topicID string) ([]ConsumerGroupMember, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	members := []ConsumerGroupMember{}
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.TopicID == topicID && s.GroupName != "" {
+			members = append(members, ConsumerGroupMember{GroupName: s.GroupName, SubscriberID: s.SubscriberID})
+		}
+	}
+
+	sort.SliceStable(members, func(i, j int) bool {
+		if members[i].GroupName != members[j].GroupName {
+			return members[i].GroupName < members[j].GroupName
+		}
+		return members[i].SubscriberID < members[j].SubscriberID
+	})
+
+	return members, nil
+}
+
+// RemoveTopicIDFromSubscriberTopicMap removes susbscriber to topic mapping
+func (m *MemoryDB) RemoveTopicIDFromSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := []subscriberTopicRow{}
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.SubscriberID != subscriberID || s.TopicID != topicID {
+			rows = append(rows, s)
+		}
+	}
+	m.data.SubscriberTopicMap = rows
+
+	return m.persist(&m.data)
+}
+
+// GetSubscribedPatterns fetches all the subscription patterns of the subscriber
+func (m *MemoryDB) GetSubscribedPatterns(ctx context.Context, subscriberID int) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	patterns := []string{}
+	for _, p := range m.data.SubscriberPatternMap {
+		if p.SubscriberID == subscriberID {
+			patterns = append(patterns, p.Pattern)
+		}
+	}
+
+	return patterns, nil
+}
+
+// GetSubscriptionPatterns fetches the subscription patterns of every subscriber
+func (m *MemoryDB) GetSubscriptionPatterns(ctx context.Context) ([]SubscriptionPattern, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	patterns := make([]SubscriptionPattern, len(m.data.SubscriberPatternMap))
+	copy(patterns, m.data.SubscriberPatternMap)
+
+	return patterns, nil
+}
+
+// InsertIntoSubscriberPatternMap inserts subscriber to subscription pattern mapping
+func (m *MemoryDB) InsertIntoSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, p := range m.data.SubscriberPatternMap {
+		if p.SubscriberID == subscriberID && p.Pattern == pattern {
+			return errors.Errorf("duplicate subscriber %v for pattern %v", subscriberID, pattern)
+		}
+	}
+	m.data.SubscriberPatternMap = append(m.data.SubscriberPatternMap, SubscriptionPattern{SubscriberID: subscriberID, Pattern: pattern})
+
+	return m.persist(&m.data)
+}
+
+// RemovePatternFromSubscriberPatternMap removes subscriber to subscription pattern mapping
+func (m *MemoryDB) RemovePatternFromSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := []SubscriptionPattern{}
+	for _, p := range m.data.SubscriberPatternMap {
+		if p.SubscriberID != subscriberID || p.Pattern != pattern {
+			rows = append(rows, p)
+		}
+	}
+	m.data.SubscriberPatternMap = rows
+
+	return m.persist(&m.data)
+}
+
+// SaveQueues persists all the message from topic
+func (m *MemoryDB) SaveQueues(ctx context.Context, queue *[]StoreQueue, isLiveQueue bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := &m.data.DLQ
+	if isLiveQueue {
+		rows = &m.data.Queue
+	}
+
+	for _, q := range *queue {
+		for _, r := range *rows {
+			if r.QueuID == q.QueuID {
+				return errors.Errorf("duplicate queue entry %v", q.QueuID)
+			}
+		}
+		*rows = append(*rows, q)
+	}
+
+	return m.persist(&m.data)
+}
+
+// RemoveMessagesFromQueue clears the live queue
+func (m *MemoryDB) RemoveMessagesFromQueue(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.Queue = nil
+
+	return m.persist(&m.data)
+}
+
+// FetchQueues fetches messages for the queue
+func (m *MemoryDB) FetchQueues(ctx context.Context) (*Queue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	rows := make([]StoreQueue, len(m.data.Queue))
+	copy(rows, m.data.Queue)
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Offset < rows[j].Offset
+	})
+
+	t := map[string][]Message{}
+	for _, q := range rows {
+		msg, ok := m.data.Messages[q.MessageID]
+		if !ok {
+			continue
+		}
+
+		mm := msg.Message
+		mm.Offset = q.Offset
+		mm.Partition = q.Partition
+		t[q.TopicID] = append(t[q.TopicID], mm)
+	}
+
+	return &Queue{Topic: t}, nil
+}
+
+// FetchDeadQueues fetches the messages held in the dead letter queue per topic
+func (m *MemoryDB) FetchDeadQueues(ctx context.Context) (*DeadQueue, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	type deadRow struct {
+		topicID string
+		message DeadMessage
+	}
+
+	rows := []deadRow{}
+	for _, q := range m.data.DLQ {
+		msg, ok := m.data.Messages[q.MessageID]
+		if !ok {
+			continue
+		}
+
+		d := DeadMessage{
+			MessageID:   msg.MessageID,
+			Partition:   q.Partition,
+			Key:         msg.Key,
+			ContentType: msg.ContentType,
+			Headers:     msg.Headers,
+			Data:        msg.Data,
+			CretedAt:    msg.CretedAt,
+			ExpiresAt:   msg.ExpiresAt,
+			Reason:      q.Reason,
+			DeadAt:      q.DeadAt,
+		}
+		if d.DeadAt.IsZero() {
+			d.DeadAt = msg.ExpiresAt
+		}
+		if d.DeadAt.IsZero() {
+			d.DeadAt = msg.CretedAt
+		}
+
+		rows = append(rows, deadRow{topicID: q.TopicID, message: d})
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].message.DeadAt.Before(rows[j].message.DeadAt)
+	})
+
+	t := map[string][]DeadMessage{}
+	for _, r := range rows {
+		t[r.topicID] = append(t[r.topicID], r.message)
+	}
+
+	return &DeadQueue{Topic: t}, nil
+}
+
+// RemoveMessagesFromDLQ clears the dead letter queue
+func (m *MemoryDB) RemoveMessagesFromDLQ(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.DLQ = nil
+
+	return m.persist(&m.data)
+}
+
+// FetchSubscriberOffsets fetches the read offset of every subscriber per partition of the subscribed topics and of the
+// topics matching its patterns followed by the read offset of every consumer group per partition, subscriptions without
+// a stored offset are returned once with an offset of -1
+func (m *MemoryDB) FetchSubscriberOffsets(ctx context.Context) (*[]SubscriberOffset, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offsets := []SubscriberOffset{}
+	groups := []SubscriberOffset{}
+	for _, s := range m.data.SubscriberTopicMap {
+		o := SubscriberOffset{SubscriberID: s.SubscriberID, TopicID: s.TopicID, Offset: -1}
+		if s.GroupName != "" {
+			o = SubscriberOffset{Group: s.GroupName, TopicID: s.TopicID, Offset: -1}
+			if containsOffset(groups, o, sameConsumer) {
+				continue
+			}
+		}
+
+		stored := []SubscriberOffset{}
+		for _, so := range m.data.SubscriberOffsets {
+			if sameConsumer(so, o) {
+				stored = append(stored, so)
+			}
+		}
+
+		if len(stored) == 0 {
+			stored = append(stored, o)
+		}
+
+		if o.Group != "" {
+			groups = append(groups, stored...)
+		} else {
+			offsets = append(offsets, stored...)
+		}
+	}
+
+	// pattern subscribers have no subscription row for the topics matching their patterns
+	for _, so := range m.data.SubscriberOffsets {
+		if so.Group == "" && m.hasPattern(so.SubscriberID) && !containsOffset(offsets, so, sameConsumer) {
+			offsets = append(offsets, so)
+		}
+	}
+	offsets = append(offsets, groups...)
+
+	return &offsets, nil
+}
+
+// SaveSubscriberOffsets persists the read offset of every subscriber and consumer group per topic
+func (m *MemoryDB) SaveSubscriberOffsets(ctx context.Context, offsets *[]SubscriberOffset) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, o := range *offsets {
+		if containsOffset(m.data.SubscriberOffsets, o, sameOffset) {
+			if o.Group != "" {
+				return errors.Errorf("duplicate offset for group %v on partition %v of topic %v", o.Group, o.Partition, o.TopicID)
+			}
+			return errors.Errorf("duplicate offset for subscriber %v on partition %v of topic %v", o.SubscriberID, o.Partition, o.TopicID)
+		}
+		m.data.SubscriberOffsets = append(m.data.SubscriberOffsets, o)
+	}
+
+	return m.persist(&m.data)
+}
+
+// RemoveSubscriberOffsets clears the subscriber offsets
+func (m *MemoryDB) RemoveSubscriberOffsets(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.data.SubscriberOffsets = nil
+
+	return m.persist(&m.data)
+}
+
+// InsertTopic inserts new topic with the given number of partitions, ttl and limits
+func (m *MemoryDB) InsertTopic(ctx context.Context, topicID string, topicName string, partitions int, ttl TopicTTL, limits TopicLimits) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range m.data.Topics {
+		if t.TopicID == topicID {
+			return errors.Errorf("duplicate topic %v", topicID)
+		}
+	}
+	m.data.Topics = append(m.data.Topics, topicRow{
+		TopicID:        topicID,
+		Name:           topicName,
+		Partitions:     partitions,
+		DefaultTTL:     ttl.DefaultTTL,
+		MaxTTL:         ttl.MaxTTL,
+		MaxMessages:    limits.MaxMessages,
+		MaxBytes:       limits.MaxBytes,
+		OverflowPolicy: limits.Overflow,
+	})
+
+	return m.persist(&m.data)
+}
+
+// FetchTopicPartitions fetches the number of partitions of every topic
+func (m *MemoryDB) FetchTopicPartitions(ctx context.Context) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	partitions := map[string]int{}
+	for _, t := range m.data.Topics {
+		partitions[t.TopicID] = 1
+		if t.Partitions > 1 {
+			partitions[t.TopicID] = t.Partitions
+		}
+	}
+
+	return partitions, nil
+}
+
+// RenameTopic updates the name of the topic
+func (m *MemoryDB) RenameTopic(ctx context.Context, topicID string, topicName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.data.Topics {
+		if m.data.Topics[i].TopicID == topicID {
+			m.data.Topics[i].Name = topicName
+		}
+	}
+
+	return m.persist(&m.data)
+}
+
+// GetTopicTTL gets the default and maximum time to live of the messages of the topic
+func (m *MemoryDB) GetTopicTTL(ctx context.Context, topicID string) (*TopicTTL, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, t := range m.data.Topics {
+		if t.TopicID == topicID {
+			return &TopicTTL{DefaultTTL: t.DefaultTTL, MaxTTL: t.MaxTTL}, nil
+		}
+	}
+
+	return &TopicTTL{}, nil
+}
+
+// UpdateTopicTTL updates the default and maximum time to live of the messages of the topic
+func (m *MemoryDB) UpdateTopicTTL(ctx context.Context, topicID string, ttl TopicTTL) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.data.Topics {
+		if m.data.Topics[i].TopicID == topicID {
+			m.data.Topics[i].DefaultTTL = ttl.DefaultTTL
+			m.data.Topics[i].MaxTTL = ttl.MaxTTL
+		}
+	}
+
+	return m.persist(&m.data)
+}
+
+// FetchTopicLimits fetches the limits and overflow policy of every topic
+func (m *MemoryDB) FetchTopicLimits(ctx context.Context) (map[string]TopicLimits, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	limits := map[string]TopicLimits{}
+	for _, t := range m.data.Topics {
+		limits[t.TopicID] = TopicLimits{MaxMessages: t.MaxMessages, MaxBytes: t.MaxBytes, Overflow: t.OverflowPolicy}
+	}
+
+	return limits, nil
+}
+
+// UpdateTopicLimits updates the limits and overflow policy of the topic
+func (m *MemoryDB) UpdateTopicLimits(ctx context.Context, topicID string, limits TopicLimits) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i := range m.data.Topics {
+		if m.data.Topics[i].TopicID == topicID {
+			m.data.Topics[i].MaxMessages = limits.MaxMessages
+			m.data.Topics[i].MaxBytes = limits.MaxBytes
+			m.data.Topics[i].OverflowPolicy = limits.Overflow
+		}
+	}
+
+	return m.persist(&m.data)
+}
+
+// DeleteTopic removes the topic along with its messages, subscriptions and offsets,
+// the publishers registered to the topic are left without a topic
+func (m *MemoryDB) DeleteTopic(ctx context.Context, topicID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	offsets := []SubscriberOffset{}
+	for _, o := range m.data.SubscriberOffsets {
+		if o.TopicID != topicID {
+			offsets = append(offsets, o)
+		}
+	}
+	m.data.SubscriberOffsets = offsets
+
+	subscriptions := []subscriberTopicRow{}
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.TopicID != topicID {
+			subscriptions = append(subscriptions, s)
+		}
+	}
+	m.data.SubscriberTopicMap = subscriptions
+
+	m.data.Queue = removeStoreQueue(m.data.Queue, topicID)
+	m.data.DLQ = removeStoreQueue(m.data.DLQ, topicID)
+
+	for id, msg := range m.data.Messages {
+		if msg.TopicID == topicID {
+			delete(m.data.Messages, id)
+		}
+	}
+
+	m.data.PublisherTopicMap = removePublisherTopic(m.data.PublisherTopicMap, func(p publisherTopicRow) bool {
+		return p.TopicID == topicID
+	})
+
+	topics := []topicRow{}
+	for _, t := range m.data.Topics {
+		if t.TopicID != topicID {
+			topics = append(topics, t)
+		}
+	}
+	m.data.Topics = topics
+
+	return m.persist(&m.data)
+}
+
+// GetTopicStats counts the publishers and subscribers registered to the topic
+func (m *MemoryDB) GetTopicStats(ctx context.Context, topicID string) (*TopicStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := &TopicStats{}
+	for _, p := range m.data.PublisherTopicMap {
+		if p.TopicID == topicID {
+			stats.Publishers++
+		}
+	}
+
+	for _, s := range m.data.SubscriberTopicMap {
+		if s.TopicID == topicID {
+			stats.Subscribers++
+		}
+	}
+
+	return stats, nil
+}
+
+// InsertClient inserts new client and returns the clientId given to it
+func (m *MemoryDB) InsertClient(ctx context.Context, client Client) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	client.ClientID = firstClientID
+	for _, c := range m.data.Clients {
+		if c.Name == client.Name {
+			return 0, errors.Errorf("duplicate client %v", client.Name)
+		}
+		if c.APIKeyHash == client.APIKeyHash {
+			return 0, errors.New("duplicate api key")
+		}
+		if c.ClientID >= client.ClientID {
+			client.ClientID = c.ClientID + 1
+		}
+	}
+	m.data.Clients = append(m.data.Clients, client)
+
+	if err := m.persist(&m.data); err != nil {
+		return 0, err
+	}
+
+	return client.ClientID, nil
+}
+
+// GetClientByAPIKey gets the client based on the hash of its api key
+func (m *MemoryDB) GetClientByAPIKey(ctx context.Context, apiKeyHash string) (*Client, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.data.Clients {
+		if c.APIKeyHash == apiKeyHash {
+			client := c
+			return &client, false, nil
+		}
+	}
+
+	return nil, true, nil
+}
+
+// GetClientByName gets the client based on its name
+func (m *MemoryDB) GetClientByName(ctx context.Context, name string) (*Client, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, c := range m.data.Clients {
+		if c.Name == name {
+			client := c
+			return &client, false, nil
+		}
+	}
+
+	return nil, true, nil
+}
+
+// addTopics creates the topics that do not exist yet
+func (m *MemoryDB) addTopics(topics []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	added := false
+	for _, name := range topics {
+		if name == "" || m.topicID(name) != "" {
+			continue
+		}
+
+		m.data.Topics = append(m.data.Topics, topicRow{TopicID: uuid.New().String(), Name: name})
+		added = true
+	}
+
+	if !added {
+		return nil
+	}
+
+	return m.persist(&m.data)
+}
+
+func (m *MemoryDB) topicID(topicName string) string {
+	for _, t := range m.data.Topics {
+		if t.Name == topicName {
+			return t.TopicID
+		}
+	}
+	return ""
+}
+
+func (m *MemoryDB) topicName(topicID string) string {
+	for _, t := range m.data.Topics {
+		if t.TopicID == topicID {
+			return t.Name
+		}
+	}
+	return ""
+}
+
+func removeStoreQueue(rows []StoreQueue, topicID string) []StoreQueue {
+	kept := []StoreQueue{}
+	for _, q := range rows {
+		if q.TopicID != topicID {
+			kept = append(kept, q)
+		}
+	}
+	return kept
+}
+
+// removePublisherTopic returns the publisher to topic mappings that do not match
+func removePublisherTopic(rows []publisherTopicRow, match func(publisherTopicRow) bool) []publisherTopicRow {
+	kept := []publisherTopicRow{}
+	for _, p := range rows {
+		if !match(p) {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
+
+// sameConsumer reports whether both offsets are kept for the same subscriber or consumer group on the same topic
+func sameConsumer(a, b SubscriberOffset) bool {
+	if a.Group != "" || b.Group != "" {
+		return a.Group == b.Group && a.TopicID == b.TopicID
+	}
+	return a.SubscriberID == b.SubscriberID && a.TopicID == b.TopicID
+}
+
+// sameOffset reports whether both offsets are kept for the same subscriber or consumer group on the same partition of a topic
+func sameOffset(a, b SubscriberOffset) bool {
+	return sameConsumer(a, b) && a.Partition == b.Partition
+}
+
+// hasPattern reports whether the subscriber holds a subscription pattern
+func (m *MemoryDB) hasPattern(subscriberID int) bool {
+	for _, p := range m.data.SubscriberPatternMap {
+		if p.SubscriberID == subscriberID {
+			return true
+		}
+	}
+	return false
+}
+
+func containsOffset(offsets []SubscriberOffset, o SubscriberOffset, same func(a, b SubscriberOffset) bool) bool {
+	for _, so := range offsets {
+		if same(so, o) {
+			return true
+		}
+	}
+	return false
+}
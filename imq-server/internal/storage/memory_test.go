@@ -0,0 +1,435 @@
+package storage_test
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
+)
+
+func TestMemoryDB_Publisher_Pass(t *testing.T) {
+	db, err := storage.NewMemoryDB([]string{"golang", "java"})
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	topics, err := db.FetchAllTopics(context.Background(), 5000)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(*topics, []string{"golang", "java"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang", "java"}, *topics)
+	}
+
+	javaID, _ := db.GetTopicIDFromTopic(context.Background(), "java")
+	golangID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	if err := db.InsertPublisherIDIntoPublisher(context.Background(), 5000); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if err := db.InsertPublisherIDIntoPublisher(context.Background(), 5000); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	db.InsertIntoPublisherTopicMap(context.Background(), 5000, javaID)
+	db.InsertIntoPublisherTopicMap(context.Background(), 5000, golangID)
+
+	if err := db.InsertIntoPublisherTopicMap(context.Background(), 5000, javaID); err == nil {
+		t.Fatalf("expected: duplicate publisher, got: nil")
+	}
+
+	got, err := db.GetPublishedTopics(context.Background(), 5000)
+	if err != nil || !reflect.DeepEqual(got, []string{"java", "golang"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"java", "golang"}, got)
+	}
+
+	if err := db.RemoveTopicIDFromPublisherTopicMap(context.Background(), 5000, javaID); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	got, _ = db.GetPublishedTopics(context.Background(), 5000)
+	if !reflect.DeepEqual(got, []string{"golang"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang"}, got)
+	}
+}
+
+func TestMemoryDB_Subscriber_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang", "java"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	db.InsertSubscriberIDIntoSubscriber(context.Background(), 6000)
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, topicID, storage.Subscription{Filter: "region = 'eu'"})
+
+	topics, err := db.GetSubscribedTopics(context.Background(), 6000)
+	if err != nil || !reflect.DeepEqual(topics, []string{"golang"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang"}, topics)
+	}
+
+	subscription, err := db.GetSubscription(context.Background(), 6000, topicID)
+	if err != nil || subscription.Filter != "region = 'eu'" {
+		t.Fatalf("expected: %v, got: %v", "region = 'eu'", subscription)
+	}
+
+	offsets, _ := db.FetchSubscriberOffsets(context.Background())
+	expected := []storage.SubscriberOffset{{SubscriberID: 6000, TopicID: topicID, Offset: -1}}
+	if !reflect.DeepEqual(*offsets, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, *offsets)
+	}
+
+	db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{{SubscriberID: 6000, TopicID: topicID, Offset: 3}})
+
+	offsets, _ = db.FetchSubscriberOffsets(context.Background())
+	expected[0].Offset = 3
+	if !reflect.DeepEqual(*offsets, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, *offsets)
+	}
+
+	db.RemoveTopicIDFromSubscriberTopicMap(context.Background(), 6000, topicID)
+
+	topics, _ = db.GetSubscribedTopics(context.Background(), 6000)
+	if len(topics) != 0 {
+		t.Fatalf("expected: [], got: %v", topics)
+	}
+}
+
+func TestMemoryDB_ConsumerGroups_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	db.InsertIntoSubscriberTopicMap(context.Background(), 7000, topicID, storage.Subscription{GroupName: "billing"})
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, topicID, storage.Subscription{GroupName: "billing"})
+	db.InsertIntoSubscriberTopicMap(context.Background(), 8000, topicID, storage.Subscription{})
+
+	members, err := db.GetConsumerGroups(context.Background(), topicID)
+	expectedMembers := []storage.ConsumerGroupMember{{GroupName: "billing", SubscriberID: 6000}, {GroupName: "billing", SubscriberID: 7000}}
+	if err != nil || !reflect.DeepEqual(members, expectedMembers) {
+		t.Fatalf("expected: %v, got: %v", expectedMembers, members)
+	}
+
+	db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{{Group: "billing", TopicID: topicID, Offset: 4}})
+
+	if err := db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{{Group: "billing", TopicID: topicID, Offset: 5}}); err == nil {
+		t.Fatalf("expected: duplicate offset error, got: nil")
+	}
+
+	offsets, _ := db.FetchSubscriberOffsets(context.Background())
+	expected := []storage.SubscriberOffset{
+		{SubscriberID: 8000, TopicID: topicID, Offset: -1},
+		{Group: "billing", TopicID: topicID, Offset: 4},
+	}
+	if !reflect.DeepEqual(*offsets, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, *offsets)
+	}
+}
+
+func TestMemoryDB_TopicLimits_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	limits := storage.TopicLimits{MaxMessages: 100, MaxBytes: 4096, Overflow: "dropOldest"}
+	if err := db.UpdateTopicLimits(context.Background(), topicID, limits); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	got, err := db.FetchTopicLimits(context.Background())
+	expected := map[string]storage.TopicLimits{topicID: limits}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestMemoryDB_Partitions_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	db.InsertTopic(context.Background(), "12345", "orders", 4, storage.TopicTTL{}, storage.TopicLimits{})
+
+	partitions, err := db.FetchTopicPartitions(context.Background())
+	expectedPartitions := map[string]int{topicID: 1, "12345": 4}
+	if err != nil || !reflect.DeepEqual(partitions, expectedPartitions) {
+		t.Fatalf("expected: %v, got: %v", expectedPartitions, partitions)
+	}
+
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, "12345", storage.Subscription{})
+	db.InsertIntoSubscriberTopicMap(context.Background(), 7000, "12345", storage.Subscription{GroupName: "billing"})
+
+	db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{
+		{SubscriberID: 6000, TopicID: "12345", Partition: 0, Offset: 2},
+		{SubscriberID: 6000, TopicID: "12345", Partition: 3, Offset: 5},
+		{Group: "billing", TopicID: "12345", Partition: 1, Offset: 7},
+	})
+
+	if err := db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{{SubscriberID: 6000, TopicID: "12345", Partition: 3, Offset: 6}}); err == nil {
+		t.Fatalf("expected: duplicate offset error, got: nil")
+	}
+
+	offsets, _ := db.FetchSubscriberOffsets(context.Background())
+	expected := []storage.SubscriberOffset{
+		{SubscriberID: 6000, TopicID: "12345", Partition: 0, Offset: 2},
+		{SubscriberID: 6000, TopicID: "12345", Partition: 3, Offset: 5},
+		{Group: "billing", TopicID: "12345", Partition: 1, Offset: 7},
+	}
+	if !reflect.DeepEqual(*offsets, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, *offsets)
+	}
+}
+
+func TestMemoryDB_SubscriberPatterns_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+
+	db.InsertSubscriberIDIntoSubscriber(context.Background(), 6000)
+	db.InsertIntoSubscriberPatternMap(context.Background(), 6000, "orders.*")
+	db.InsertIntoSubscriberPatternMap(context.Background(), 7000, "#.created")
+
+	if err := db.InsertIntoSubscriberPatternMap(context.Background(), 6000, "orders.*"); err == nil {
+		t.Fatalf("expected: duplicate pattern error, got: nil")
+	}
+
+	patterns, err := db.GetSubscribedPatterns(context.Background(), 6000)
+	if err != nil || !reflect.DeepEqual(patterns, []string{"orders.*"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"orders.*"}, patterns)
+	}
+
+	all, _ := db.GetSubscriptionPatterns(context.Background())
+	expected := []storage.SubscriptionPattern{{SubscriberID: 6000, Pattern: "orders.*"}, {SubscriberID: 7000, Pattern: "#.created"}}
+	if !reflect.DeepEqual(all, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, all)
+	}
+
+	db.RemovePatternFromSubscriberPatternMap(context.Background(), 6000, "orders.*")
+
+	patterns, _ = db.GetSubscribedPatterns(context.Background(), 6000)
+	if len(patterns) != 0 {
+		t.Fatalf("expected: [], got: %v", patterns)
+	}
+}
+
+func TestMemoryDB_SubscriberPatternOffsets_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"orders.eu", "orders.us"})
+	euID, _ := db.GetTopicIDFromTopic(context.Background(), "orders.eu")
+	usID, _ := db.GetTopicIDFromTopic(context.Background(), "orders.us")
+
+	db.InsertIntoSubscriberPatternMap(context.Background(), 6000, "orders.*")
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, usID, storage.Subscription{})
+
+	db.SaveSubscriberOffsets(context.Background(), &[]storage.SubscriberOffset{
+		{SubscriberID: 6000, TopicID: euID, Offset: 2},
+		{SubscriberID: 6000, TopicID: usID, Offset: 4},
+		{SubscriberID: 7000, TopicID: euID, Offset: 1},
+	})
+
+	offsets, _ := db.FetchSubscriberOffsets(context.Background())
+	expected := []storage.SubscriberOffset{
+		{SubscriberID: 6000, TopicID: usID, Offset: 4},
+		{SubscriberID: 6000, TopicID: euID, Offset: 2},
+	}
+	if !reflect.DeepEqual(*offsets, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, *offsets)
+	}
+}
+
+func TestMemoryDB_Queues_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	messages := []storage.Message{
+		{MessageID: "message1", Data: []byte("data 1"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+		{MessageID: "message2", Data: []byte("data 2"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+	}
+	for _, m := range messages {
+		if err := db.InsertMessageIntoMessage(context.Background(), 5000, topicID, m); err != nil {
+			t.Fatalf("expected: nil, got: %v", err)
+		}
+	}
+
+	db.SaveQueues(context.Background(), &[]storage.StoreQueue{
+		{QueuID: "q2", TopicID: topicID, Partition: 1, MessageID: "message2", Offset: 1},
+		{QueuID: "q1", TopicID: topicID, MessageID: "message1", Offset: 0},
+	}, true)
+	db.SaveQueues(context.Background(), &[]storage.StoreQueue{
+		{QueuID: "d1", TopicID: topicID, MessageID: "message1", Reason: "expired", DeadAt: time.Date(2021, 2, 27, 20, 5, 0, 0, time.UTC)},
+	}, false)
+
+	messages[1].Offset = 1
+	messages[1].Partition = 1
+	expectedQueue := &storage.Queue{Topic: map[string][]storage.Message{topicID: messages}}
+
+	queue, err := db.FetchQueues(context.Background())
+	if err != nil || !reflect.DeepEqual(queue, expectedQueue) {
+		t.Fatalf("expected: %v, got: %v", expectedQueue, queue)
+	}
+
+	expectedDeadQueue := &storage.DeadQueue{Topic: map[string][]storage.DeadMessage{
+		topicID: {{MessageID: "message1", Data: []byte("data 1"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC), Reason: "expired", DeadAt: time.Date(2021, 2, 27, 20, 5, 0, 0, time.UTC)}},
+	}}
+
+	deadQueue, err := db.FetchDeadQueues(context.Background())
+	if err != nil || !reflect.DeepEqual(deadQueue, expectedDeadQueue) {
+		t.Fatalf("expected: %v, got: %v", expectedDeadQueue, deadQueue)
+	}
+
+	db.RemoveMessagesFromQueue(context.Background())
+	db.RemoveMessagesFromDLQ(context.Background())
+
+	queue, _ = db.FetchQueues(context.Background())
+	deadQueue, _ = db.FetchDeadQueues(context.Background())
+	if len(queue.Topic) != 0 || len(deadQueue.Topic) != 0 {
+		t.Fatalf("expected: empty queues, got: %v %v", queue, deadQueue)
+	}
+}
+
+func TestFileDB_Reopen_Pass(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "imq.json")
+
+	db, err := storage.NewFileDB(path, []string{"golang"})
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+	db.InsertSubscriberIDIntoSubscriber(context.Background(), 6000)
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, topicID, storage.Subscription{})
+
+	db, err = storage.NewFileDB(path, []string{"golang", "java"})
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	got, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+	if got != topicID {
+		t.Fatalf("expected: %v, got: %v", topicID, got)
+	}
+
+	topics, _ := db.FetchAllTopics(context.Background(), 5000)
+	if !reflect.DeepEqual(*topics, []string{"golang", "java"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang", "java"}, *topics)
+	}
+
+	subscribed, _ := db.GetSubscribedTopics(context.Background(), 6000)
+	if !reflect.DeepEqual(subscribed, []string{"golang"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang"}, subscribed)
+	}
+}
+
+func TestFileDB_LegacyPublishers_Pass(t *testing.T) {
+	dir, err := ioutil.TempDir("", "storage")
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "imq.json")
+
+	content := `{"topics":[{"topicId":"12345","name":"golang"}],"publishers":{"5000":"12345","5001":""}}`
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	db, err := storage.NewFileDB(path, nil)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	published, _ := db.GetPublishedTopics(context.Background(), 5000)
+	if !reflect.DeepEqual(published, []string{"golang"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang"}, published)
+	}
+
+	published, _ = db.GetPublishedTopics(context.Background(), 5001)
+	if len(published) != 0 {
+		t.Fatalf("expected: [], got: %v", published)
+	}
+}
+
+func TestMemoryDB_DeleteTopic_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB(nil)
+
+	db.InsertTopic(context.Background(), "12345", "golang", 1, storage.TopicTTL{}, storage.TopicLimits{})
+	db.InsertPublisherIDIntoPublisher(context.Background(), 5000)
+	db.InsertIntoPublisherTopicMap(context.Background(), 5000, "12345")
+	db.InsertSubscriberIDIntoSubscriber(context.Background(), 6000)
+	db.InsertIntoSubscriberTopicMap(context.Background(), 6000, "12345", storage.Subscription{})
+
+	stats, _ := db.GetTopicStats(context.Background(), "12345")
+	if *stats != (storage.TopicStats{Publishers: 1, Subscribers: 1}) {
+		t.Fatalf("expected: %v, got: %v", storage.TopicStats{Publishers: 1, Subscribers: 1}, *stats)
+	}
+
+	if err := db.DeleteTopic(context.Background(), "12345"); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+	if topicID != "" {
+		t.Fatalf("expected: empty topicId, got: %v", topicID)
+	}
+
+	published, _ := db.GetPublishedTopics(context.Background(), 5000)
+	subscribed, _ := db.GetSubscribedTopics(context.Background(), 6000)
+	if len(published) != 0 || len(subscribed) != 0 {
+		t.Fatalf("expected: no registrations, got: %v %v", published, subscribed)
+	}
+}
+
+func TestMemoryDB_Clients_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB(nil)
+
+	clientID, err := db.InsertClient(context.Background(), storage.Client{Name: "orders", Role: "publisher", APIKeyHash: "hash1"})
+	if err != nil || clientID != 1000 {
+		t.Fatalf("expected: %v, got: %v %v", 1000, clientID, err)
+	}
+
+	clientID, _ = db.InsertClient(context.Background(), storage.Client{Name: "billing", Role: "subscriber", APIKeyHash: "hash2"})
+	if clientID != 1001 {
+		t.Fatalf("expected: %v, got: %v", 1001, clientID)
+	}
+
+	if _, err := db.InsertClient(context.Background(), storage.Client{Name: "orders", Role: "admin", APIKeyHash: "hash3"}); err == nil {
+		t.Fatalf("expected: duplicate client, got: nil")
+	}
+
+	got, notFound, _ := db.GetClientByAPIKey(context.Background(), "hash2")
+	expected := &storage.Client{ClientID: 1001, Name: "billing", Role: "subscriber", APIKeyHash: "hash2"}
+	if notFound || !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+
+	if _, notFound, _ := db.GetClientByAPIKey(context.Background(), "unknown"); !notFound {
+		t.Fatalf("expected: notFound, got: %v", notFound)
+	}
+}
+
+func TestMemoryDB_InsertMessages_Pass(t *testing.T) {
+	db, _ := storage.NewMemoryDB([]string{"golang"})
+	topicID, _ := db.GetTopicIDFromTopic(context.Background(), "golang")
+
+	messages := []storage.Message{
+		{MessageID: "message1", Data: []byte("data 1"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+		{MessageID: "message2", Data: []byte("data 2"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+	}
+	if err := db.InsertMessagesIntoMessage(context.Background(), 5000, topicID, messages); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	duplicate := []storage.Message{{MessageID: "message3", Data: []byte("data 3")}, {MessageID: "message1", Data: []byte("data 1")}}
+	if err := db.InsertMessagesIntoMessage(context.Background(), 5000, topicID, duplicate); err == nil {
+		t.Fatalf("expected: duplicate message, got: nil")
+	}
+
+	if err := db.InsertMessageIntoMessage(context.Background(), 5000, topicID, storage.Message{MessageID: "message3", Data: []byte("data 3")}); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
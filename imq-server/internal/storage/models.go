@@ -1,18 +1,101 @@
 package storage
 
+import "time"
+
+// Message holds a message, Offset and Partition locate it in the queue of its topic
 type Message struct {
-	MessageID string
-	Data      string
-	CretedAt  string
-	ExpiresAt string
+	MessageID   string
+	Offset      int64
+	Partition   int
+	Key         string
+	ContentType string
+	Headers     map[string]string
+	Data        []byte
+	CretedAt    time.Time
+	ExpiresAt   time.Time
 }
 
 type Queue struct {
 	Topic map[string][]Message
 }
 
+type DeadMessage struct {
+	MessageID   string
+	Partition   int
+	Key         string
+	ContentType string
+	Headers     map[string]string
+	Data        []byte
+	CretedAt    time.Time
+	ExpiresAt   time.Time
+	Reason      string
+	DeadAt      time.Time
+}
+
+type DeadQueue struct {
+	Topic map[string][]DeadMessage
+}
+
 type StoreQueue struct {
 	QueuID    string
 	TopicID   string
+	Partition int
 	MessageID string
+	Offset    int64
+	Reason    string
+	DeadAt    time.Time
+}
+
+// SubscriberOffset holds the read offset of a subscriber for a partition of a topic, or of the consumer group named
+// by Group when it is set, in which case SubscriberID is not used
+type SubscriberOffset struct {
+	SubscriberID int
+	Group        string
+	TopicID      string
+	Partition    int
+	Offset       int64
+}
+
+// Subscription holds the filter and consumer group of a subscription to a topic, both empty when not set
+type Subscription struct {
+	Filter    string
+	GroupName string
+}
+
+// ConsumerGroupMember holds a subscriber reading a topic as a member of a consumer group
+type ConsumerGroupMember struct {
+	GroupName    string
+	SubscriberID int
+}
+
+// SubscriptionPattern holds a subscription of a subscriber to every topic matched by the pattern
+type SubscriptionPattern struct {
+	SubscriberID int
+	Pattern      string
+}
+
+// TopicTTL holds the default and maximum time to live of the messages of a topic in seconds, zero when not set
+type TopicTTL struct {
+	DefaultTTL int
+	MaxTTL     int
+}
+
+// TopicLimits holds how many messages and bytes a topic holds at most and the overflow policy applied to a message
+// published to it once it is full, zero or empty when not set
+type TopicLimits struct {
+	MaxMessages int
+	MaxBytes    int
+	Overflow    string
+}
+
+type TopicStats struct {
+	Publishers  int
+	Subscribers int
+}
+
+type Client struct {
+	ClientID   int
+	Name       string
+	Role       string
+	APIKeyHash string
 }
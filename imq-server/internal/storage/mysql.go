@@ -3,6 +3,9 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql" //to be used indirectly by mysql driver
 	"github.com/pkg/errors"
@@ -15,19 +18,43 @@ type DatabaseIF interface {
 	Connect() error
 	Test() error
 	FetchAllTopics(ctx context.Context, id int) (*[]string, error)
-	InsertPublisher(ctx context.Context, publisherID int, topicID string) error
-	UpdateTopicIDIntoPublisher(ctx context.Context, publisherID int, topicID string) error
-	RemoveTopicIDFromPublisher(ctx context.Context, publisherID int) error
+	GetPublishedTopics(ctx context.Context, publisherID int) ([]string, error)
+	InsertPublisherIDIntoPublisher(ctx context.Context, publisherID int) error
+	InsertIntoPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error
+	RemoveTopicIDFromPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error
 	FetchQueues(ctx context.Context) (*Queue, error)
-	GetTopicIDFromPublisher(ctx context.Context, publisherID int) (string, bool, error)
 	GetTopicIDFromTopic(ctx context.Context, topicName string) (string, error)
 	InsertMessageIntoMessage(ctx context.Context, publisherID int, topicID string, message Message) error
+	InsertMessagesIntoMessage(ctx context.Context, publisherID int, topicID string, messages []Message) error
 	GetSubscribedTopics(ctx context.Context, subscriberID int) ([]string, error)
 	InsertSubscriberIDIntoSubscriber(ctx context.Context, subscriberID int) error
-	InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error
+	InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string, subscription Subscription) error
+	GetSubscription(ctx context.Context, subscriberID int, topicID string) (*Subscription, error)
+	GetConsumerGroups(ctx context.Context, topicID string) ([]ConsumerGroupMember, error)
 	RemoveTopicIDFromSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error
+	GetSubscribedPatterns(ctx context.Context, subscriberID int) ([]string, error)
+	GetSubscriptionPatterns(ctx context.Context) ([]SubscriptionPattern, error)
+	InsertIntoSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error
+	RemovePatternFromSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error
 	SaveQueues(ctx context.Context, queue *[]StoreQueue, isLiveQueue bool) error
 	RemoveMessagesFromQueue(ctx context.Context) error
+	FetchDeadQueues(ctx context.Context) (*DeadQueue, error)
+	RemoveMessagesFromDLQ(ctx context.Context) error
+	FetchSubscriberOffsets(ctx context.Context) (*[]SubscriberOffset, error)
+	SaveSubscriberOffsets(ctx context.Context, offsets *[]SubscriberOffset) error
+	RemoveSubscriberOffsets(ctx context.Context) error
+	InsertTopic(ctx context.Context, topicID string, topicName string, partitions int, ttl TopicTTL, limits TopicLimits) error
+	FetchTopicPartitions(ctx context.Context) (map[string]int, error)
+	RenameTopic(ctx context.Context, topicID string, topicName string) error
+	DeleteTopic(ctx context.Context, topicID string) error
+	GetTopicStats(ctx context.Context, topicID string) (*TopicStats, error)
+	GetTopicTTL(ctx context.Context, topicID string) (*TopicTTL, error)
+	UpdateTopicTTL(ctx context.Context, topicID string, ttl TopicTTL) error
+	FetchTopicLimits(ctx context.Context) (map[string]TopicLimits, error)
+	UpdateTopicLimits(ctx context.Context, topicID string, limits TopicLimits) error
+	InsertClient(ctx context.Context, client Client) (int, error)
+	GetClientByAPIKey(ctx context.Context, apiKeyHash string) (*Client, bool, error)
+	GetClientByName(ctx context.Context, name string) (*Client, bool, error)
 }
 
 // MysqlDB is the reciever type for DatabaseIF
@@ -72,40 +99,6 @@ func (m *MysqlDB) Test() error {
 	return nil
 }
 
-// GetTopicIDFromPublisher gets topicId from Publisher table based on given publisherId
-func (m *MysqlDB) GetTopicIDFromPublisher(ctx context.Context, publisherID int) (string, bool, error) {
-	var (
-		topicID  string
-		notFound bool
-	)
-
-	stmt := `SELECT IFNULL(topicId,"") FROM Publisher WHERE publisherId = ?`
-
-	err := m.Cxn.QueryRowContext(ctx, stmt, publisherID).Scan(&topicID)
-	if err != nil && err != sql.ErrNoRows {
-		return "", notFound, err
-	}
-
-	if err == sql.ErrNoRows {
-		notFound = true
-		return "", notFound, nil
-	}
-
-	return topicID, notFound, nil
-}
-
-// UpdateTopicIDIntoPublisher updates topicId into Publisher Table
-func (m *MysqlDB) UpdateTopicIDIntoPublisher(ctx context.Context, publisherID int, topicID string) error {
-	stmt := `UPDATE Publisher SET topicId = ? WHERE publisherId = ?`
-
-	_, err := m.Cxn.ExecContext(ctx, stmt, topicID, publisherID)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // GetTopicIDFromTopic gets topicId from Topic table based on given topicName
 func (m *MysqlDB) GetTopicIDFromTopic(ctx context.Context, topicName string) (string, error) {
 	var topicID string
@@ -120,23 +113,11 @@ func (m *MysqlDB) GetTopicIDFromTopic(ctx context.Context, topicName string) (st
 	return topicID, nil
 }
 
-// RemoveTopicIDFromPublisher  removes topicId from Publisher based on given publisherId
-func (m *MysqlDB) RemoveTopicIDFromPublisher(ctx context.Context, publisherID int) error {
-	stmt := `UPDATE Publisher SET topicId = NULL WHERE publisherId = ?`
-
-	_, err := m.Cxn.ExecContext(ctx, stmt, publisherID)
-	if err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // FetchQueues fetches messages for the queue
 func (m *MysqlDB) FetchQueues(ctx context.Context) (*Queue, error) {
-	stmt := `SELECT Q.topicId,M.messageId,M.data,M.createdAt,M.expiredAt 
+	stmt := `SELECT Q.topicId,Q.partitionId,Q.messageOffset,M.messageId,IFNULL(M.messageKey,''),IFNULL(M.contentType,''),IFNULL(M.headers,''),M.data,M.createdAt,M.expiredAt 
 				FROM Queue as Q JOIN Message as M 
-				ON Q.messageId = M.messageId`
+				ON Q.messageId = M.messageId ORDER BY Q.messageOffset`
 
 	row, err := m.Cxn.QueryContext(ctx, stmt)
 	if err != nil && err != sql.ErrNoRows {
@@ -148,10 +129,16 @@ func (m *MysqlDB) FetchQueues(ctx context.Context) (*Queue, error) {
 	t := map[string][]Message{}
 
 	for row.Next() {
-		var topicID string
+		var topicID, headers string
+		var expiresAt sql.NullTime
 		m := Message{}
 
-		if err := row.Scan(&topicID, &m.MessageID, &m.Data, &m.CretedAt, &m.ExpiresAt); err != nil {
+		if err := row.Scan(&topicID, &m.Partition, &m.Offset, &m.MessageID, &m.Key, &m.ContentType, &headers, &m.Data, &m.CretedAt, &expiresAt); err != nil {
+			return nil, err
+		}
+		m.ExpiresAt = expiresAt.Time
+
+		if m.Headers, err = decodeHeaders(headers); err != nil {
 			return nil, err
 		}
 		t[topicID] = append(t[topicID], m)
@@ -182,11 +169,72 @@ func (m *MysqlDB) FetchAllTopics(ctx context.Context, id int) (*[]string, error)
 	return &topics, nil
 }
 
-// InsertPublisher insert new Publisher to Publisher table
-func (m *MysqlDB) InsertPublisher(ctx context.Context, publisherID int, topicID string) error {
-	stmt := `INSERT INTO Publisher (publisherId, topicId) VALUES (?,?)`
+// GetPublishedTopics fetches all the topics the publisher is connected to
+func (m *MysqlDB) GetPublishedTopics(ctx context.Context, publisherID int) ([]string, error) {
+	var topics []string
+
+	stmt := `SELECT T.name FROM Topic as T 
+				JOIN PublisherTopicMap AS P
+				ON T.topicId = P.topicId WHERE P.publisherId = ?`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt, publisherID)
+	if err != nil {
+		return []string{}, err
+	}
+
+	defer row.Close()
+
+	for row.Next() {
+		var topic string
+		if err := row.Scan(&topic); err != nil {
+			return nil, err
+		}
+		topics = append(topics, topic)
+	}
+
+	return topics, nil
+}
+
+// InsertPublisherIDIntoPublisher inserts new publisher into Publisher table
+func (m *MysqlDB) InsertPublisherIDIntoPublisher(ctx context.Context, publisherID int) error {
+	var id int
+
+	stmt := `SELECT publisherId FROM Publisher WHERE publisherId = ?`
 
-	_, err := m.Cxn.Exec(stmt, publisherID, topicID)
+	err := m.Cxn.QueryRowContext(ctx, stmt, publisherID).Scan(&id)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if id == 0 {
+		stmt = `INSERT INTO Publisher (publisherId) VALUES (?)`
+
+		_, err = m.Cxn.ExecContext(ctx, stmt, publisherID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InsertIntoPublisherTopicMap inserts publisher to topic mapping
+func (m *MysqlDB) InsertIntoPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
+	stmt := `INSERT INTO PublisherTopicMap (publisherId,topicId) VALUES (?,?)`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, publisherID, topicID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemoveTopicIDFromPublisherTopicMap removes topicId and publisherId from PublisherTopicMap table
+func (m *MysqlDB) RemoveTopicIDFromPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
+	stmt := `DELETE FROM PublisherTopicMap WHERE publisherId = ? AND topicId = ?`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, publisherID, topicID)
 	if err != nil {
 		return err
 	}
@@ -196,9 +244,40 @@ func (m *MysqlDB) InsertPublisher(ctx context.Context, publisherID int, topicID
 
 // InsertMessageIntoMessage persists message info into Message table
 func (m *MysqlDB) InsertMessageIntoMessage(ctx context.Context, publisherID int, topicID string, message Message) error {
-	stmt := `INSERT INTO Message (messageId,data,createdAt,expiredAt,pubId,topicId) VALUES (?,?,?,?,?,?)`
+	stmt := `INSERT INTO Message (messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId) VALUES (?,?,?,?,?,?,?,?,?)`
+
+	headers, err := encodeHeaders(message.Headers)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.Cxn.ExecContext(ctx, stmt, message.MessageID, message.Key, message.ContentType, headers, message.Data, message.CretedAt, nullTime(message.ExpiresAt), publisherID, topicID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// InsertMessagesIntoMessage persists the info of every message of a batch into Message table with a single statement
+func (m *MysqlDB) InsertMessagesIntoMessage(ctx context.Context, publisherID int, topicID string, messages []Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+
+	stmt := `INSERT INTO Message (messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId) VALUES ` +
+		strings.TrimSuffix(strings.Repeat("(?,?,?,?,?,?,?,?,?),", len(messages)), ",")
+
+	args := []interface{}{}
+	for _, message := range messages {
+		headers, err := encodeHeaders(message.Headers)
+		if err != nil {
+			return err
+		}
+		args = append(args, message.MessageID, message.Key, message.ContentType, headers, message.Data, message.CretedAt, nullTime(message.ExpiresAt), publisherID, topicID)
+	}
 
-	_, err := m.Cxn.ExecContext(ctx, stmt, message.MessageID, message.Data, message.CretedAt, message.ExpiresAt, publisherID, topicID)
+	_, err := m.Cxn.ExecContext(ctx, stmt, args...)
 	if err != nil {
 		return err
 	}
@@ -253,11 +332,11 @@ func (m *MysqlDB) InsertSubscriberIDIntoSubscriber(ctx context.Context, subscrib
 	return nil
 }
 
-// InsertIntoSubscriberTopicMap inserts susbscriber to topic mapping
-func (m *MysqlDB) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error {
-	stmt := `INSERT INTO SubscriberTopicMap (subscriberId,topicId) VALUES (?,?)`
+// InsertIntoSubscriberTopicMap inserts susbscriber to topic mapping along with the filter and consumer group of the subscription
+func (m *MysqlDB) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string, subscription Subscription) error {
+	stmt := `INSERT INTO SubscriberTopicMap (subscriberId,topicId,filter,groupName) VALUES (?,?,?,?)`
 
-	_, err := m.Cxn.ExecContext(ctx, stmt, subscriberID, topicID)
+	_, err := m.Cxn.ExecContext(ctx, stmt, subscriberID, topicID, subscription.Filter, subscription.GroupName)
 	if err != nil {
 		return err
 	}
@@ -265,6 +344,44 @@ func (m *MysqlDB) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID
 	return nil
 }
 
+// GetSubscription gets the filter and consumer group of the subscription of the subscriber to the topic
+func (m *MysqlDB) GetSubscription(ctx context.Context, subscriberID int, topicID string) (*Subscription, error) {
+	subscription := &Subscription{}
+
+	stmt := `SELECT filter,groupName FROM SubscriberTopicMap WHERE subscriberId = ? AND topicId = ?`
+
+	err := m.Cxn.QueryRowContext(ctx, stmt, subscriberID, topicID).Scan(&subscription.Filter, &subscription.GroupName)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// GetConsumerGroups fetches the members of every consumer group reading the topic
+func (m *MysqlDB) GetConsumerGroups(ctx context.Context, topicID string) ([]ConsumerGroupMember, error) {
+	members := []ConsumerGroupMember{}
+
+	stmt := `SELECT groupName,subscriberId FROM SubscriberTopicMap WHERE topicId = ? AND groupName <> '' ORDER BY groupName,subscriberId`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt, topicID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	for row.Next() {
+		member := ConsumerGroupMember{}
+		if err := row.Scan(&member.GroupName, &member.SubscriberID); err != nil {
+			return nil, err
+		}
+		members = append(members, member)
+	}
+
+	return members, nil
+}
+
 // RemoveTopicIDFromSubscriberTopicMap remove topicId andm subscriberId from SubscriberTopicMap table
 func (m *MysqlDB) RemoveTopicIDFromSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error {
 	stmt := `DELETE FROM SubscriberTopicMap WHERE subscriberId = ? AND topicId = ?`
@@ -277,18 +394,97 @@ func (m *MysqlDB) RemoveTopicIDFromSubscriberTopicMap(ctx context.Context, subsc
 	return nil
 }
 
+// GetSubscribedPatterns fetches all the subscription patterns of the subscriber
+func (m *MysqlDB) GetSubscribedPatterns(ctx context.Context, subscriberID int) ([]string, error) {
+	patterns := []string{}
+
+	stmt := `SELECT pattern FROM SubscriberPatternMap WHERE subscriberId = ?`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt, subscriberID)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	for row.Next() {
+		var pattern string
+		if err := row.Scan(&pattern); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, pattern)
+	}
+
+	return patterns, nil
+}
+
+// GetSubscriptionPatterns fetches the subscription patterns of every subscriber
+func (m *MysqlDB) GetSubscriptionPatterns(ctx context.Context) ([]SubscriptionPattern, error) {
+	patterns := []SubscriptionPattern{}
+
+	stmt := `SELECT subscriberId,pattern FROM SubscriberPatternMap`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	for row.Next() {
+		p := SubscriptionPattern{}
+		if err := row.Scan(&p.SubscriberID, &p.Pattern); err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, p)
+	}
+
+	return patterns, nil
+}
+
+// InsertIntoSubscriberPatternMap inserts subscriber to subscription pattern mapping
+func (m *MysqlDB) InsertIntoSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	stmt := `INSERT INTO SubscriberPatternMap (subscriberId,pattern) VALUES (?,?)`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, subscriberID, pattern)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RemovePatternFromSubscriberPatternMap remove pattern and subscriberId from SubscriberPatternMap table
+func (m *MysqlDB) RemovePatternFromSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	stmt := `DELETE FROM SubscriberPatternMap WHERE subscriberId = ? AND pattern = ?`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, subscriberID, pattern)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 // SaveQueues persists all the message from topic to db
 func (m *MysqlDB) SaveQueues(ctx context.Context, queue *[]StoreQueue, isLiveQueue bool) error {
 	var stmt string
 
 	if isLiveQueue {
-		stmt = `INSERT INTO Queue (queueId,topicId,messageId) VALUES (?,?,?)`
+		stmt = `INSERT INTO Queue (queueId,topicId,partitionId,messageId,messageOffset) VALUES (?,?,?,?,?)`
 	} else {
-		stmt = `INSERT INTO DLQ (dlqId,topicId,messageId) VALUES (?,?,?)`
+		stmt = `INSERT INTO DLQ (dlqId,topicId,partitionId,messageId,reason,deadAt) VALUES (?,?,?,?,?,?)`
 	}
 
 	for _, q := range *queue {
-		_, err := m.Cxn.ExecContext(ctx, stmt, q.QueuID, q.TopicID, q.MessageID)
+		args := []interface{}{q.QueuID, q.TopicID, q.Partition, q.MessageID}
+		if isLiveQueue {
+			args = append(args, q.Offset)
+		} else {
+			args = append(args, q.Reason, q.DeadAt)
+		}
+
+		_, err := m.Cxn.ExecContext(ctx, stmt, args...)
 		if err != nil {
 			return err
 		}
@@ -308,3 +504,392 @@ func (m *MysqlDB) RemoveMessagesFromQueue(ctx context.Context) error {
 
 	return nil
 }
+
+// FetchDeadQueues fetches the messages held in the DLQ table per topic
+func (m *MysqlDB) FetchDeadQueues(ctx context.Context) (*DeadQueue, error) {
+	stmt := `SELECT D.topicId,D.partitionId,M.messageId,IFNULL(M.messageKey,''),IFNULL(M.contentType,''),IFNULL(M.headers,''),M.data,M.createdAt,M.expiredAt,IFNULL(D.reason,''),COALESCE(D.deadAt,M.expiredAt,M.createdAt) 
+				FROM DLQ as D JOIN Message as M 
+				ON D.messageId = M.messageId ORDER BY D.deadAt`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	t := map[string][]DeadMessage{}
+
+	for row.Next() {
+		var topicID, headers string
+		var expiresAt sql.NullTime
+		m := DeadMessage{}
+
+		if err := row.Scan(&topicID, &m.Partition, &m.MessageID, &m.Key, &m.ContentType, &headers, &m.Data, &m.CretedAt, &expiresAt, &m.Reason, &m.DeadAt); err != nil {
+			return nil, err
+		}
+		m.ExpiresAt = expiresAt.Time
+
+		if m.Headers, err = decodeHeaders(headers); err != nil {
+			return nil, err
+		}
+		t[topicID] = append(t[topicID], m)
+	}
+
+	return &DeadQueue{Topic: t}, nil
+}
+
+// RemoveMessagesFromDLQ clear DLQ table
+func (m *MysqlDB) RemoveMessagesFromDLQ(ctx context.Context) error {
+	stmt := `DELETE FROM DLQ`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchSubscriberOffsets fetches the read offset of every subscriber per partition of the subscribed topics and of the
+// topics matching its patterns followed by the read offset of every consumer group per partition, subscriptions without
+// a stored offset are returned once with an offset of -1
+func (m *MysqlDB) FetchSubscriberOffsets(ctx context.Context) (*[]SubscriberOffset, error) {
+	offsets := []SubscriberOffset{}
+
+	stmt := `SELECT S.subscriberId,S.topicId,IFNULL(O.partitionId,0),IFNULL(O.messageOffset,-1) 
+				FROM SubscriberTopicMap AS S LEFT JOIN SubscriberOffset AS O 
+				ON S.subscriberId = O.subscriberId AND S.topicId = O.topicId
+				WHERE S.groupName = ''`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	for row.Next() {
+		o := SubscriberOffset{}
+		if err := row.Scan(&o.SubscriberID, &o.TopicID, &o.Partition, &o.Offset); err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, o)
+	}
+
+	// pattern subscribers have no row in SubscriberTopicMap for the topics matching their patterns
+	stmt = `SELECT O.subscriberId,O.topicId,O.partitionId,O.messageOffset 
+				FROM SubscriberOffset AS O 
+				WHERE O.subscriberId IN (SELECT subscriberId FROM SubscriberPatternMap) 
+				AND NOT EXISTS (SELECT 1 FROM SubscriberTopicMap AS S WHERE S.subscriberId = O.subscriberId AND S.topicId = O.topicId)`
+
+	patternRow, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer patternRow.Close()
+
+	for patternRow.Next() {
+		o := SubscriberOffset{}
+		if err := patternRow.Scan(&o.SubscriberID, &o.TopicID, &o.Partition, &o.Offset); err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, o)
+	}
+
+	stmt = `SELECT G.groupName,G.topicId,IFNULL(O.partitionId,0),IFNULL(O.messageOffset,-1) 
+				FROM (SELECT DISTINCT groupName,topicId FROM SubscriberTopicMap WHERE groupName <> '') AS G 
+				LEFT JOIN ConsumerGroupOffset AS O ON G.groupName = O.groupName AND G.topicId = O.topicId`
+
+	groupRow, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer groupRow.Close()
+
+	for groupRow.Next() {
+		o := SubscriberOffset{}
+		if err := groupRow.Scan(&o.Group, &o.TopicID, &o.Partition, &o.Offset); err != nil {
+			return nil, err
+		}
+		offsets = append(offsets, o)
+	}
+
+	return &offsets, nil
+}
+
+// SaveSubscriberOffsets persists the read offset of every subscriber and consumer group per partition of a topic
+func (m *MysqlDB) SaveSubscriberOffsets(ctx context.Context, offsets *[]SubscriberOffset) error {
+	stmt := `INSERT INTO SubscriberOffset (subscriberId,topicId,partitionId,messageOffset) VALUES (?,?,?,?)`
+	groupStmt := `INSERT INTO ConsumerGroupOffset (groupName,topicId,partitionId,messageOffset) VALUES (?,?,?,?)`
+
+	for _, o := range *offsets {
+		var err error
+		if o.Group != "" {
+			_, err = m.Cxn.ExecContext(ctx, groupStmt, o.Group, o.TopicID, o.Partition, o.Offset)
+		} else {
+			_, err = m.Cxn.ExecContext(ctx, stmt, o.SubscriberID, o.TopicID, o.Partition, o.Offset)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RemoveSubscriberOffsets clear SubscriberOffset and ConsumerGroupOffset tables
+func (m *MysqlDB) RemoveSubscriberOffsets(ctx context.Context) error {
+	for _, stmt := range []string{`DELETE FROM SubscriberOffset`, `DELETE FROM ConsumerGroupOffset`} {
+		_, err := m.Cxn.ExecContext(ctx, stmt)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// InsertTopic inserts new topic with the given number of partitions, ttl and limits into Topic table
+func (m *MysqlDB) InsertTopic(ctx context.Context, topicID string, topicName string, partitions int, ttl TopicTTL, limits TopicLimits) error {
+	stmt := `INSERT INTO Topic (topicId,name,partitions,defaultTtl,maxTtl,maxMessages,maxBytes,overflowPolicy) VALUES (?,?,?,?,?,?,?,?)`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, topicID, topicName, partitions, ttl.DefaultTTL, ttl.MaxTTL, limits.MaxMessages, limits.MaxBytes, limits.Overflow)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchTopicPartitions fetches the number of partitions of every topic from Topic table
+func (m *MysqlDB) FetchTopicPartitions(ctx context.Context) (map[string]int, error) {
+	stmt := `SELECT topicId,partitions FROM Topic`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	partitions := map[string]int{}
+
+	for row.Next() {
+		var topicID string
+		var n int
+		if err := row.Scan(&topicID, &n); err != nil {
+			return nil, err
+		}
+		partitions[topicID] = n
+	}
+
+	return partitions, nil
+}
+
+// RenameTopic updates the name of the topic in Topic table
+func (m *MysqlDB) RenameTopic(ctx context.Context, topicID string, topicName string) error {
+	stmt := `UPDATE Topic SET name = ? WHERE topicId = ?`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, topicName, topicID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetTopicTTL gets the default and maximum time to live of the messages of the topic from Topic table
+func (m *MysqlDB) GetTopicTTL(ctx context.Context, topicID string) (*TopicTTL, error) {
+	ttl := &TopicTTL{}
+
+	stmt := `SELECT defaultTtl,maxTtl FROM Topic WHERE topicId = ?`
+
+	err := m.Cxn.QueryRowContext(ctx, stmt, topicID).Scan(&ttl.DefaultTTL, &ttl.MaxTTL)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	return ttl, nil
+}
+
+// UpdateTopicTTL updates the default and maximum time to live of the messages of the topic in Topic table
+func (m *MysqlDB) UpdateTopicTTL(ctx context.Context, topicID string, ttl TopicTTL) error {
+	stmt := `UPDATE Topic SET defaultTtl = ?, maxTtl = ? WHERE topicId = ?`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, ttl.DefaultTTL, ttl.MaxTTL, topicID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// FetchTopicLimits fetches the limits and overflow policy of every topic from Topic table
+func (m *MysqlDB) FetchTopicLimits(ctx context.Context) (map[string]TopicLimits, error) {
+	stmt := `SELECT topicId,maxMessages,maxBytes,overflowPolicy FROM Topic`
+
+	row, err := m.Cxn.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	defer row.Close()
+
+	limits := map[string]TopicLimits{}
+
+	for row.Next() {
+		var topicID string
+		var l TopicLimits
+		if err := row.Scan(&topicID, &l.MaxMessages, &l.MaxBytes, &l.Overflow); err != nil {
+			return nil, err
+		}
+		limits[topicID] = l
+	}
+
+	return limits, nil
+}
+
+// UpdateTopicLimits updates the limits and overflow policy of the topic in Topic table
+func (m *MysqlDB) UpdateTopicLimits(ctx context.Context, topicID string, limits TopicLimits) error {
+	stmt := `UPDATE Topic SET maxMessages = ?, maxBytes = ?, overflowPolicy = ? WHERE topicId = ?`
+
+	_, err := m.Cxn.ExecContext(ctx, stmt, limits.MaxMessages, limits.MaxBytes, limits.Overflow, topicID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTopic removes the topic along with its messages, subscriptions, offsets and publisher connections
+func (m *MysqlDB) DeleteTopic(ctx context.Context, topicID string) error {
+	stmts := []string{
+		`DELETE FROM SubscriberOffset WHERE topicId = ?`,
+		`DELETE FROM ConsumerGroupOffset WHERE topicId = ?`,
+		`DELETE FROM SubscriberTopicMap WHERE topicId = ?`,
+		`DELETE FROM Queue WHERE topicId = ?`,
+		`DELETE FROM DLQ WHERE topicId = ?`,
+		`DELETE FROM Message WHERE topicId = ?`,
+		`DELETE FROM PublisherTopicMap WHERE topicId = ?`,
+		`DELETE FROM Topic WHERE topicId = ?`,
+	}
+
+	tx, err := m.Cxn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt, topicID); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetTopicStats counts the publishers and subscribers registered to the topic
+func (m *MysqlDB) GetTopicStats(ctx context.Context, topicID string) (*TopicStats, error) {
+	stats := &TopicStats{}
+
+	stmt := `SELECT (SELECT COUNT(*) FROM PublisherTopicMap WHERE topicId = ?),
+				(SELECT COUNT(*) FROM SubscriberTopicMap WHERE topicId = ?)`
+
+	err := m.Cxn.QueryRowContext(ctx, stmt, topicID, topicID).Scan(&stats.Publishers, &stats.Subscribers)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// InsertClient inserts new client into Client table and returns the clientId given to it
+func (m *MysqlDB) InsertClient(ctx context.Context, client Client) (int, error) {
+	stmt := `INSERT INTO Client (name,role,apiKeyHash) VALUES (?,?,?)`
+
+	result, err := m.Cxn.ExecContext(ctx, stmt, client.Name, client.Role, client.APIKeyHash)
+	if err != nil {
+		return 0, err
+	}
+
+	clientID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(clientID), nil
+}
+
+// GetClientByAPIKey gets the client from Client table based on the hash of its api key
+func (m *MysqlDB) GetClientByAPIKey(ctx context.Context, apiKeyHash string) (*Client, bool, error) {
+	client := &Client{}
+
+	stmt := `SELECT clientId,name,role,apiKeyHash FROM Client WHERE apiKeyHash = ?`
+
+	err := m.Cxn.QueryRowContext(ctx, stmt, apiKeyHash).Scan(&client.ClientID, &client.Name, &client.Role, &client.APIKeyHash)
+	if err == sql.ErrNoRows {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return client, false, nil
+}
+
+// GetClientByName gets the client from Client table based on its name
+func (m *MysqlDB) GetClientByName(ctx context.Context, name string) (*Client, bool, error) {
+	client := &Client{}
+
+	stmt := `SELECT clientId,name,role,apiKeyHash FROM Client WHERE name = ?`
+
+	err := m.Cxn.QueryRowContext(ctx, stmt, name).Scan(&client.ClientID, &client.Name, &client.Role, &client.APIKeyHash)
+	if err == sql.ErrNoRows {
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	return client, false, nil
+}
+
+// nullTime stores the zero time, a message that never expires, as NULL
+func nullTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
+	}
+	return t
+}
+
+// encodeHeaders encodes the headers of a message as json for the headers column, no headers are stored as an empty string
+func encodeHeaders(headers map[string]string) (string, error) {
+	if len(headers) == 0 {
+		return "", nil
+	}
+
+	b, err := json.Marshal(headers)
+	if err != nil {
+		return "", errors.Wrap(err, "could not encode message headers")
+	}
+
+	return string(b), nil
+}
+
+// decodeHeaders decodes the headers column of a message
+func decodeHeaders(headers string) (map[string]string, error) {
+	if headers == "" {
+		return nil, nil
+	}
+
+	h := map[string]string{}
+	if err := json.Unmarshal([]byte(headers), &h); err != nil {
+		return nil, errors.Wrap(err, "could not decode message headers")
+	}
+
+	return h, nil
+}
@@ -6,54 +6,38 @@ import (
 	"errors"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/WinnersonKharsunai/GraduationProject/server/internal/storage"
 )
 
-func TestGetTopicIDFromPublisher_Fail(t *testing.T) {
-	publisherID := 5000
-	expectedErr := errors.New("failed to get publisherId")
+func TestGetTopicIDFromTopic_Fail(t *testing.T) {
+	topicName := "Golang"
+	expectedErr := errors.New("failed to get topicId")
 
 	mock, db := mysqlMock()
-	stmt := `SELECT IFNULL\(topicId,""\) FROM Publisher WHERE publisherId = \?`
+	stmt := `SELECT topicId FROM MessagingQueue.Topic where name = \?`
 	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	_, _, err := db.GetTopicIDFromPublisher(context.Background(), publisherID)
+	_, err := db.GetTopicIDFromTopic(context.Background(), topicName)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestGetTopicIDFromPublisher_NotFoundFail(t *testing.T) {
-	publisherID := 5000
-
-	mock, db := mysqlMock()
-	stmt := `SELECT IFNULL\(topicId,""\) FROM Publisher WHERE publisherId = \?`
-	mock.ExpectQuery(stmt).WillReturnError(sql.ErrNoRows)
-
-	_, notFound, err := db.GetTopicIDFromPublisher(context.Background(), publisherID)
-	if err != nil {
-		t.Fatalf("expected: nil, got: %v", err)
-	}
-
-	if notFound != true {
-		t.Fatalf("expected: notFound, got: %v", notFound)
-	}
-}
-
-func TestGetTopicIDFromPublisher_Pass(t *testing.T) {
-	publisherID := 5000
+func TestGetTopicIDFromTopic_Pass(t *testing.T) {
+	topicName := "Golang"
 	mock, db := mysqlMock()
 
 	columns := []string{"topicId"}
 	rows := sqlmock.NewRows(columns)
 	rows.AddRow("12345")
 
-	stmt := `SELECT IFNULL\(topicId,""\) FROM Publisher WHERE publisherId = \?`
+	stmt := `SELECT topicId FROM MessagingQueue.Topic where name = \?`
 	mock.ExpectQuery(stmt).WillReturnRows(rows)
 
-	topicID, _, err := db.GetTopicIDFromPublisher(context.Background(), publisherID)
+	topicID, err := db.GetTopicIDFromTopic(context.Background(), topicName)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
@@ -63,202 +47,226 @@ func TestGetTopicIDFromPublisher_Pass(t *testing.T) {
 	}
 }
 
-func TestUpdateTopicIDIntoPublisher_Fail(t *testing.T) {
-	publisherID := 5000
-	topicID := "12345"
-	expectedErr := errors.New("failed to update")
+func TestFetchQueues_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch")
 
 	mock, db := mysqlMock()
-	stmt := `UPDATE Publisher SET topicId = \? WHERE publisherId = \?`
-	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+	stmt := `SELECT Q.topicId,Q.partitionId,Q.messageOffset,M.messageId,IFNULL\(M.messageKey,''\),IFNULL\(M.contentType,''\),IFNULL\(M.headers,''\),M.data,M.createdAt,M.expiredAt 
+				FROM Queue as Q JOIN Message as M 
+				ON Q.messageId = M.messageId ORDER BY Q.messageOffset`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	err := db.UpdateTopicIDIntoPublisher(context.Background(), publisherID, topicID)
+	_, err := db.FetchQueues(context.Background())
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestUpdateTopicIDIntoPublisher_Pass(t *testing.T) {
-	publisherID := 5000
-	topicID := "12345"
-
+func TestFetchQueues_Pass(t *testing.T) {
 	mock, db := mysqlMock()
-	stmt := `UPDATE Publisher SET topicId = \? WHERE publisherId = \?`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.UpdateTopicIDIntoPublisher(context.Background(), publisherID, topicID)
+	want := &storage.Queue{
+		Topic: map[string][]storage.Message{
+			"12345": {
+				{
+					MessageID:   "123",
+					Offset:      7,
+					Partition:   2,
+					Key:         "order-1",
+					ContentType: "application/json",
+					Headers:     map[string]string{"source": "orders"},
+					Data:        []byte("test"),
+					CretedAt:    time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+					ExpiresAt:   time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	columns := []string{"topicId", "partitionId", "messageOffset", "messageId", "messageKey", "contentType", "headers", "data", "createdAt", "expiredAt"}
+	rows := sqlmock.NewRows(columns)
+	rows.AddRow("12345", 2, 7, "123", "order-1", "application/json", `{"source":"orders"}`, []byte("test"), time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC))
+
+	stmt := `SELECT Q.topicId,Q.partitionId,Q.messageOffset,M.messageId,IFNULL\(M.messageKey,''\),IFNULL\(M.contentType,''\),IFNULL\(M.headers,''\),M.data,M.createdAt,M.expiredAt 
+				FROM Queue as Q JOIN Message as M 
+				ON Q.messageId = M.messageId ORDER BY Q.messageOffset`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	queue, err := db.FetchQueues(context.Background())
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
+
+	if !reflect.DeepEqual(queue, want) {
+		t.Fatalf("expected: %v, got: %v", want, queue)
+	}
 }
 
-func TestGetTopicIDFromTopic_Fail(t *testing.T) {
-	topicName := "Golang"
-	expectedErr := errors.New("failed to get topicId")
+func TestFetchAllTopics_Fail(t *testing.T) {
+	publisherID := 5000
+	expectedErr := errors.New("failed to fetch")
 
 	mock, db := mysqlMock()
-	stmt := `SELECT topicId FROM MessagingQueue.Topic where name = \?`
+	stmt := `SELECT name FROM Topic`
 	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	_, err := db.GetTopicIDFromTopic(context.Background(), topicName)
+	_, err := db.FetchAllTopics(context.Background(), publisherID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestGetTopicIDFromTopic_Pass(t *testing.T) {
-	topicName := "Golang"
+func TestFetchAllTopics_Pass(t *testing.T) {
+	publisherID := 5000
 	mock, db := mysqlMock()
 
-	columns := []string{"topicId"}
+	columns := []string{"name"}
 	rows := sqlmock.NewRows(columns)
-	rows.AddRow("12345")
+	rows.AddRow("golang")
 
-	stmt := `SELECT topicId FROM MessagingQueue.Topic where name = \?`
+	stmt := `SELECT name FROM Topic`
 	mock.ExpectQuery(stmt).WillReturnRows(rows)
 
-	topicID, err := db.GetTopicIDFromTopic(context.Background(), topicName)
+	_, err := db.FetchAllTopics(context.Background(), publisherID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
-
-	if topicID != "12345" {
-		t.Fatalf("expected: topicId = '12345', got: %v", topicID)
-	}
 }
 
-func TestRemoveTopicIDFromPublisher_Fail(t *testing.T) {
+func TestGetPublishedTopics_Fail(t *testing.T) {
 	publisherID := 5000
-	expectedErr := errors.New("failed to update")
+	expectedErr := errors.New("failed to get topics")
 
 	mock, db := mysqlMock()
-	stmt := `UPDATE Publisher SET topicId = NULL WHERE publisherId = \?`
-	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+	stmt := `SELECT T.name FROM Topic as T 
+				JOIN PublisherTopicMap AS P
+				ON T.topicId = P.topicId WHERE P.publisherId = \?`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	err := db.RemoveTopicIDFromPublisher(context.Background(), publisherID)
+	_, err := db.GetPublishedTopics(context.Background(), publisherID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestRemoveTopicIDFromPublisher_Pass(t *testing.T) {
+func TestGetPublishedTopics_Pass(t *testing.T) {
 	publisherID := 5000
-
 	mock, db := mysqlMock()
-	stmt := `UPDATE Publisher SET topicId = NULL WHERE publisherId = \?`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.RemoveTopicIDFromPublisher(context.Background(), publisherID)
+	columns := []string{"name"}
+	rows := sqlmock.NewRows(columns)
+	rows.AddRow("golang")
+	rows.AddRow("java")
+
+	stmt := `SELECT T.name FROM Topic as T 
+				JOIN PublisherTopicMap AS P
+				ON T.topicId = P.topicId WHERE P.publisherId = \?`
+	mock.ExpectQuery(stmt).WithArgs(publisherID).WillReturnRows(rows)
+
+	topics, err := db.GetPublishedTopics(context.Background(), publisherID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
-}
 
-func TestFetchQueues_Fail(t *testing.T) {
-	expectedErr := errors.New("failed to fetch")
+	if !reflect.DeepEqual(topics, []string{"golang", "java"}) {
+		t.Fatalf("expected: %v, got: %v", []string{"golang", "java"}, topics)
+	}
+}
 
+func TestInsertPublisherIDIntoPublisher_Fail(t *testing.T) {
+	publisherID := 5000
+	expectedErr := errors.New("failed to insert publisherId")
 	mock, db := mysqlMock()
-	stmt := `SELECT Q.topicId,M.messageId,M.data,M.createdAt,M.expiredAt 
-				FROM Queue as Q JOIN Message as M 
-				ON Q.messageId = M.messageId`
-	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	_, err := db.FetchQueues(context.Background())
+	columns := []string{"publisherId"}
+	rows := sqlmock.NewRows(columns)
+
+	stmt := `SELECT publisherId FROM Publisher WHERE publisherId = \?`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	stmt = `INSERT INTO Publisher \(publisherId\) VALUES \(\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.InsertPublisherIDIntoPublisher(context.Background(), publisherID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestFetchQueues_Pass(t *testing.T) {
+func TestInsertPublisherIDIntoPublisher_Pass(t *testing.T) {
+	publisherID := 5000
 	mock, db := mysqlMock()
 
-	want := &storage.Queue{
-		Topic: map[string][]storage.Message{
-			"12345": {
-				{
-					MessageID: "123",
-					Data:      "test",
-					CretedAt:  "2021-02-27 20:03:09",
-					ExpiresAt: "2021-02-27 20:04:09",
-				},
-			},
-		},
-	}
-
-	columns := []string{"topicId", "messageId", "data", "createdAt", "expiredAt"}
+	columns := []string{"publisherId"}
 	rows := sqlmock.NewRows(columns)
-	rows.AddRow("12345", "123", "test", "2021-02-27 20:03:09", "2021-02-27 20:04:09")
+	rows.AddRow(publisherID)
 
-	stmt := `SELECT Q.topicId,M.messageId,M.data,M.createdAt,M.expiredAt 
-				FROM Queue as Q JOIN Message as M 
-				ON Q.messageId = M.messageId`
+	stmt := `SELECT publisherId FROM Publisher WHERE publisherId = \?`
 	mock.ExpectQuery(stmt).WillReturnRows(rows)
 
-	queue, err := db.FetchQueues(context.Background())
+	err := db.InsertPublisherIDIntoPublisher(context.Background(), publisherID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
 
-	if !reflect.DeepEqual(queue, want) {
-		t.Fatalf("expected: %v, got: %v", want, queue)
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
 	}
 }
 
-func TestFetchAllTopics_Fail(t *testing.T) {
+func TestInsertIntoPublisherTopicMap_Fail(t *testing.T) {
 	publisherID := 5000
-	expectedErr := errors.New("failed to fetch")
+	topicID := "12345"
+	expectedErr := errors.New("failed to insert")
 
 	mock, db := mysqlMock()
-	stmt := `SELECT name FROM Topic`
-	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+	stmt := `INSERT INTO PublisherTopicMap \(publisherId,topicId\) VALUES \(\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
 
-	_, err := db.FetchAllTopics(context.Background(), publisherID)
+	err := db.InsertIntoPublisherTopicMap(context.Background(), publisherID, topicID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestFetchAllTopics_Pass(t *testing.T) {
+func TestInsertIntoPublisherTopicMap_Pass(t *testing.T) {
 	publisherID := 5000
-	mock, db := mysqlMock()
-
-	columns := []string{"name"}
-	rows := sqlmock.NewRows(columns)
-	rows.AddRow("golang")
+	topicID := "12345"
 
-	stmt := `SELECT name FROM Topic`
-	mock.ExpectQuery(stmt).WillReturnRows(rows)
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO PublisherTopicMap \(publisherId,topicId\) VALUES \(\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs(publisherID, topicID).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	_, err := db.FetchAllTopics(context.Background(), publisherID)
+	err := db.InsertIntoPublisherTopicMap(context.Background(), publisherID, topicID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
 }
 
-func TestInsertPublisher_Fail(t *testing.T) {
+func TestRemoveTopicIDFromPublisherTopicMap_Fail(t *testing.T) {
 	publisherID := 5000
-	topicName := "Test"
-	expectedErr := errors.New("failed to insert")
+	topicID := "12345"
+	expectedErr := errors.New("failed to delete")
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Publisher \(publisherId, topicId\) VALUES \(\?,\?\)`
+	stmt := `DELETE FROM PublisherTopicMap WHERE publisherId = \? AND topicId = \?`
 	mock.ExpectExec(stmt).WillReturnError(expectedErr)
 
-	err := db.InsertPublisher(context.Background(), publisherID, topicName)
+	err := db.RemoveTopicIDFromPublisherTopicMap(context.Background(), publisherID, topicID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestInsertPublisher_Pass(t *testing.T) {
+func TestRemoveTopicIDFromPublisherTopicMap_Pass(t *testing.T) {
 	publisherID := 5000
-	topicName := "Test"
+	topicID := "12345"
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Publisher \(publisherId, topicId\) VALUES \(\?,\?\)`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+	stmt := `DELETE FROM PublisherTopicMap WHERE publisherId = \? AND topicId = \?`
+	mock.ExpectExec(stmt).WithArgs(publisherID, topicID).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.InsertPublisher(context.Background(), publisherID, topicName)
+	err := db.RemoveTopicIDFromPublisherTopicMap(context.Background(), publisherID, topicID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
@@ -269,14 +277,14 @@ func TestInsertMessageIntoMessage_Fail(t *testing.T) {
 	topicID := "12345"
 	message := storage.Message{
 		MessageID: "123",
-		Data:      "test data",
-		CretedAt:  "2021-02-27 20:03:09",
-		ExpiresAt: "2021-02-27 20:04:09",
+		Data:      []byte("test data"),
+		CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+		ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 	}
 	expectedErr := errors.New("failed to insert")
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Message \(messageId,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?\)`
+	stmt := `INSERT INTO Message \(messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?,\?,\?,\?\)`
 	mock.ExpectExec(stmt).WillReturnError(expectedErr)
 
 	err := db.InsertMessageIntoMessage(context.Background(), publisherID, topicID, message)
@@ -290,13 +298,13 @@ func TestInsertMessageIntoMessage_Pass(t *testing.T) {
 	topicID := "12345"
 	message := storage.Message{
 		MessageID: "123",
-		Data:      "test data",
-		CretedAt:  "2021-02-27 20:03:09",
-		ExpiresAt: "2021-02-27 20:04:09",
+		Data:      []byte("test data"),
+		CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+		ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
 	}
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Message \(messageId,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?\)`
+	stmt := `INSERT INTO Message \(messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?,\?,\?,\?\)`
 	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := db.InsertMessageIntoMessage(context.Background(), publisherID, topicID, message)
@@ -305,6 +313,49 @@ func TestInsertMessageIntoMessage_Pass(t *testing.T) {
 	}
 }
 
+func TestInsertMessagesIntoMessage_Fail(t *testing.T) {
+	publisherID := 5000
+	topicID := "12345"
+	messages := []storage.Message{
+		{MessageID: "123", Data: []byte("test data 1"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+	}
+	expectedErr := errors.New("failed to insert")
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO Message \(messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.InsertMessagesIntoMessage(context.Background(), publisherID, topicID, messages)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestInsertMessagesIntoMessage_Pass(t *testing.T) {
+	publisherID := 5000
+	topicID := "12345"
+	messages := []storage.Message{
+		{MessageID: "123", Key: "order-1", Headers: map[string]string{"source": "orders"}, Data: []byte("test data 1"), CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+		{MessageID: "124", ContentType: "application/octet-stream", Data: []byte{0x00, 0xff}, CretedAt: time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC)},
+	}
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO Message \(messageId,messageKey,contentType,headers,data,createdAt,expiredAt,pubId,topicId\) VALUES \(\?,\?,\?,\?,\?,\?,\?,\?,\?\),\(\?,\?,\?,\?,\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).
+		WithArgs("123", "order-1", "", `{"source":"orders"}`, []byte("test data 1"), time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC), publisherID, topicID,
+			"124", "", "application/octet-stream", "", []byte{0x00, 0xff}, time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC), publisherID, topicID).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+
+	err := db.InsertMessagesIntoMessage(context.Background(), publisherID, topicID, messages)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
 func TestGetSubscribedTopics_Fail(t *testing.T) {
 	subscriberID := 6000
 	expectedErr := errors.New("failed to get topics")
@@ -399,10 +450,10 @@ func TestInsertIntoSubscriberTopicMap_Fail(t *testing.T) {
 	expectedErr := errors.New("failed to insert")
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO SubscriberTopicMap \(subscriberId,topicId\) VALUES \(\?,\?\)`
+	stmt := `INSERT INTO SubscriberTopicMap \(subscriberId,topicId,filter,groupName\) VALUES \(\?,\?,\?,\?\)`
 	mock.ExpectExec(stmt).WillReturnError(expectedErr)
 
-	err := db.InsertIntoSubscriberTopicMap(context.Background(), subscriberID, topicID)
+	err := db.InsertIntoSubscriberTopicMap(context.Background(), subscriberID, topicID, storage.Subscription{})
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
@@ -413,128 +464,767 @@ func TestInsertIntoSubscriberTopicMap_Pass(t *testing.T) {
 	topicID := "12345"
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO SubscriberTopicMap \(subscriberId,topicId\) VALUES \(\?,\?\)`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+	stmt := `INSERT INTO SubscriberTopicMap \(subscriberId,topicId,filter,groupName\) VALUES \(\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs(subscriberID, topicID, "region = 'eu'", "billing").WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.InsertIntoSubscriberTopicMap(context.Background(), subscriberID, topicID)
+	err := db.InsertIntoSubscriberTopicMap(context.Background(), subscriberID, topicID, storage.Subscription{Filter: "region = 'eu'", GroupName: "billing"})
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
 }
 
-func TestRemoveTopicIDFromSubscriberTopicMap_Fail(t *testing.T) {
+func TestGetSubscription_Fail(t *testing.T) {
 	subscriberID := 6000
 	topicID := "12345"
-	expectedErr := errors.New("failed to delete")
+	expectedErr := errors.New("failed to get subscription")
 
 	mock, db := mysqlMock()
-	stmt := `DELETE FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
-	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+	stmt := `SELECT filter,groupName FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	err := db.RemoveTopicIDFromSubscriberTopicMap(context.Background(), subscriberID, topicID)
+	_, err := db.GetSubscription(context.Background(), subscriberID, topicID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestRemoveTopicIDFromSubscriberTopicMap_Pass(t *testing.T) {
+func TestGetSubscription_Pass(t *testing.T) {
 	subscriberID := 6000
 	topicID := "12345"
-
 	mock, db := mysqlMock()
-	stmt := `DELETE FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.RemoveTopicIDFromSubscriberTopicMap(context.Background(), subscriberID, topicID)
+	rows := sqlmock.NewRows([]string{"filter", "groupName"})
+	rows.AddRow("region = 'eu'", "billing")
+
+	stmt := `SELECT filter,groupName FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
+	mock.ExpectQuery(stmt).WithArgs(subscriberID, topicID).WillReturnRows(rows)
+
+	got, err := db.GetSubscription(context.Background(), subscriberID, topicID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
-}
 
-func TestSaveQueues_FailedToInsertToQueue(t *testing.T) {
-	liveQueue := &[]storage.StoreQueue{
-		{
-			QueuID:    "queue",
-			TopicID:   "12334",
-			MessageID: "message123",
-		},
+	expected := &storage.Subscription{Filter: "region = 'eu'", GroupName: "billing"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
 	}
+}
 
-	expectedErr := errors.New("failed to insert to Queue")
+func TestGetConsumerGroups_Fail(t *testing.T) {
+	topicID := "12345"
+	expectedErr := errors.New("failed to get consumer groups")
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Queue \(queueId,topicId,messageId\) VALUES \(\?,\?,\?\)`
-	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+	stmt := `SELECT groupName,subscriberId FROM SubscriberTopicMap WHERE topicId = \? AND groupName <> ''`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	err := db.SaveQueues(context.Background(), liveQueue, true)
+	_, err := db.GetConsumerGroups(context.Background(), topicID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestSaveQueues_FailedToInsertToDLQ(t *testing.T) {
-	deadQueue := &[]storage.StoreQueue{
-		{
-			QueuID:    "queue",
-			TopicID:   "12334",
-			MessageID: "message123",
-		},
+func TestGetConsumerGroups_Pass(t *testing.T) {
+	topicID := "12345"
+	mock, db := mysqlMock()
+
+	rows := sqlmock.NewRows([]string{"groupName", "subscriberId"})
+	rows.AddRow("billing", 6000)
+	rows.AddRow("billing", 7000)
+
+	stmt := `SELECT groupName,subscriberId FROM SubscriberTopicMap WHERE topicId = \? AND groupName <> ''`
+	mock.ExpectQuery(stmt).WithArgs(topicID).WillReturnRows(rows)
+
+	got, err := db.GetConsumerGroups(context.Background(), topicID)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
 	}
 
-	expectedErr := errors.New("failed to insert to Queue")
+	expected := []storage.ConsumerGroupMember{{GroupName: "billing", SubscriberID: 6000}, {GroupName: "billing", SubscriberID: 7000}}
+	if !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestRemoveTopicIDFromSubscriberTopicMap_Fail(t *testing.T) {
+	subscriberID := 6000
+	topicID := "12345"
+	expectedErr := errors.New("failed to delete")
 
 	mock, db := mysqlMock()
-	dlqStmt := `INSERT INTO DLQ \(dlqId,topicId,messageId\) VALUES \(\?,\?,\?\)`
-	mock.ExpectExec(dlqStmt).WillReturnError(expectedErr)
+	stmt := `DELETE FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
 
-	err := db.SaveQueues(context.Background(), deadQueue, false)
+	err := db.RemoveTopicIDFromSubscriberTopicMap(context.Background(), subscriberID, topicID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestSaveQueues_Pass(t *testing.T) {
-	liveQueue := &[]storage.StoreQueue{
-		{
-			QueuID:    "queue",
-			TopicID:   "12334",
-			MessageID: "message123",
-		},
-	}
+func TestRemoveTopicIDFromSubscriberTopicMap_Pass(t *testing.T) {
+	subscriberID := 6000
+	topicID := "12345"
 
 	mock, db := mysqlMock()
-	stmt := `INSERT INTO Queue \(queueId,topicId,messageId\) VALUES \(\?,\?,\?\)`
+	stmt := `DELETE FROM SubscriberTopicMap WHERE subscriberId = \? AND topicId = \?`
 	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.SaveQueues(context.Background(), liveQueue, true)
+	err := db.RemoveTopicIDFromSubscriberTopicMap(context.Background(), subscriberID, topicID)
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
 }
 
-func TestRemoveMessagesFromQueue_Fail(t *testing.T) {
-	expectedErr := errors.New("failed to delete")
+func TestGetSubscribedPatterns_Fail(t *testing.T) {
+	subscriberID := 6000
+	expectedErr := errors.New("failed to get patterns")
 
 	mock, db := mysqlMock()
-	stmt := `DELETE FROM Queue`
-	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+	stmt := `SELECT pattern FROM SubscriberPatternMap WHERE subscriberId = \?`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
 
-	err := db.RemoveMessagesFromQueue(context.Background())
+	_, err := db.GetSubscribedPatterns(context.Background(), subscriberID)
 	if err.Error() != expectedErr.Error() {
 		t.Fatalf("expected: %v, got: %v", expectedErr, err)
 	}
 }
 
-func TestRemoveMessagesFromQueue_Pass(t *testing.T) {
-
+func TestGetSubscribedPatterns_Pass(t *testing.T) {
+	subscriberID := 6000
 	mock, db := mysqlMock()
-	stmt := `DELETE FROM Queue`
-	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := db.RemoveMessagesFromQueue(context.Background())
+	rows := sqlmock.NewRows([]string{"pattern"})
+	rows.AddRow("orders.*")
+	rows.AddRow("billing.#")
+
+	stmt := `SELECT pattern FROM SubscriberPatternMap WHERE subscriberId = \?`
+	mock.ExpectQuery(stmt).WithArgs(subscriberID).WillReturnRows(rows)
+
+	expected := []string{"orders.*", "billing.#"}
+
+	got, err := db.GetSubscribedPatterns(context.Background(), subscriberID)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestGetSubscriptionPatterns_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to get patterns")
+
+	mock, db := mysqlMock()
+	stmt := `SELECT subscriberId,pattern FROM SubscriberPatternMap`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+
+	_, err := db.GetSubscriptionPatterns(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestGetSubscriptionPatterns_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	rows := sqlmock.NewRows([]string{"subscriberId", "pattern"})
+	rows.AddRow(6000, "orders.*")
+	rows.AddRow(7000, "#.created")
+
+	stmt := `SELECT subscriberId,pattern FROM SubscriberPatternMap`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	expected := []storage.SubscriptionPattern{
+		{SubscriberID: 6000, Pattern: "orders.*"},
+		{SubscriberID: 7000, Pattern: "#.created"},
+	}
+
+	got, err := db.GetSubscriptionPatterns(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestInsertIntoSubscriberPatternMap_Fail(t *testing.T) {
+	subscriberID := 6000
+	pattern := "orders.*"
+	expectedErr := errors.New("failed to insert")
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO SubscriberPatternMap \(subscriberId,pattern\) VALUES \(\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.InsertIntoSubscriberPatternMap(context.Background(), subscriberID, pattern)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestInsertIntoSubscriberPatternMap_Pass(t *testing.T) {
+	subscriberID := 6000
+	pattern := "orders.*"
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO SubscriberPatternMap \(subscriberId,pattern\) VALUES \(\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs(subscriberID, pattern).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.InsertIntoSubscriberPatternMap(context.Background(), subscriberID, pattern)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestRemovePatternFromSubscriberPatternMap_Fail(t *testing.T) {
+	subscriberID := 6000
+	pattern := "orders.*"
+	expectedErr := errors.New("failed to delete")
+
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM SubscriberPatternMap WHERE subscriberId = \? AND pattern = \?`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.RemovePatternFromSubscriberPatternMap(context.Background(), subscriberID, pattern)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestRemovePatternFromSubscriberPatternMap_Pass(t *testing.T) {
+	subscriberID := 6000
+	pattern := "orders.*"
+
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM SubscriberPatternMap WHERE subscriberId = \? AND pattern = \?`
+	mock.ExpectExec(stmt).WithArgs(subscriberID, pattern).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.RemovePatternFromSubscriberPatternMap(context.Background(), subscriberID, pattern)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestSaveQueues_FailedToInsertToQueue(t *testing.T) {
+	liveQueue := &[]storage.StoreQueue{
+		{
+			QueuID:    "queue",
+			TopicID:   "12334",
+			MessageID: "message123",
+		},
+	}
+
+	expectedErr := errors.New("failed to insert to Queue")
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO Queue \(queueId,topicId,partitionId,messageId,messageOffset\) VALUES \(\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.SaveQueues(context.Background(), liveQueue, true)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestSaveQueues_FailedToInsertToDLQ(t *testing.T) {
+	deadQueue := &[]storage.StoreQueue{
+		{
+			QueuID:    "queue",
+			TopicID:   "12334",
+			MessageID: "message123",
+		},
+	}
+
+	expectedErr := errors.New("failed to insert to Queue")
+
+	mock, db := mysqlMock()
+	dlqStmt := `INSERT INTO DLQ \(dlqId,topicId,partitionId,messageId,reason,deadAt\) VALUES \(\?,\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(dlqStmt).WillReturnError(expectedErr)
+
+	err := db.SaveQueues(context.Background(), deadQueue, false)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestSaveQueues_Pass(t *testing.T) {
+	liveQueue := &[]storage.StoreQueue{
+		{
+			QueuID:    "queue",
+			TopicID:   "12334",
+			MessageID: "message123",
+		},
+	}
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO Queue \(queueId,topicId,partitionId,messageId,messageOffset\) VALUES \(\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.SaveQueues(context.Background(), liveQueue, true)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestRemoveMessagesFromQueue_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to delete")
+
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM Queue`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.RemoveMessagesFromQueue(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestRemoveMessagesFromQueue_Pass(t *testing.T) {
+
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM Queue`
+	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.RemoveMessagesFromQueue(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestSaveQueues_DLQPass(t *testing.T) {
+	deadQueue := &[]storage.StoreQueue{
+		{
+			QueuID:    "queue",
+			TopicID:   "12334",
+			Partition: 1,
+			MessageID: "message123",
+			Reason:    "expired",
+			DeadAt:    time.Date(2021, 2, 27, 20, 4, 10, 0, time.UTC),
+		},
+	}
+
+	mock, db := mysqlMock()
+	dlqStmt := `INSERT INTO DLQ \(dlqId,topicId,partitionId,messageId,reason,deadAt\) VALUES \(\?,\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(dlqStmt).WithArgs("queue", "12334", 1, "message123", "expired", time.Date(2021, 2, 27, 20, 4, 10, 0, time.UTC)).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.SaveQueues(context.Background(), deadQueue, false)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestFetchDeadQueues_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch")
+
+	mock, db := mysqlMock()
+	stmt := `SELECT D.topicId,D.partitionId,M.messageId,IFNULL\(M.messageKey,''\),IFNULL\(M.contentType,''\),IFNULL\(M.headers,''\),M.data,M.createdAt,M.expiredAt,IFNULL\(D.reason,''\),COALESCE\(D.deadAt,M.expiredAt,M.createdAt\) 
+				FROM DLQ as D JOIN Message as M 
+				ON D.messageId = M.messageId ORDER BY D.deadAt`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+
+	_, err := db.FetchDeadQueues(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestFetchDeadQueues_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	want := &storage.DeadQueue{
+		Topic: map[string][]storage.DeadMessage{
+			"12345": {
+				{
+					MessageID: "message123",
+					Partition: 1,
+					Data:      []byte("test data"),
+					CretedAt:  time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC),
+					ExpiresAt: time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC),
+					Reason:    "expired",
+					DeadAt:    time.Date(2021, 2, 27, 20, 4, 10, 0, time.UTC),
+				},
+			},
+		},
+	}
+
+	columns := []string{"topicId", "partitionId", "messageId", "messageKey", "contentType", "headers", "data", "createdAt", "expiredAt", "reason", "deadAt"}
+	rows := sqlmock.NewRows(columns)
+	rows.AddRow("12345", 1, "message123", "", "", "", []byte("test data"), time.Date(2021, 2, 27, 20, 3, 9, 0, time.UTC), time.Date(2021, 2, 27, 20, 4, 9, 0, time.UTC), "expired", time.Date(2021, 2, 27, 20, 4, 10, 0, time.UTC))
+
+	stmt := `SELECT D.topicId,D.partitionId,M.messageId,IFNULL\(M.messageKey,''\),IFNULL\(M.contentType,''\),IFNULL\(M.headers,''\),M.data,M.createdAt,M.expiredAt,IFNULL\(D.reason,''\),COALESCE\(D.deadAt,M.expiredAt,M.createdAt\) 
+				FROM DLQ as D JOIN Message as M 
+				ON D.messageId = M.messageId ORDER BY D.deadAt`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	deadQueue, err := db.FetchDeadQueues(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(deadQueue, want) {
+		t.Fatalf("expected: %v, got: %v", want, deadQueue)
+	}
+}
+
+func TestRemoveMessagesFromDLQ_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM DLQ`
+	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.RemoveMessagesFromDLQ(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestFetchSubscriberOffsets_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch")
+
+	mock, db := mysqlMock()
+	stmt := `SELECT S.subscriberId,S.topicId,IFNULL\(O.partitionId,0\),IFNULL\(O.messageOffset,-1\) 
+				FROM SubscriberTopicMap AS S LEFT JOIN SubscriberOffset AS O 
+				ON S.subscriberId = O.subscriberId AND S.topicId = O.topicId`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+
+	_, err := db.FetchSubscriberOffsets(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestFetchSubscriberOffsets_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	want := &[]storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      "12345",
+			Offset:       3,
+		},
+		{
+			SubscriberID: 6000,
+			TopicID:      "12345",
+			Partition:    1,
+			Offset:       8,
+		},
+		{
+			SubscriberID: 7000,
+			TopicID:      "67890",
+			Offset:       2,
+		},
+		{
+			Group:   "billing",
+			TopicID: "12345",
+			Offset:  -1,
+		},
+	}
+
+	columns := []string{"subscriberId", "topicId", "partitionId", "messageOffset"}
+	rows := sqlmock.NewRows(columns)
+	rows.AddRow(6000, "12345", 0, 3)
+	rows.AddRow(6000, "12345", 1, 8)
+
+	stmt := `SELECT S.subscriberId,S.topicId,IFNULL\(O.partitionId,0\),IFNULL\(O.messageOffset,-1\) 
+				FROM SubscriberTopicMap AS S LEFT JOIN SubscriberOffset AS O 
+				ON S.subscriberId = O.subscriberId AND S.topicId = O.topicId`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	patternRows := sqlmock.NewRows(columns)
+	patternRows.AddRow(7000, "67890", 0, 2)
+
+	stmt = `SELECT O.subscriberId,O.topicId,O.partitionId,O.messageOffset 
+				FROM SubscriberOffset AS O 
+				WHERE O.subscriberId IN \(SELECT subscriberId FROM SubscriberPatternMap\)`
+	mock.ExpectQuery(stmt).WillReturnRows(patternRows)
+
+	groupRows := sqlmock.NewRows([]string{"groupName", "topicId", "partitionId", "messageOffset"})
+	groupRows.AddRow("billing", "12345", 0, -1)
+
+	stmt = `SELECT G.groupName,G.topicId,IFNULL\(O.partitionId,0\),IFNULL\(O.messageOffset,-1\)`
+	mock.ExpectQuery(stmt).WillReturnRows(groupRows)
+
+	offsets, err := db.FetchSubscriberOffsets(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(offsets, want) {
+		t.Fatalf("expected: %v, got: %v", want, offsets)
+	}
+}
+
+func TestSaveSubscriberOffsets_Fail(t *testing.T) {
+	offsets := &[]storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      "12345",
+			Offset:       3,
+		},
+	}
+
+	expectedErr := errors.New("failed to insert to SubscriberOffset")
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO SubscriberOffset \(subscriberId,topicId,partitionId,messageOffset\) VALUES \(\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WillReturnError(expectedErr)
+
+	err := db.SaveSubscriberOffsets(context.Background(), offsets)
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestSaveSubscriberOffsets_Pass(t *testing.T) {
+	offsets := &[]storage.SubscriberOffset{
+		{
+			SubscriberID: 6000,
+			TopicID:      "12345",
+			Offset:       3,
+		},
+		{
+			Group:     "billing",
+			TopicID:   "12345",
+			Partition: 2,
+			Offset:    5,
+		},
+	}
+
+	mock, db := mysqlMock()
+	stmt := `INSERT INTO SubscriberOffset \(subscriberId,topicId,partitionId,messageOffset\) VALUES \(\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs(6000, "12345", 0, 3).WillReturnResult(sqlmock.NewResult(1, 1))
+	stmt = `INSERT INTO ConsumerGroupOffset \(groupName,topicId,partitionId,messageOffset\) VALUES \(\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs("billing", "12345", 2, 5).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.SaveSubscriberOffsets(context.Background(), offsets)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestRemoveSubscriberOffsets_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+	stmt := `DELETE FROM SubscriberOffset`
+	mock.ExpectExec(stmt).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`DELETE FROM ConsumerGroupOffset`).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err := db.RemoveSubscriberOffsets(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestInsertTopic_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	stmt := `INSERT INTO Topic \(topicId,name,partitions,defaultTtl,maxTtl,maxMessages,maxBytes,overflowPolicy\) VALUES \(\?,\?,\?,\?,\?,\?,\?,\?\)`
+	mock.ExpectExec(stmt).WithArgs("12345", "golang", 4, 60, 3600, 100, 0, "block").WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ttl := storage.TopicTTL{DefaultTTL: 60, MaxTTL: 3600}
+	limits := storage.TopicLimits{MaxMessages: 100, Overflow: "block"}
+
+	err := db.InsertTopic(context.Background(), "12345", "golang", 4, ttl, limits)
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestFetchTopicPartitions_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch")
+
+	mock, db := mysqlMock()
+	stmt := `SELECT topicId,partitions FROM Topic`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+
+	_, err := db.FetchTopicPartitions(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestFetchTopicPartitions_Pass(t *testing.T) {
+	want := map[string]int{"12345": 1, "67890": 4}
+
+	mock, db := mysqlMock()
+	rows := sqlmock.NewRows([]string{"topicId", "partitions"})
+	rows.AddRow("12345", 1)
+	rows.AddRow("67890", 4)
+
+	stmt := `SELECT topicId,partitions FROM Topic`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	partitions, err := db.FetchTopicPartitions(context.Background())
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(partitions, want) {
+		t.Fatalf("expected: %v, got: %v", want, partitions)
+	}
+}
+
+func TestFetchTopicLimits_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to fetch")
+
+	mock, db := mysqlMock()
+	stmt := `SELECT topicId,maxMessages,maxBytes,overflowPolicy FROM Topic`
+	mock.ExpectQuery(stmt).WillReturnError(expectedErr)
+
+	_, err := db.FetchTopicLimits(context.Background())
+	if err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+}
+
+func TestFetchTopicLimits_Pass(t *testing.T) {
+	want := map[string]storage.TopicLimits{
+		"12345": {},
+		"67890": {MaxMessages: 100, MaxBytes: 4096, Overflow: "block"},
+	}
+
+	mock, db := mysqlMock()
+	rows := sqlmock.NewRows([]string{"topicId", "maxMessages", "maxBytes", "overflowPolicy"})
+	rows.AddRow("12345", 0, 0, "")
+	rows.AddRow("67890", 100, 4096, "block")
+
+	stmt := `SELECT topicId,maxMessages,maxBytes,overflowPolicy FROM Topic`
+	mock.ExpectQuery(stmt).WillReturnRows(rows)
+
+	limits, err := db.FetchTopicLimits(context.Background())
 	if err != nil {
 		t.Fatalf("expected: nil, got: %v", err)
 	}
+
+	if !reflect.DeepEqual(limits, want) {
+		t.Fatalf("expected: %v, got: %v", want, limits)
+	}
+}
+
+func TestUpdateTopicLimits_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	stmt := `UPDATE Topic SET maxMessages = \?, maxBytes = \?, overflowPolicy = \? WHERE topicId = \?`
+	mock.ExpectExec(stmt).WithArgs(100, 4096, "block", "12345").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := db.UpdateTopicLimits(context.Background(), "12345", storage.TopicLimits{MaxMessages: 100, MaxBytes: 4096, Overflow: "block"})
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestDeleteTopic_Fail(t *testing.T) {
+	expectedErr := errors.New("failed to delete messages")
+	mock, db := mysqlMock()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM SubscriberOffset WHERE topicId = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM ConsumerGroupOffset WHERE topicId = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM SubscriberTopicMap WHERE topicId = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM Queue WHERE topicId = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM DLQ WHERE topicId = \?`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM Message WHERE topicId = \?`).WillReturnError(expectedErr)
+	mock.ExpectRollback()
+
+	err := db.DeleteTopic(context.Background(), "12345")
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Fatalf("expected: %v, got: %v", expectedErr, err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestDeleteTopic_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM SubscriberOffset WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM ConsumerGroupOffset WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM SubscriberTopicMap WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM Queue WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM DLQ WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM Message WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM PublisherTopicMap WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM Topic WHERE topicId = \?`).WithArgs("12345").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := db.DeleteTopic(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+}
+
+func TestGetTopicStats_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	rows := sqlmock.NewRows([]string{"publishers", "subscribers"}).AddRow(1, 2)
+	mock.ExpectQuery(`SELECT \(SELECT COUNT\(\*\) FROM PublisherTopicMap WHERE topicId = \?\)`).WithArgs("12345", "12345").WillReturnRows(rows)
+
+	expected := &storage.TopicStats{Publishers: 1, Subscribers: 2}
+
+	got, err := db.GetTopicStats(context.Background(), "12345")
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestInsertClient_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	mock.ExpectExec(`INSERT INTO Client \(name,role,apiKeyHash\) VALUES \(\?,\?,\?\)`).WithArgs("orders", "publisher", "hash").WillReturnResult(sqlmock.NewResult(1000, 1))
+
+	clientID, err := db.InsertClient(context.Background(), storage.Client{Name: "orders", Role: "publisher", APIKeyHash: "hash"})
+	if err != nil {
+		t.Fatalf("expected: nil, got: %v", err)
+	}
+
+	if clientID != 1000 {
+		t.Fatalf("expected: %v, got: %v", 1000, clientID)
+	}
+}
+
+func TestGetClientByAPIKey_Pass(t *testing.T) {
+	mock, db := mysqlMock()
+
+	rows := sqlmock.NewRows([]string{"clientId", "name", "role", "apiKeyHash"}).AddRow(1000, "orders", "publisher", "hash")
+	mock.ExpectQuery(`SELECT clientId,name,role,apiKeyHash FROM Client WHERE apiKeyHash = \?`).WithArgs("hash").WillReturnRows(rows)
+
+	expected := &storage.Client{ClientID: 1000, Name: "orders", Role: "publisher", APIKeyHash: "hash"}
+
+	got, notFound, err := db.GetClientByAPIKey(context.Background(), "hash")
+	if err != nil || notFound {
+		t.Fatalf("expected: nil, got: %v %v", err, notFound)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v, got: %v", expected, got)
+	}
+}
+
+func TestGetClientByAPIKey_NotFound(t *testing.T) {
+	mock, db := mysqlMock()
+
+	mock.ExpectQuery(`SELECT clientId,name,role,apiKeyHash FROM Client WHERE apiKeyHash = \?`).WithArgs("hash").WillReturnError(sql.ErrNoRows)
+
+	_, notFound, err := db.GetClientByAPIKey(context.Background(), "hash")
+	if err != nil || !notFound {
+		t.Fatalf("expected: notFound, got: %v %v", err, notFound)
+	}
 }
 
 func mysqlMock() (sqlmock.Sqlmock, storage.MysqlDB) {
@@ -0,0 +1,29 @@
+package wal
+
+// FailWrites makes every later write to the current segment of l write half of the record and fail with err
+func FailWrites(l WriteAheadLogIF, err error) (restore func()) {
+	log := l.(*Log)
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+
+	file := log.file
+	log.file = &failingSegment{segment: file, err: err}
+
+	return func() {
+		log.mu.Lock()
+		defer log.mu.Unlock()
+
+		log.file = file
+	}
+}
+
+type failingSegment struct {
+	segment
+	err error
+}
+
+func (f *failingSegment) Write(p []byte) (int, error) {
+	n, _ := f.segment.Write(p[:len(p)/2])
+	return n, f.err
+}
@@ -0,0 +1,426 @@
+package wal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	segmentExt  = ".wal"
+	snapshotExt = ".snapshot"
+	tmpFile     = "snapshot.tmp"
+	headerSize  = 8
+
+	// maxRecordSize bounds a record well above a message of the largest frame, a header
+	// claiming more than that is torn and is not trusted to allocate the record
+	maxRecordSize = 64 << 20
+)
+
+var (
+	errCorruptedSegment = errors.New("wal: corrupted segment")
+	errInvalidRecord    = errors.New("wal: record has to hold between 1 and 64MiB")
+)
+
+// WriteAheadLogIF is the interface for the write-ahead log
+type WriteAheadLogIF interface {
+	Append(record []byte) error
+	Replay(fn func(record []byte) error) error
+	Compact(snapshot [][]byte) error
+	Segments() int
+	Close() error
+}
+
+// Log is an append-only log of records split over segment files in a directory. Every record is
+// written with its length and crc32 checksum and synced to disk before Append returns.
+// A snapshot written by Compact replaces every segment before it
+type Log struct {
+	dir         string
+	segmentSize int64
+	mu          sync.Mutex
+	file        segment
+	size        int64
+	index       uint64
+	files       []string
+}
+
+// segment is the file records are appended to, it is only something else than an *os.File in tests
+type segment interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+// NewLog opens the log stored in dir, creating it when missing. A record torn by a crash at the
+// end of the last segment is dropped. A new segment is started once the current one reaches segmentSize bytes
+func NewLog(dir string, segmentSize int64) (WriteAheadLogIF, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	l := &Log{
+		dir:         dir,
+		segmentSize: segmentSize,
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// Append writes the record at the end of the log and syncs it to disk
+func (l *Log) Append(record []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return errors.New("wal: log is closed")
+	}
+
+	if !validRecord(len(record)) {
+		return errInvalidRecord
+	}
+
+	if l.size > 0 && l.size+int64(headerSize+len(record)) > l.segmentSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	_, err := l.file.Write(encode(record))
+	if err == nil {
+		err = l.file.Sync()
+	}
+
+	if err != nil {
+		// the failed record is cut off so the next one is not written after a torn record, the segment is
+		// opened in append mode so the next write lands at the end it is cut back to
+		if terr := l.file.Truncate(l.size); terr != nil {
+			// nothing is appended after a torn record, it is dropped when the log is opened again
+			l.file.Close()
+			l.file = nil
+		}
+		return err
+	}
+
+	l.size += int64(headerSize + len(record))
+
+	return nil
+}
+
+// Replay calls fn with every record of the log in the order they were written, starting from the last snapshot
+func (l *Log) Replay(fn func(record []byte) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, name := range l.files {
+		path := filepath.Join(l.dir, name)
+
+		valid, err := scan(path, fn)
+		if err != nil {
+			return err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+
+		if valid < info.Size() && i < len(l.files)-1 {
+			return fmt.Errorf("%v: %v", errCorruptedSegment, name)
+		}
+	}
+
+	return nil
+}
+
+// Compact replaces the whole log with the given snapshot records
+func (l *Log) Compact(snapshot [][]byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	tmp := filepath.Join(l.dir, tmpFile)
+
+	f, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	for _, record := range snapshot {
+		if !validRecord(len(record)) {
+			f.Close()
+			return errInvalidRecord
+		}
+
+		if _, err := w.Write(encode(record)); err != nil {
+			f.Close()
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	snapshotName := fileName(l.index+1, snapshotExt)
+	if err := os.Rename(tmp, filepath.Join(l.dir, snapshotName)); err != nil {
+		return err
+	}
+
+	if err := syncDir(l.dir); err != nil {
+		return err
+	}
+
+	if l.file != nil {
+		l.file.Close()
+		l.file = nil
+	}
+
+	for _, name := range l.files {
+		if err := os.Remove(filepath.Join(l.dir, name)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	l.files = []string{snapshotName}
+	l.index++
+
+	return l.createSegment(l.index + 1)
+}
+
+// Segments returns the number of files the log is made of
+func (l *Log) Segments() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.files)
+}
+
+// Close syncs and closes the current segment
+func (l *Log) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
+
+func (l *Log) open() error {
+	entries, err := ioutil.ReadDir(l.dir)
+	if err != nil {
+		return err
+	}
+
+	type logFile struct {
+		name  string
+		index uint64
+	}
+
+	files := []logFile{}
+	snapshot := uint64(0)
+
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if ext != segmentExt && ext != snapshotExt {
+			continue
+		}
+
+		index, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), ext), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		if ext == snapshotExt && index > snapshot {
+			snapshot = index
+		}
+
+		files = append(files, logFile{name: e.Name(), index: index})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].index < files[j].index
+	})
+
+	// everything before the last snapshot was compacted into it
+	for _, f := range files {
+		if f.index < snapshot || (f.index == snapshot && filepath.Ext(f.name) != snapshotExt) {
+			if err := os.Remove(filepath.Join(l.dir, f.name)); err != nil {
+				return err
+			}
+			continue
+		}
+
+		l.files = append(l.files, f.name)
+		l.index = f.index
+	}
+
+	os.Remove(filepath.Join(l.dir, tmpFile))
+
+	if len(l.files) == 0 || filepath.Ext(l.files[len(l.files)-1]) == snapshotExt {
+		return l.createSegment(l.index + 1)
+	}
+
+	path := filepath.Join(l.dir, l.files[len(l.files)-1])
+
+	valid, err := scan(path, func([]byte) error { return nil })
+	if err != nil {
+		return err
+	}
+
+	if err := os.Truncate(path, valid); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	l.file = f
+	l.size = valid
+
+	return nil
+}
+
+func (l *Log) rotate() error {
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+
+	if err := l.file.Close(); err != nil {
+		return err
+	}
+
+	l.file = nil
+
+	return l.createSegment(l.index + 1)
+}
+
+func (l *Log) createSegment(index uint64) error {
+	name := fileName(index, segmentExt)
+
+	f, err := os.OpenFile(filepath.Join(l.dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := syncDir(l.dir); err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file = f
+	l.size = 0
+	l.index = index
+	l.files = append(l.files, name)
+
+	return nil
+}
+
+// scan calls fn with every intact record of the file and returns the size of the intact part. A header with
+// a length no record can have, such as the zeros left at the end of a segment by a crash, ends the intact part
+func scan(path string, fn func(record []byte) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, headerSize)
+	valid := int64(0)
+
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return valid, nil
+			}
+			return valid, err
+		}
+
+		length := binary.LittleEndian.Uint32(header[0:4])
+		checksum := binary.LittleEndian.Uint32(header[4:8])
+
+		if !validRecord(int(length)) {
+			return valid, nil
+		}
+
+		record := make([]byte, length)
+		if _, err := io.ReadFull(r, record); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return valid, nil
+			}
+			return valid, err
+		}
+
+		if crc32.ChecksumIEEE(record) != checksum {
+			return valid, nil
+		}
+
+		if err := fn(record); err != nil {
+			return valid, err
+		}
+
+		valid += int64(headerSize) + int64(length)
+	}
+}
+
+func validRecord(length int) bool {
+	return length > 0 && length <= maxRecordSize
+}
+
+func encode(record []byte) []byte {
+	buf := make([]byte, headerSize+len(record))
+	binary.LittleEndian.PutUint32(buf[0:4], uint32(len(record)))
+	binary.LittleEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(record))
+	copy(buf[headerSize:], record)
+	return buf
+}
+
+func fileName(index uint64, ext string) string {
+	return fmt.Sprintf("%020d%s", index, ext)
+}
+
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
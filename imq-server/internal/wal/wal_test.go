@@ -0,0 +1,295 @@
+package wal_test
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/wal"
+)
+
+func TestReplay_Pass(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := []string{"record1", "record2", "record3"}
+	for _, r := range expected {
+		if err := l.Append([]byte(r)); err != nil {
+			t.Fatalf("expected: nil \n\t got: %v", err)
+		}
+	}
+	l.Close()
+
+	l, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestReplay_TornRecordDropped(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	l.Append([]byte("record1"))
+	l.Append([]byte("record2"))
+	l.Close()
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	info, _ := os.Stat(segments[0])
+	if err := os.Truncate(segments[0], info.Size()-3); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	l, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Append([]byte("record3")); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := []string{"record1", "record3"}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestReplay_ZeroPaddedSegment(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	l.Append([]byte("record1"))
+	l.Close()
+
+	// a crash can leave the end of a segment allocated but never written
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	f, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	f.Write(make([]byte, 64))
+	f.Close()
+
+	l, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Append([]byte("record2")); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := []string{"record1", "record2"}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestReplay_OversizedRecordDropped(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	l.Append([]byte("record1"))
+	l.Close()
+
+	segments, _ := filepath.Glob(filepath.Join(dir, "*.wal"))
+	f, err := os.OpenFile(segments[0], os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	f.Write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	f.Close()
+
+	l, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	expected := []string{"record1"}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestAppend_EmptyRecordFail(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	if err := l.Append(nil); err == nil {
+		t.Fatalf("expected: %v \n\t got: %v", "error", err)
+	}
+}
+
+func TestAppend_RotatesSegments(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 32)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	expected := []string{}
+	for i := 0; i < 5; i++ {
+		r := fmt.Sprintf("record%d", i)
+		if err := l.Append([]byte(r)); err != nil {
+			t.Fatalf("expected: nil \n\t got: %v", err)
+		}
+		expected = append(expected, r)
+	}
+
+	if l.Segments() != 3 {
+		t.Fatalf("expected: %v \n\t got: %v", 3, l.Segments())
+	}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestAppend_FailedWriteTruncated(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if err := l.Append([]byte("record0")); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expectedErr := errors.New("disk full")
+
+	restore := wal.FailWrites(l, expectedErr)
+	if err := l.Append([]byte("record1")); err != expectedErr {
+		t.Fatalf("expected: %v \n\t got: %v", expectedErr, err)
+	}
+	restore()
+
+	if err := l.Append([]byte("record2")); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	l.Close()
+
+	// the half written record is gone, so the record after it survives a restart
+	l, err = wal.NewLog(dir, 1024)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	expected := []string{"record0", "record2"}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestCompact_Pass(t *testing.T) {
+	dir := getDir(t)
+	defer os.RemoveAll(dir)
+
+	l, err := wal.NewLog(dir, 32)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	for i := 0; i < 5; i++ {
+		l.Append([]byte(fmt.Sprintf("record%d", i)))
+	}
+
+	if err := l.Compact([][]byte{[]byte("record4")}); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	l.Append([]byte("record5"))
+	l.Close()
+
+	l, err = wal.NewLog(dir, 32)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer l.Close()
+
+	if l.Segments() != 2 {
+		t.Fatalf("expected: %v \n\t got: %v", 2, l.Segments())
+	}
+
+	expected := []string{"record4", "record5"}
+
+	got := getRecords(t, l)
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func getDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "wal")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	return dir
+}
+
+func getRecords(t *testing.T, l wal.WriteAheadLogIF) []string {
+	records := []string{}
+	err := l.Replay(func(record []byte) error {
+		records = append(records, string(record))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	return records
+}
@@ -0,0 +1,26 @@
+package protocol
+
+import "errors"
+
+// ErrorCode classifies the error of a response so clients do not have to match its message
+type ErrorCode string
+
+// codes a failed response can carry
+const (
+	CodeNotFound        ErrorCode = "NOT_FOUND"
+	CodeAlreadyExists   ErrorCode = "ALREADY_EXISTS"
+	CodeUnauthorized    ErrorCode = "UNAUTHORIZED"
+	CodeInvalidArgument ErrorCode = "INVALID_ARGUMENT"
+	CodeUnavailable     ErrorCode = "UNAVAILABLE"
+	CodeQueueEmpty      ErrorCode = "QUEUE_EMPTY"
+	CodeQueueFull       ErrorCode = "QUEUE_FULL"
+	CodeInternal        ErrorCode = "INTERNAL"
+)
+
+var (
+	// ErrInvalidVersion is returned when the header carries a version other than Version1 or Version2
+	ErrInvalidVersion = errors.New("invalid header version")
+	// ErrUnsupportedContentType is returned when the header carries a content-type other than json, xml or gob,
+	// or gob over the json protocol
+	ErrUnsupportedContentType = errors.New("unsupported content-type")
+)
@@ -0,0 +1,101 @@
+package protocol_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
+func TestReadRequestFrame_Pass(t *testing.T) {
+	hdr := protocol.Header{Version: protocol.Version2, ContentType: "json", Method: "publishMessageRequest"}
+	body := `{"data":"line 1\nline 2\n"}`
+
+	header, _ := json.Marshal(hdr)
+
+	buf := &bytes.Buffer{}
+	if err := protocol.WriteFrame(buf, header, []byte(body)); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	framed, err := protocol.IsFrame(bufio.NewReader(bytes.NewReader(buf.Bytes())))
+	if err != nil || !framed {
+		t.Fatalf("expected: %v \n\t got: %v %v", true, framed, err)
+	}
+
+	expected := &protocol.Request{Header: hdr, Body: body}
+
+	got, err := protocol.ReadRequestFrame(buf)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(expected, got) {
+		t.Fatalf("expected: %v \n\t got: %v", expected, got)
+	}
+}
+
+func TestWriteResponseFrame_Pass(t *testing.T) {
+	response := &protocol.Response{Event: protocol.EventMessage, Body: []byte("data\n")}
+
+	buf := &bytes.Buffer{}
+	if err := protocol.WriteResponseFrame(buf, response); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	header, body, err := protocol.ReadFrame(buf)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if string(header) != `{"error":"","event":"message"}` || string(body) != "data\n" {
+		t.Fatalf("expected: %v \n\t got: %s %s", response, header, body)
+	}
+}
+
+func TestWriteResponseFrame_ErrorPass(t *testing.T) {
+	response := &protocol.Response{RequestID: "7", Error: "no message present in queue", Code: protocol.CodeQueueEmpty, Retryable: true}
+
+	buf := &bytes.Buffer{}
+	if err := protocol.WriteResponseFrame(buf, response); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	header, _, err := protocol.ReadFrame(buf)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := `{"requestId":"7","error":"no message present in queue","code":"QUEUE_EMPTY","retryable":true}`
+	if string(header) != expected {
+		t.Fatalf("expected: %v \n\t got: %s", expected, header)
+	}
+}
+
+func TestReadFrame_LineFail(t *testing.T) {
+	line := `{"header":{"version":"1.0"},"body":""}` + "\n"
+
+	framed, _ := protocol.IsFrame(bufio.NewReader(bytes.NewReader([]byte(line))))
+	if framed {
+		t.Fatalf("expected: %v \n\t got: %v", false, framed)
+	}
+
+	_, _, err := protocol.ReadFrame(bytes.NewReader([]byte(line)))
+	if err != protocol.ErrInvalidFrame {
+		t.Fatalf("expected: %v \n\t got: %v", protocol.ErrInvalidFrame, err)
+	}
+}
+
+func TestReadFrame_TooLargeFail(t *testing.T) {
+	prefix := []byte{'I', 'M', 'Q', 2, 0, 0, 0, 0, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(prefix[8:], protocol.MaxFrameSize+1)
+
+	_, _, err := protocol.ReadFrame(bytes.NewReader(prefix))
+	if err != protocol.ErrFrameTooLarge {
+		t.Fatalf("expected: %v \n\t got: %v", protocol.ErrFrameTooLarge, err)
+	}
+}
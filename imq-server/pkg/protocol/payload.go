@@ -0,0 +1,84 @@
+package protocol
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"sort"
+)
+
+// Payload holds the binary data of a message, it is carried as base64 in json and xml and as is in gob
+type Payload []byte
+
+// MarshalText encodes the payload as base64, used by json and xml
+func (p Payload) MarshalText() ([]byte, error) {
+	text := make([]byte, base64.StdEncoding.EncodedLen(len(p)))
+	base64.StdEncoding.Encode(text, p)
+	return text, nil
+}
+
+// UnmarshalText decodes the base64 encoded payload, used by json and xml
+func (p *Payload) UnmarshalText(text []byte) error {
+	data := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
+	n, err := base64.StdEncoding.Decode(data, text)
+	if err != nil {
+		return err
+	}
+	*p = data[:n]
+	return nil
+}
+
+// MarshalBinary returns the payload as is, used by gob
+func (p Payload) MarshalBinary() ([]byte, error) {
+	return []byte(p), nil
+}
+
+// UnmarshalBinary copies the payload as is, used by gob
+func (p *Payload) UnmarshalBinary(data []byte) error {
+	*p = append(Payload(nil), data...)
+	return nil
+}
+
+// Headers holds the key/value headers of a message
+type Headers map[string]string
+
+// xmlHeader is a single header of Headers in xml, which cannot encode maps
+type xmlHeader struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// MarshalXML encodes the headers as a list of header elements sorted by key
+func (h Headers) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	headers := struct {
+		Header []xmlHeader `xml:"header"`
+	}{}
+	for _, k := range keys {
+		headers.Header = append(headers.Header, xmlHeader{Key: k, Value: h[k]})
+	}
+
+	return e.EncodeElement(headers, start)
+}
+
+// UnmarshalXML decodes the list of header elements written by MarshalXML
+func (h *Headers) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	headers := struct {
+		Header []xmlHeader `xml:"header"`
+	}{}
+	if err := d.DecodeElement(&headers, &start); err != nil {
+		return err
+	}
+
+	if *h == nil {
+		*h = Headers{}
+	}
+	for _, header := range headers.Header {
+		(*h)[header.Key] = header.Value
+	}
+	return nil
+}
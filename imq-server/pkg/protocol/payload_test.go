@@ -0,0 +1,68 @@
+package protocol_test
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
+type testMessage struct {
+	Headers protocol.Headers `json:"headers,omitempty" xml:"headers,omitempty"`
+	Data    protocol.Payload `json:"data" xml:"data"`
+}
+
+func TestPayload_JSONPass(t *testing.T) {
+	msg := testMessage{Headers: protocol.Headers{"source": "orders"}, Data: []byte{0x00, 0xff, '\n'}}
+
+	b, err := json.Marshal(msg)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := `{"headers":{"source":"orders"},"data":"AP8K"}`
+	if string(b) != expected {
+		t.Fatalf("expected: %v \n\t got: %s", expected, b)
+	}
+
+	got := testMessage{}
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(msg, got) {
+		t.Fatalf("expected: %v \n\t got: %v", msg, got)
+	}
+}
+
+func TestPayload_XMLPass(t *testing.T) {
+	msg := testMessage{Headers: protocol.Headers{"trace": "abc", "source": "orders"}, Data: []byte{0x00, 0xff, '<'}}
+
+	b, err := xml.Marshal(msg)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	expected := `<testMessage><headers><header key="source">orders</header><header key="trace">abc</header></headers><data>AP88</data></testMessage>`
+	if string(b) != expected {
+		t.Fatalf("expected: %v \n\t got: %s", expected, b)
+	}
+
+	got := testMessage{}
+	if err := xml.Unmarshal(b, &got); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if !reflect.DeepEqual(msg, got) {
+		t.Fatalf("expected: %v \n\t got: %v", msg, got)
+	}
+}
+
+func TestPayload_InvalidBase64Fail(t *testing.T) {
+	got := testMessage{}
+	if err := json.Unmarshal([]byte(`{"data":"not base64!"}`), &got); err == nil {
+		t.Fatalf("expected: error \n\t got: %v", err)
+	}
+}
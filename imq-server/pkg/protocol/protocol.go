@@ -1,9 +1,6 @@
 package protocol
 
-import (
-	"context"
-	"errors"
-)
+import "context"
 
 // ProtocolIF is the interface for the protocol
 type ProtocolIF interface {
@@ -12,12 +9,17 @@ type ProtocolIF interface {
 
 // ValidateRequestHeader validates the request header
 func (hdr Header) ValidateRequestHeader(ctx context.Context) error {
-	if hdr.Version != "1.0" {
-		return errors.New("invalid header version")
+	if hdr.Version != Version1 && hdr.Version != Version2 {
+		return ErrInvalidVersion
 	}
 
-	if hdr.ContentType != "json" && hdr.ContentType != "xml" {
-		return errors.New("unsupported content-type")
+	// the body of a gob request is binary and can only be carried by a binary frame
+	if hdr.ContentType == "gob" && hdr.Version != Version2 {
+		return ErrUnsupportedContentType
+	}
+
+	if hdr.ContentType != "json" && hdr.ContentType != "xml" && hdr.ContentType != "gob" {
+		return ErrUnsupportedContentType
 	}
 
 	return nil
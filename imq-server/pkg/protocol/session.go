@@ -0,0 +1,35 @@
+package protocol
+
+import "context"
+
+// Session holds the client a connection has authenticated as
+type Session struct {
+	ClientID int
+	Role     string
+}
+
+type sessionKey struct{}
+
+type peerKey struct{}
+
+// NewSessionContext returns a copy of ctx that carries the given session
+func NewSessionContext(ctx context.Context, session *Session) context.Context {
+	return context.WithValue(ctx, sessionKey{}, session)
+}
+
+// SessionFromContext returns the session carried by ctx, if any
+func SessionFromContext(ctx context.Context) (*Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(*Session)
+	return session, ok
+}
+
+// NewPeerContext returns a copy of ctx that carries the subject of the verified client certificate
+func NewPeerContext(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, peerKey{}, subject)
+}
+
+// PeerFromContext returns the subject of the verified client certificate carried by ctx, if any
+func PeerFromContext(ctx context.Context) (string, bool) {
+	subject, ok := ctx.Value(peerKey{}).(string)
+	return subject, ok
+}
@@ -0,0 +1,22 @@
+package protocol
+
+import "context"
+
+// Stream is used to push responses to a connected client outside of the request/response cycle
+type Stream interface {
+	Push(response *Response) error
+	Done() <-chan struct{}
+}
+
+type streamKey struct{}
+
+// NewStreamContext returns a copy of ctx that carries the given stream
+func NewStreamContext(ctx context.Context, stream Stream) context.Context {
+	return context.WithValue(ctx, streamKey{}, stream)
+}
+
+// StreamFromContext returns the stream carried by ctx, if any
+func StreamFromContext(ctx context.Context) (Stream, bool) {
+	stream, ok := ctx.Value(streamKey{}).(Stream)
+	return stream, ok
+}
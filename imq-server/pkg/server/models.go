@@ -1,15 +1,35 @@
 package server
 
-import "net"
+import (
+	"errors"
+	"time"
+)
 
-type newConnection struct {
-	conn net.Conn
-	err  error
+// Options holds the connection settings of the server
+type Options struct {
+	// MaxConnections bounds the clients connected at the same time, the others are rejected
+	MaxConnections int
+	// ReadTimeout is how long a client has to send the whole of a request once it started, zero waits forever
+	ReadTimeout time.Duration
+	// IdleTimeout closes the connections nothing was read from nor written to for that long, zero keeps them open
+	IdleTimeout time.Duration
 }
 
 const (
-	pub                   = "publisher"
-	sub                   = "subscriber"
 	statusConnected       = "connected"
+	statusGoingAway       = "server is shutting down"
 	failedTowriteResponse = "failed to write response to client"
+
+	// maxInFlightRequests bounds the requests of a connection processed at the same time
+	maxInFlightRequests = 64
+
+	defaultMaxConnections = 1000
+
+	// rejectTimeout bounds the write of the rejection sent to a client over capacity
+	rejectTimeout = 5 * time.Second
+
+	// maxAcceptDelay bounds the wait before accepting again after an accept error that can go away
+	maxAcceptDelay = time.Second
 )
+
+var errAtCapacity = errors.New("server is at capacity, try again later")
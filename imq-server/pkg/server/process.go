@@ -7,59 +7,140 @@ import (
 	"errors"
 	"fmt"
 	"net"
-	"strings"
+	"sync"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
 )
 
-func (s *Server) processWorker(id int) {
-	for con := range s.processCh {
-		s.log.Infof("processWorker with Id %v: get a new connection with: %v", id, con.RemoteAddr().String())
+// processConnection serves a client for as long as it stays connected
+func (s *Server) processConnection(id int, con net.Conn) {
+	s.log.Infof("processConnection with Id %v: get a new connection with: %v", id, con.RemoteAddr().String())
 
-		response := &protocol.Response{}
-		connectionClosed := false
+	subject, err := peerSubject(con)
+	if err != nil {
+		s.log.Errorf("processConnection with Id %v: failed tls handshake: %v", id, err)
+		con.Close()
+		return
+	}
 
-		if s.clientType[getClientPort(con)] == "" {
-			err := errors.New("unauthorised")
-			s.log.Errorf("processWorker with Id %v: failed to recognised client: %v", id, err)
-			response.Error = err.Error()
-			connectionClosed = true
-		}
+	sess := newSession(con, s.opts.ReadTimeout, s.opts.IdleTimeout)
+	s.addSession(id, sess)
+	defer s.removeSession(id)
 
-		response.Body = []byte(statusConnected)
+	response := &protocol.Response{Body: []byte(statusConnected)}
+	connectionClosed := false
+
+	if err := sess.write(response); err != nil {
+		s.log.Errorf("processConnection with Id %v: %v:%v", id, failedTowriteResponse, err)
+	}
+
+	go s.pushWorker(id, sess)
+	ctx := protocol.NewStreamContext(context.Background(), sess)
+	if subject != "" {
+		ctx = protocol.NewPeerContext(ctx, subject)
+	}
 
-		if err := writeToConnection(con, response); err != nil {
-			s.log.Errorf("processWorker with Id %v: %v:%v", id, failedTowriteResponse, err)
+	// the first request of every connection has to authenticate the client
+	request, err := sess.read()
+	if err != nil {
+		if !sess.isGoingAway() {
+			s.log.Errorf("processConnection with Id %v: failed to read client request: %v", id, err)
+		}
+		if errors.Is(err, errMalformedRequest) {
+			sess.write(malformedResponse(err))
+		}
+		connectionClosed = true
+	} else {
+		ctx, response = s.router.Authenticate(ctx, request)
+		response.RequestID = request.Header.RequestID
+		if response.Error != "" {
+			s.log.Errorf("processConnection with Id %v: failed to authenticate client: %v", id, response.Error)
+			connectionClosed = true
 		}
 
-		for !connectionClosed {
-			data, err := readFromConnection(con)
-			if err != nil {
-				s.log.Errorf("processWorker with Id %v: failed to read client request: %v", id, err)
-				connectionClosed = true
-				continue
-			}
+		if err := sess.write(response); err != nil {
+			s.log.Errorf("processConnection with Id %v: %v:%v", id, failedTowriteResponse, err)
+		}
+	}
 
-			response = s.router.RequestRouter(context.Background(), data)
+	// requests carrying a requestId are processed concurrently and answered as soon as they are done,
+	// the others are answered in the order they were sent
+	var inFlight sync.WaitGroup
+	inFlightCh := make(chan struct{}, maxInFlightRequests)
 
-			if err := writeToConnection(con, response); err != nil {
-				s.log.Errorf("processWorker with Id %v: %v:%v", id, failedTowriteResponse, err)
+	for !connectionClosed {
+		request, err := sess.read()
+		if errors.Is(err, errMalformedRequest) {
+			s.writeResponse(id, sess, malformedResponse(err))
+			continue
+		} else if err != nil {
+			if !sess.isGoingAway() {
+				s.log.Errorf("processConnection with Id %v: failed to read client request: %v", id, err)
 			}
+			connectionClosed = true
+			continue
 		}
 
-		s.log.Infof("processWorker with Id %v: closing connection with: %v", id, con.RemoteAddr().String())
-		con.Close()
+		if request.Header.RequestID == "" {
+			s.writeResponse(id, sess, s.router.RequestRouter(ctx, request))
+			continue
+		}
+
+		inFlightCh <- struct{}{}
+		inFlight.Add(1)
+		go func(request *protocol.Request) {
+			defer func() {
+				<-inFlightCh
+				inFlight.Done()
+			}()
+
+			response := s.router.RequestRouter(ctx, request)
+			response.RequestID = request.Header.RequestID
+			s.writeResponse(id, sess, response)
+		}(request)
 	}
-	s.processWg.Done()
+
+	// the client is told the server is going away before the requests still being processed are answered
+	if sess.isGoingAway() {
+		s.writeResponse(id, sess, &protocol.Response{Event: protocol.EventGoingAway, Body: []byte(statusGoingAway)})
+	}
+
+	inFlight.Wait()
+
+	s.log.Infof("processConnection with Id %v: closing connection with: %v", id, con.RemoteAddr().String())
+	sess.close()
 }
 
-func getClientPort(c net.Conn) string {
-	remoteAddr := strings.SplitAfter(c.RemoteAddr().String(), ":")
-	return remoteAddr[1]
+func (s *Server) writeResponse(id int, sess *session, response *protocol.Response) {
+	if err := sess.write(response); err != nil {
+		s.log.Errorf("processConnection with Id %v: %v:%v", id, failedTowriteResponse, err)
+	}
+}
+
+// malformedResponse answers a request that could not be read, the reason is sent as the details
+func malformedResponse(err error) *protocol.Response {
+	return &protocol.Response{
+		Error:   errMalformedRequest.Error(),
+		Code:    protocol.CodeInvalidArgument,
+		Details: err.Error(),
+	}
+}
+
+func (s *Server) pushWorker(id int, sess *session) {
+	for {
+		select {
+		case <-sess.Done():
+			return
+		case response := <-sess.pushCh:
+			if err := sess.write(response); err != nil {
+				s.log.Errorf("pushWorker with Id %v: %v:%v", id, failedTowriteResponse, err)
+			}
+		}
+	}
 }
 
-func readFromConnection(c net.Conn) (string, error) {
-	data, err := bufio.NewReader(c).ReadString('\n')
+func readFromConnection(r *bufio.Reader) (string, error) {
+	data, err := r.ReadString('\n')
 	return data, err
 }
 
@@ -2,105 +2,197 @@ package server
 
 import (
 	"context"
-	"fmt"
+	"errors"
 	"net"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/routes"
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
 	"github.com/sirupsen/logrus"
 )
 
-// Server is the concrete implementation for the Multiclient hanlder
+// Server is the concrete implementation for the Multiclient hanlder, every connection is served by its own goroutine
 type Server struct {
-	log             *logrus.Logger
-	lis             net.Listener
-	router          routes.Router
-	publisherCount  int
-	subscriberCount int
-	clientType      map[string]string
-	newConnection   chan newConnection
-	processCh       chan net.Conn
-	processWg       sync.WaitGroup
-	shutdownCh      chan struct{}
+	log    *logrus.Logger
+	lis    net.Listener
+	router routes.Router
+	opts   Options
+	// slots holds a token for every connection being served, a client finding it full is rejected
+	slots  chan struct{}
+	connWg sync.WaitGroup
+	mu     sync.Mutex
+	nextID int
+	closed bool
+	// sessions holds the connections being served, they are told to go away on shutdown
+	sessions map[int]*session
 }
 
 // NewServer is the factory function for the Server type
-func NewServer(log *logrus.Logger, lis net.Listener, pubCount, subCount int, router routes.Router) *Server {
-	s := &Server{
-		log:             log,
-		lis:             lis,
-		publisherCount:  pubCount,
-		subscriberCount: subCount,
-		router:          router,
-		clientType:      make(map[string]string),
-		shutdownCh:      make(chan struct{}),
-		newConnection:   make(chan newConnection),
-		processCh:       make(chan net.Conn),
+func NewServer(log *logrus.Logger, lis net.Listener, opts Options, router routes.Router) *Server {
+	if opts.MaxConnections <= 0 {
+		opts.MaxConnections = defaultMaxConnections
 	}
 
-	s.processWg.Add(pubCount)
-	for i := 1; i <= pubCount; i++ {
-		s.clientType[fmt.Sprintf("%v", 4999+i)] = pub
-		go s.processWorker(i)
-	}
+	return &Server{
+		log:    log,
+		lis:    lis,
+		router: router,
+		opts:   opts,
+		slots:  make(chan struct{}, opts.MaxConnections),
 
-	s.processWg.Add(subCount)
-	for i := 1; i <= pubCount; i++ {
-		s.clientType[fmt.Sprintf("%v", 5999+i)] = sub
-		go s.processWorker(i)
+		sessions: map[int]*session{},
 	}
-
-	return s
 }
 
-// Serve starts the server
+// Serve accepts clients until the server is shut down
 func (s *Server) Serve() error {
-	shutdown := false
-	for !shutdown {
+	var delay time.Duration
+
+	for {
+		con, err := s.lis.Accept()
+		if err != nil {
+			if s.isClosed() {
+				return nil
+			}
+
+			// a listener closed without a shutdown cannot accept again, retrying it would only spin
+			if errors.Is(err, net.ErrClosed) {
+				return err
+			}
+
+			if retryAccept(err) {
+				delay = acceptDelay(delay)
+				s.log.Errorf("failed to accept client, retrying in %v: %v", delay, err)
+				time.Sleep(delay)
+				continue
+			}
+			return err
+		}
+		delay = 0
+
 		select {
-		case <-s.shutdownCh:
-			shutdown = true
+		case s.slots <- struct{}{}:
 		default:
-			ctx, cancel := context.WithTimeout(context.Background(), time.Duration(5*time.Second))
-			defer cancel()
+			go s.reject(con)
+			continue
+		}
+
+		id, ok := s.track()
+		if !ok {
+			<-s.slots
+			con.Close()
+			return nil
+		}
 
-			go func() {
-				s.accept(ctx)
+		go func() {
+			defer func() {
+				<-s.slots
+				s.connWg.Done()
 			}()
+			s.processConnection(id, con)
+		}()
+	}
+}
 
-			select {
-			case <-ctx.Done():
-			case ch := <-s.newConnection:
-				if ch.err != nil {
-					s.log.Errorf("failed to accept client: %v", ch.err)
-					continue
-				}
-				s.processCh <- ch.conn
-			}
-		}
+// track registers a new connection, it fails once the server is shut down
+func (s *Server) track() (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return 0, false
+	}
+
+	s.nextID++
+	s.connWg.Add(1)
+	return s.nextID, true
+}
+
+// addSession registers the session of a connection, it goes away at once when the server is shut down
+func (s *Server) addSession(id int, sess *session) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		sess.goAway()
+	}
+	s.sessions[id] = sess
+}
+
+func (s *Server) removeSession(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// reject tells a client the server is over capacity and closes its connection
+func (s *Server) reject(con net.Conn) {
+	defer con.Close()
+
+	s.log.Warnf("rejecting client %v: %v", con.RemoteAddr().String(), errAtCapacity)
+
+	con.SetWriteDeadline(time.Now().Add(rejectTimeout))
+	response := &protocol.Response{
+		Error:     errAtCapacity.Error(),
+		Code:      protocol.CodeUnavailable,
+		Retryable: true,
+	}
+	if err := writeToConnection(con, response); err != nil {
+		s.log.Errorf("failed to reject client %v: %v", con.RemoteAddr().String(), err)
 	}
-	return nil
 }
 
-func (s *Server) accept(ctx context.Context) {
-	con, err := s.lis.Accept()
-	s.newConnection <- newConnection{
-		conn: con,
-		err:  err,
+// retryAccept reports whether accepting again can succeed after err, as after a timeout or
+// running out of file descriptors while the connections being served are closed
+func retryAccept(err error) bool {
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return true
 	}
+
+	return errors.Is(err, syscall.EMFILE) || errors.Is(err, syscall.ENFILE)
 }
 
-// Shutdown gracefully shutdown the server
+// acceptDelay doubles the previous delay, up to maxAcceptDelay
+func acceptDelay(delay time.Duration) time.Duration {
+	if delay == 0 {
+		return 5 * time.Millisecond
+	}
+
+	delay *= 2
+	if delay > maxAcceptDelay {
+		delay = maxAcceptDelay
+	}
+	return delay
+}
+
+// Shutdown stops accepting clients and tells the connected ones the server is going away, their connections are
+// closed once the requests being processed are answered. The connections still open when ctx is done are closed
 func (s *Server) Shutdown(ctx context.Context) error {
-	done := make(chan struct{})
+	s.mu.Lock()
+	s.closed = true
+	for _, sess := range s.sessions {
+		sess.goAway()
+	}
+	s.mu.Unlock()
 
-	go func() {
-		close(s.shutdownCh)
+	if err := s.lis.Close(); err != nil {
+		s.log.Warnf("failed to close listener: %v", err)
+	}
 
-		close(s.processCh)
-		s.processWg.Wait()
+	done := make(chan struct{})
 
+	go func() {
+		s.connWg.Wait()
 		close(done)
 	}()
 
@@ -108,6 +200,13 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	case <-done:
 		return nil
 	case <-ctx.Done():
-		return ctx.Err()
 	}
+
+	s.mu.Lock()
+	for _, sess := range s.sessions {
+		sess.close()
+	}
+	s.mu.Unlock()
+
+	return ctx.Err()
 }
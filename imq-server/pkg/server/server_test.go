@@ -0,0 +1,459 @@
+package server_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/routes"
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/server"
+	"github.com/sirupsen/logrus"
+)
+
+// testRouter answers slowRequest only once fastRequest has been answered
+type testRouter struct {
+	fastDone chan struct{}
+}
+
+func (r *testRouter) Authenticate(ctx context.Context, request *protocol.Request) (context.Context, *protocol.Response) {
+	return ctx, &protocol.Response{Body: []byte("authenticated")}
+}
+
+func (r *testRouter) RequestRouter(ctx context.Context, request *protocol.Request) *protocol.Response {
+	switch request.Header.Method {
+	case "slowRequest":
+		select {
+		case <-r.fastDone:
+		case <-time.After(5 * time.Second):
+		}
+	case "fastRequest":
+		close(r.fastDone)
+	}
+	return &protocol.Response{Body: []byte(request.Header.Method)}
+}
+
+// blockingRouter answers blockingRequest once released, started is signalled when it is received
+type blockingRouter struct {
+	started chan struct{}
+	release chan struct{}
+}
+
+func (r *blockingRouter) Authenticate(ctx context.Context, request *protocol.Request) (context.Context, *protocol.Response) {
+	return ctx, &protocol.Response{Body: []byte("authenticated")}
+}
+
+func (r *blockingRouter) RequestRouter(ctx context.Context, request *protocol.Request) *protocol.Response {
+	if request.Header.Method == "blockingRequest" {
+		r.started <- struct{}{}
+		<-r.release
+	}
+	return &protocol.Response{Body: []byte(request.Header.Method)}
+}
+
+func TestServe_PipelinedRequests_Pass(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	s := server.NewServer(&logrus.Logger{}, lis, server.Options{}, &testRouter{fastDone: make(chan struct{})})
+	go s.Serve()
+
+	con, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer con.Close()
+
+	reader := bufio.NewReader(con)
+	readResponse(t, reader)
+
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "authenticateRequest"})
+	if got := readResponse(t, reader); string(got.Body) != "authenticated" {
+		t.Fatalf("expected: %v \n\t got: %v", "authenticated", got)
+	}
+
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "slowRequest", RequestID: "1"})
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "fastRequest", RequestID: "2"})
+
+	first := readResponse(t, reader)
+	second := readResponse(t, reader)
+
+	if first.RequestID != "2" || string(first.Body) != "fastRequest" {
+		t.Fatalf("expected: %v \n\t got: %v %s", "2", first.RequestID, first.Body)
+	}
+
+	if second.RequestID != "1" || string(second.Body) != "slowRequest" {
+		t.Fatalf("expected: %v \n\t got: %v %s", "1", second.RequestID, second.Body)
+	}
+}
+
+func TestServe_ManyClients_Pass(t *testing.T) {
+	s, addr := startServer(t, server.Options{MaxConnections: 300}, &testRouter{fastDone: make(chan struct{})})
+	defer shutdown(s)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 200)
+
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			con, err := net.Dial("tcp", addr)
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer con.Close()
+
+			reader := bufio.NewReader(con)
+			if _, err := reader.ReadString('\n'); err != nil {
+				errs <- err
+				return
+			}
+
+			b, _ := json.Marshal(protocol.Request{Header: protocol.Header{Version: "1.0", Method: "authenticateRequest"}})
+			if _, err := con.Write(append(b, '\n')); err != nil {
+				errs <- err
+				return
+			}
+
+			if _, err := reader.ReadString('\n'); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestServe_AtCapacityFail(t *testing.T) {
+	s, addr := startServer(t, server.Options{MaxConnections: 1}, &testRouter{fastDone: make(chan struct{})})
+	defer shutdown(s)
+
+	first, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if got := readResponse(t, bufio.NewReader(first)); got.Error != "" {
+		t.Fatalf("expected: %v \n\t got: %v", "", got.Error)
+	}
+
+	second, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer second.Close()
+
+	got := readResponse(t, bufio.NewReader(second))
+	if got.Code != protocol.CodeUnavailable || !got.Retryable {
+		t.Fatalf("expected: %v \n\t got: %v", protocol.CodeUnavailable, got)
+	}
+
+	// the slot of a client is given back once it disconnects
+	first.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		con, err := net.Dial("tcp", addr)
+		if err != nil {
+			t.Fatalf("expected: nil \n\t got: %v", err)
+		}
+
+		got := readResponse(t, bufio.NewReader(con))
+		con.Close()
+
+		if got.Error == "" {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatalf("expected: %v \n\t got: %v", "", got.Error)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestServe_IdleTimeout(t *testing.T) {
+	s, addr := startServer(t, server.Options{IdleTimeout: 100 * time.Millisecond}, &testRouter{fastDone: make(chan struct{})})
+	defer shutdown(s)
+
+	con, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer con.Close()
+
+	reader := bufio.NewReader(con)
+	readResponse(t, reader)
+
+	con.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadString('\n'); err != io.EOF {
+		t.Fatalf("expected: %v \n\t got: %v", io.EOF, err)
+	}
+}
+
+func TestServe_ReadTimeout(t *testing.T) {
+	s, addr := startServer(t, server.Options{ReadTimeout: 100 * time.Millisecond}, &testRouter{fastDone: make(chan struct{})})
+	defer shutdown(s)
+
+	con, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer con.Close()
+
+	reader := bufio.NewReader(con)
+	readResponse(t, reader)
+
+	// a request that never ends
+	if _, err := con.Write([]byte(`{"header":`)); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	con.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadString('\n'); err != io.EOF {
+		t.Fatalf("expected: %v \n\t got: %v", io.EOF, err)
+	}
+}
+
+// flakyListener fails the accepts with the queued errors before accepting from the wrapped listener
+type flakyListener struct {
+	net.Listener
+	errs chan error
+}
+
+func (l *flakyListener) Accept() (net.Conn, error) {
+	select {
+	case err := <-l.errs:
+		return nil, err
+	default:
+		return l.Listener.Accept()
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "accept timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestServe_AcceptTimeoutRetried(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	errs := make(chan error, 2)
+	errs <- timeoutError{}
+	errs <- timeoutError{}
+
+	s := server.NewServer(&logrus.Logger{}, &flakyListener{Listener: lis, errs: errs}, server.Options{}, &testRouter{fastDone: make(chan struct{})})
+	go s.Serve()
+	defer shutdown(s)
+
+	con, err := net.Dial("tcp", lis.Addr().String())
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer con.Close()
+
+	con.SetReadDeadline(time.Now().Add(5 * time.Second))
+	readResponse(t, bufio.NewReader(con))
+}
+
+func TestServe_ListenerClosedFail(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	s := server.NewServer(&logrus.Logger{}, lis, server.Options{}, &testRouter{fastDone: make(chan struct{})})
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve()
+	}()
+
+	lis.Close()
+
+	select {
+	case err := <-served:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Fatalf("expected: %v \n\t got: %v", net.ErrClosed, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected: %v \n\t got: %v", "Serve to return", "timeout")
+	}
+}
+
+func TestShutdown_StopsAccepting(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	s := server.NewServer(&logrus.Logger{}, lis, server.Options{}, &testRouter{fastDone: make(chan struct{})})
+
+	served := make(chan error, 1)
+	go func() {
+		served <- s.Serve()
+	}()
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	select {
+	case err := <-served:
+		if err != nil {
+			t.Fatalf("expected: nil \n\t got: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("expected: %v \n\t got: %v", "Serve to return", "timeout")
+	}
+
+	if con, err := net.Dial("tcp", lis.Addr().String()); err == nil {
+		con.Close()
+		t.Fatalf("expected: %v \n\t got: %v", "error", err)
+	}
+}
+
+func TestShutdown_DrainsInFlightRequests(t *testing.T) {
+	router := &blockingRouter{started: make(chan struct{}), release: make(chan struct{})}
+	s, addr := startServer(t, server.Options{}, router)
+
+	con, reader := connect(t, addr)
+	defer con.Close()
+
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "blockingRequest", RequestID: "1"})
+	<-router.started
+
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- s.Shutdown(context.Background())
+	}()
+
+	// the client is told first, the request being processed is still answered
+	if got := readResponse(t, reader); got.Event != protocol.EventGoingAway {
+		t.Fatalf("expected: %v \n\t got: %v", protocol.EventGoingAway, got)
+	}
+
+	select {
+	case err := <-shutdownErr:
+		t.Fatalf("expected: %v \n\t got: %v", "shutdown to wait", err)
+	default:
+	}
+
+	close(router.release)
+
+	if got := readResponse(t, reader); got.RequestID != "1" || string(got.Body) != "blockingRequest" {
+		t.Fatalf("expected: %v \n\t got: %v %s", "1", got.RequestID, got.Body)
+	}
+
+	con.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadString('\n'); err != io.EOF {
+		t.Fatalf("expected: %v \n\t got: %v", io.EOF, err)
+	}
+
+	if err := <-shutdownErr; err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func TestShutdown_ForceCloseFail(t *testing.T) {
+	router := &blockingRouter{started: make(chan struct{}), release: make(chan struct{})}
+	defer close(router.release)
+
+	s, addr := startServer(t, server.Options{}, router)
+
+	con, reader := connect(t, addr)
+	defer con.Close()
+
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "blockingRequest", RequestID: "1"})
+	<-router.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := s.Shutdown(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected: %v \n\t got: %v", context.DeadlineExceeded, err)
+	}
+
+	if got := readResponse(t, reader); got.Event != protocol.EventGoingAway {
+		t.Fatalf("expected: %v \n\t got: %v", protocol.EventGoingAway, got)
+	}
+
+	// the connection is closed without the answer of the request
+	con.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := reader.ReadString('\n'); err != io.EOF {
+		t.Fatalf("expected: %v \n\t got: %v", io.EOF, err)
+	}
+}
+
+func connect(t *testing.T, addr string) (net.Conn, *bufio.Reader) {
+	con, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	reader := bufio.NewReader(con)
+	readResponse(t, reader)
+
+	writeRequest(t, con, protocol.Header{Version: "1.0", Method: "authenticateRequest"})
+	readResponse(t, reader)
+
+	return con, reader
+}
+
+func startServer(t *testing.T, opts server.Options, router routes.Router) (*server.Server, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	s := server.NewServer(&logrus.Logger{}, lis, opts, router)
+	go s.Serve()
+
+	return s, lis.Addr().String()
+}
+
+func shutdown(s *server.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	s.Shutdown(ctx)
+}
+
+func writeRequest(t *testing.T, con net.Conn, hdr protocol.Header) {
+	b, _ := json.Marshal(protocol.Request{Header: hdr})
+	if _, err := con.Write(append(b, '\n')); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func readResponse(t *testing.T, reader *bufio.Reader) *protocol.Response {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	response := &protocol.Response{}
+	if err := json.Unmarshal([]byte(line), response); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	return response
+}
@@ -0,0 +1,204 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/protocol"
+)
+
+const pushBufferSize = 256
+
+var (
+	errSessionClosed    = errors.New("session closed")
+	errPushBufferFull   = errors.New("push buffer full")
+	errMalformedRequest = errors.New("malformed request")
+	errGoingAway        = errors.New("session going away")
+)
+
+// session wraps a client connection so that responses and pushed messages can share it,
+// the framing of the connection is fixed by the first request the client sends
+type session struct {
+	// lastActive is the time in unix nanoseconds something was last read from or written to the connection
+	lastActive  int64
+	goingAway   int32
+	readTimeout time.Duration
+	idleTimeout time.Duration
+	conn        net.Conn
+	reader      *bufio.Reader
+	writeMu     sync.Mutex
+	negotiated  bool
+	framed      bool
+	pushCh      chan *protocol.Response
+	done        chan struct{}
+	closeOnce   sync.Once
+}
+
+func newSession(conn net.Conn, readTimeout, idleTimeout time.Duration) *session {
+	return &session{
+		lastActive:  time.Now().UnixNano(),
+		readTimeout: readTimeout,
+		idleTimeout: idleTimeout,
+		conn:        conn,
+		reader:      bufio.NewReader(conn),
+		pushCh:      make(chan *protocol.Response, pushBufferSize),
+		done:        make(chan struct{}),
+	}
+}
+
+// Push queues the response to be written to the client without blocking the caller
+func (s *session) Push(response *protocol.Response) error {
+	select {
+	case <-s.done:
+		return errSessionClosed
+	default:
+	}
+
+	select {
+	case s.pushCh <- response:
+		return nil
+	default:
+		return errPushBufferFull
+	}
+}
+
+// Done is closed once the session is closed
+func (s *session) Done() <-chan struct{} {
+	return s.done
+}
+
+// read reads the next request, errors wrapping errMalformedRequest leave the stream usable
+func (s *session) read() (*protocol.Request, error) {
+	if err := s.waitForRequest(); err != nil {
+		return nil, err
+	}
+	s.setReadTimeout(s.readTimeout)
+
+	if !s.negotiated {
+		framed, err := protocol.IsFrame(s.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		s.writeMu.Lock()
+		s.framed = framed
+		s.negotiated = true
+		s.writeMu.Unlock()
+	}
+
+	if s.framed {
+		request, err := protocol.ReadRequestFrame(s.reader)
+		if err != nil {
+			return nil, err
+		}
+
+		if request.Header.Version != protocol.Version2 {
+			return nil, fmt.Errorf("%w: binary frames require version %v", errMalformedRequest, protocol.Version2)
+		}
+		return request, nil
+	}
+
+	data, err := readFromConnection(s.reader)
+	if err != nil {
+		return nil, err
+	}
+
+	request := &protocol.Request{}
+	if err := json.Unmarshal([]byte(data), request); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMalformedRequest, err)
+	}
+
+	if request.Header.Version == protocol.Version2 {
+		return nil, fmt.Errorf("%w: version %v requires binary frames", errMalformedRequest, protocol.Version2)
+	}
+	return request, nil
+}
+
+// waitForRequest blocks until the next request starts arriving, failing once the connection has been idle
+// for the idle timeout, pushed messages keep a connection from being idle
+func (s *session) waitForRequest() error {
+	s.touch()
+
+	for {
+		if s.idleTimeout > 0 {
+			last := time.Unix(0, atomic.LoadInt64(&s.lastActive))
+			s.conn.SetReadDeadline(last.Add(s.idleTimeout))
+		} else {
+			s.conn.SetReadDeadline(time.Time{})
+		}
+
+		// checked once the deadline is set so that it cannot override the one set by goAway
+		if s.isGoingAway() {
+			return errGoingAway
+		}
+
+		_, err := s.reader.Peek(1)
+		if err == nil {
+			return nil
+		}
+
+		if s.isGoingAway() {
+			return errGoingAway
+		}
+
+		// the deadline may have passed while a message was being pushed
+		var ne net.Error
+		if errors.As(err, &ne) && ne.Timeout() && s.idleTimeout > 0 && !s.idle() {
+			continue
+		}
+		return err
+	}
+}
+
+// setReadTimeout bounds the time left to read from the connection, a zero timeout waits forever
+func (s *session) setReadTimeout(timeout time.Duration) {
+	if timeout <= 0 {
+		s.conn.SetReadDeadline(time.Time{})
+		return
+	}
+	s.conn.SetReadDeadline(time.Now().Add(timeout))
+}
+
+// goAway stops the session from waiting for further requests, a request being read may be dropped
+func (s *session) goAway() {
+	atomic.StoreInt32(&s.goingAway, 1)
+	s.conn.SetReadDeadline(time.Now())
+}
+
+func (s *session) isGoingAway() bool {
+	return atomic.LoadInt32(&s.goingAway) == 1
+}
+
+func (s *session) touch() {
+	atomic.StoreInt64(&s.lastActive, time.Now().UnixNano())
+}
+
+func (s *session) idle() bool {
+	last := time.Unix(0, atomic.LoadInt64(&s.lastActive))
+	return time.Since(last) >= s.idleTimeout
+}
+
+func (s *session) write(response *protocol.Response) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.touch()
+
+	if s.framed {
+		return protocol.WriteResponseFrame(s.conn, response)
+	}
+	return writeToConnection(s.conn, response)
+}
+
+func (s *session) close() {
+	s.closeOnce.Do(func() {
+		close(s.done)
+		s.conn.Close()
+	})
+}
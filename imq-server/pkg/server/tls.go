@@ -0,0 +1,73 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"time"
+)
+
+const handshakeTimeout = 10 * time.Second
+
+// NewTLSConfig loads the certificate of the server, when clientCAFile is given the client
+// certificates signed by it are verified and, if requireClientCert is set, demanded
+func NewTLSConfig(certFile, keyFile, clientCAFile string, requireClientCert bool) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if clientCAFile == "" {
+		if requireClientCert {
+			return nil, errors.New("client certificates cannot be required without a client CA")
+		}
+		return cfg, nil
+	}
+
+	pem, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, errors.New("no certificate found in client CA file")
+	}
+
+	cfg.ClientCAs = pool
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	if requireClientCert {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+
+	return cfg, nil
+}
+
+// peerSubject completes the handshake of a TLS connection and returns the common name of
+// the verified client certificate, it is empty for plain connections or clients without one
+func peerSubject(con net.Conn) (string, error) {
+	tlsCon, ok := con.(*tls.Conn)
+	if !ok {
+		return "", nil
+	}
+
+	tlsCon.SetDeadline(time.Now().Add(handshakeTimeout))
+	if err := tlsCon.Handshake(); err != nil {
+		return "", err
+	}
+	tlsCon.SetDeadline(time.Time{})
+
+	state := tlsCon.ConnectionState()
+	if len(state.VerifiedChains) == 0 {
+		return "", nil
+	}
+
+	return state.VerifiedChains[0][0].Subject.CommonName, nil
+}
@@ -0,0 +1,142 @@
+package server_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/pkg/server"
+)
+
+func TestNewTLSConfig_MutualTLS_Pass(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca, caKey := newCertificate(t, "imq-ca", nil, nil)
+	serverCert, serverKey := newCertificate(t, "localhost", ca, caKey)
+	clientCert, clientKey := newCertificate(t, "orders", ca, caKey)
+
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", ca.Raw)
+	writePEM(t, filepath.Join(dir, "server.pem"), "CERTIFICATE", serverCert.Raw)
+	writeKey(t, filepath.Join(dir, "server-key.pem"), serverKey)
+
+	cfg, err := server.NewTLSConfig(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), filepath.Join(dir, "ca.pem"), true)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+
+	clientCfg := &tls.Config{
+		RootCAs:    roots,
+		ServerName: "localhost",
+		Certificates: []tls.Certificate{{
+			Certificate: [][]byte{clientCert.Raw},
+			PrivateKey:  clientKey,
+		}},
+	}
+
+	serverSide, clientSide := net.Pipe()
+	srv := tls.Server(serverSide, cfg)
+	cli := tls.Client(clientSide, clientCfg)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- cli.Handshake()
+	}()
+
+	if err := srv.Handshake(); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	state := srv.ConnectionState()
+	if len(state.VerifiedChains) == 0 || state.VerifiedChains[0][0].Subject.CommonName != "orders" {
+		t.Fatalf("expected: %v \n\t got: %v", "orders", state.PeerCertificates)
+	}
+}
+
+func TestNewTLSConfig_RequireClientCertWithoutCA_Fail(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	cert, key := newCertificate(t, "localhost", nil, nil)
+	writePEM(t, filepath.Join(dir, "server.pem"), "CERTIFICATE", cert.Raw)
+	writeKey(t, filepath.Join(dir, "server-key.pem"), key)
+
+	_, err = server.NewTLSConfig(filepath.Join(dir, "server.pem"), filepath.Join(dir, "server-key.pem"), "", true)
+	if err == nil {
+		t.Fatalf("expected: error \n\t got: nil")
+	}
+}
+
+// newCertificate creates a certificate for the common name signed by parent, or a self-signed CA when parent is nil
+func newCertificate(t *testing.T, commonName string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+
+	return cert, key
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	if err := ioutil.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+}
+
+func writeKey(t *testing.T, path string, key *ecdsa.PrivateKey) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("expected: nil \n\t got: %v", err)
+	}
+	writePEM(t, path, "EC PRIVATE KEY", der)
+}
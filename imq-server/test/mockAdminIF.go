@@ -0,0 +1,73 @@
+package test
+
+import (
+	"context"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/admin"
+)
+
+// MockAdminIF is a struct for mocking AdminIF
+type MockAdminIF struct {
+	Mock
+	admin.AdminIF
+}
+
+// ListDeadMessages mocks on AdminIF.ListDeadMessages
+func (m *MockAdminIF) ListDeadMessages(ctx context.Context, in *admin.ListDeadMessagesRequest) (*admin.ListDeadMessagesResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.ListDeadMessagesResponse), args.Error(1)
+}
+
+// PurgeDeadMessages mocks on AdminIF.PurgeDeadMessages
+func (m *MockAdminIF) PurgeDeadMessages(ctx context.Context, in *admin.PurgeDeadMessagesRequest) (*admin.PurgeDeadMessagesResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.PurgeDeadMessagesResponse), args.Error(1)
+}
+
+// ReplayDeadMessages mocks on AdminIF.ReplayDeadMessages
+func (m *MockAdminIF) ReplayDeadMessages(ctx context.Context, in *admin.ReplayDeadMessagesRequest) (*admin.ReplayDeadMessagesResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.ReplayDeadMessagesResponse), args.Error(1)
+}
+
+// CreateTopic mocks on AdminIF.CreateTopic
+func (m *MockAdminIF) CreateTopic(ctx context.Context, in *admin.CreateTopicRequest) (*admin.CreateTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.CreateTopicResponse), args.Error(1)
+}
+
+// UpdateTopicTTL mocks on AdminIF.UpdateTopicTTL
+func (m *MockAdminIF) UpdateTopicTTL(ctx context.Context, in *admin.UpdateTopicTTLRequest) (*admin.UpdateTopicTTLResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.UpdateTopicTTLResponse), args.Error(1)
+}
+
+// UpdateTopicLimits mocks on AdminIF.UpdateTopicLimits
+func (m *MockAdminIF) UpdateTopicLimits(ctx context.Context, in *admin.UpdateTopicLimitsRequest) (*admin.UpdateTopicLimitsResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.UpdateTopicLimitsResponse), args.Error(1)
+}
+
+// RenameTopic mocks on AdminIF.RenameTopic
+func (m *MockAdminIF) RenameTopic(ctx context.Context, in *admin.RenameTopicRequest) (*admin.RenameTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.RenameTopicResponse), args.Error(1)
+}
+
+// DeleteTopic mocks on AdminIF.DeleteTopic
+func (m *MockAdminIF) DeleteTopic(ctx context.Context, in *admin.DeleteTopicRequest) (*admin.DeleteTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.DeleteTopicResponse), args.Error(1)
+}
+
+// DescribeTopic mocks on AdminIF.DescribeTopic
+func (m *MockAdminIF) DescribeTopic(ctx context.Context, in *admin.DescribeTopicRequest) (*admin.DescribeTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.DescribeTopicResponse), args.Error(1)
+}
+
+// ListConsumerGroups mocks on AdminIF.ListConsumerGroups
+func (m *MockAdminIF) ListConsumerGroups(ctx context.Context, in *admin.ListConsumerGroupsRequest) (*admin.ListConsumerGroupsResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*admin.ListConsumerGroupsResponse), args.Error(1)
+}
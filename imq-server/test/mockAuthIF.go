@@ -0,0 +1,19 @@
+package test
+
+import (
+	"context"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/cmd/services/auth"
+)
+
+// MockAuthIF is a struct for mocking AuthIF
+type MockAuthIF struct {
+	Mock
+	auth.AuthIF
+}
+
+// Authenticate mocks on AuthIF.Authenticate
+func (m *MockAuthIF) Authenticate(ctx context.Context, in *auth.AuthenticateRequest) (*auth.AuthenticateResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*auth.AuthenticateResponse), args.Error(1)
+}
@@ -0,0 +1,31 @@
+package test
+
+import (
+	"context"
+
+	"github.com/WinnersonKharsunai/GraduationProject/server/internal/domain"
+)
+
+// MockClientServiceIF is a struct for mocking ClientServicesIF
+type MockClientServiceIF struct {
+	Mock
+	domain.ClientServicesIF
+}
+
+// Authenticate mocks on ClientServicesIF.Authenticate
+func (m *MockClientServiceIF) Authenticate(ctx context.Context, apiKey string) (*domain.Client, error) {
+	args := m.Called(ctx, apiKey)
+	return args.Get(0).(*domain.Client), args.Error(1)
+}
+
+// RegisterClient mocks on ClientServicesIF.RegisterClient
+func (m *MockClientServiceIF) RegisterClient(ctx context.Context, name string, role string, apiKey string) (*domain.Client, error) {
+	args := m.Called(ctx, name, role, apiKey)
+	return args.Get(0).(*domain.Client), args.Error(1)
+}
+
+// AuthenticateCertificate mocks on ClientServicesIF.AuthenticateCertificate
+func (m *MockClientServiceIF) AuthenticateCertificate(ctx context.Context, subject string) (*domain.Client, error) {
+	args := m.Called(ctx, subject)
+	return args.Get(0).(*domain.Client), args.Error(1)
+}
@@ -18,21 +18,27 @@ func (m *MockDatabaseIF) FetchAllTopics(ctx context.Context, id int) (*[]string,
 	return args.Get(0).(*[]string), args.Error(1)
 }
 
-// InsertPublisher mocks on DatabaseIF.InsertPublisher
-func (m *MockDatabaseIF) InsertPublisher(ctx context.Context, publisherID int, topicName string) error {
-	args := m.Called(ctx, publisherID, topicName)
+// GetPublishedTopics mocks on DatabaseIF.GetPublishedTopics
+func (m *MockDatabaseIF) GetPublishedTopics(ctx context.Context, publisherID int) ([]string, error) {
+	args := m.Called(ctx, publisherID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// InsertPublisherIDIntoPublisher mocks on DatabaseIF.InsertPublisherIDIntoPublisher
+func (m *MockDatabaseIF) InsertPublisherIDIntoPublisher(ctx context.Context, publisherID int) error {
+	args := m.Called(ctx, publisherID)
 	return args.Error(0)
 }
 
-// UpdateTopicIDIntoPublisher mocks on DatabaseIF.UpdateTopicIDIntoPublisher
-func (m *MockDatabaseIF) UpdateTopicIDIntoPublisher(ctx context.Context, publisherID int, topicID string) error {
+// InsertIntoPublisherTopicMap mocks on DatabaseIF.InsertIntoPublisherTopicMap
+func (m *MockDatabaseIF) InsertIntoPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
 	args := m.Called(ctx, publisherID, topicID)
 	return args.Error(0)
 }
 
-// RemoveTopicIDFromPublisher mocks on DatabaseIF.RemoveTopicIDFromPublisher
-func (m *MockDatabaseIF) RemoveTopicIDFromPublisher(ctx context.Context, publisherID int) error {
-	args := m.Called(ctx, publisherID)
+// RemoveTopicIDFromPublisherTopicMap mocks on DatabaseIF.RemoveTopicIDFromPublisherTopicMap
+func (m *MockDatabaseIF) RemoveTopicIDFromPublisherTopicMap(ctx context.Context, publisherID int, topicID string) error {
+	args := m.Called(ctx, publisherID, topicID)
 	return args.Error(0)
 }
 
@@ -42,12 +48,6 @@ func (m *MockDatabaseIF) FetchQueues(ctx context.Context) (*storage.Queue, error
 	return args.Get(0).(*storage.Queue), args.Error(1)
 }
 
-// GetTopicIDFromPublisher mocks on DatabaseIF.GetTopicIDFromPublisher
-func (m *MockDatabaseIF) GetTopicIDFromPublisher(ctx context.Context, publisherID int) (string, bool, error) {
-	args := m.Called(ctx, publisherID)
-	return args.String(0), args.Bool(1), args.Error(2)
-}
-
 // GetTopicIDFromTopic mocks on DatabaseIF.GetTopicIDFromTopic
 func (m *MockDatabaseIF) GetTopicIDFromTopic(ctx context.Context, topicName string) (string, error) {
 	args := m.Called(ctx, topicName)
@@ -60,6 +60,12 @@ func (m *MockDatabaseIF) InsertMessageIntoMessage(ctx context.Context, publisher
 	return args.Error(0)
 }
 
+// InsertMessagesIntoMessage mocks on DatabaseIF.InsertMessagesIntoMessage
+func (m *MockDatabaseIF) InsertMessagesIntoMessage(ctx context.Context, publisherID int, topicID string, messages []storage.Message) error {
+	args := m.Called(ctx, publisherID, topicID, messages)
+	return args.Error(0)
+}
+
 // GetSubscribedTopics mocks on DatabaseIF.GetSubscribedTopics
 func (m *MockDatabaseIF) GetSubscribedTopics(ctx context.Context, subscriberID int) ([]string, error) {
 	args := m.Called(ctx, subscriberID)
@@ -73,17 +79,53 @@ func (m *MockDatabaseIF) InsertSubscriberIDIntoSubscriber(ctx context.Context, s
 }
 
 // InsertIntoSubscriberTopicMap mocks on DatabaseIF.InsertIntoSubscriberTopicMap
-func (m *MockDatabaseIF) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error {
-	args := m.Called(ctx, subscriberID, topicID)
+func (m *MockDatabaseIF) InsertIntoSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string, subscription storage.Subscription) error {
+	args := m.Called(ctx, subscriberID, topicID, subscription)
 	return args.Error(0)
 }
 
+// GetSubscription mocks on DatabaseIF.GetSubscription
+func (m *MockDatabaseIF) GetSubscription(ctx context.Context, subscriberID int, topicID string) (*storage.Subscription, error) {
+	args := m.Called(ctx, subscriberID, topicID)
+	return args.Get(0).(*storage.Subscription), args.Error(1)
+}
+
+// GetConsumerGroups mocks on DatabaseIF.GetConsumerGroups
+func (m *MockDatabaseIF) GetConsumerGroups(ctx context.Context, topicID string) ([]storage.ConsumerGroupMember, error) {
+	args := m.Called(ctx, topicID)
+	return args.Get(0).([]storage.ConsumerGroupMember), args.Error(1)
+}
+
 // RemoveTopicIDFromSubscriberTopicMap mocks on DatabaseIF.RemoveTopicIDFromSubscriberTopicMap
 func (m *MockDatabaseIF) RemoveTopicIDFromSubscriberTopicMap(ctx context.Context, subscriberID int, topicID string) error {
 	args := m.Called(ctx, subscriberID, topicID)
 	return args.Error(0)
 }
 
+// GetSubscribedPatterns mocks on DatabaseIF.GetSubscribedPatterns
+func (m *MockDatabaseIF) GetSubscribedPatterns(ctx context.Context, subscriberID int) ([]string, error) {
+	args := m.Called(ctx, subscriberID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// GetSubscriptionPatterns mocks on DatabaseIF.GetSubscriptionPatterns
+func (m *MockDatabaseIF) GetSubscriptionPatterns(ctx context.Context) ([]storage.SubscriptionPattern, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]storage.SubscriptionPattern), args.Error(1)
+}
+
+// InsertIntoSubscriberPatternMap mocks on DatabaseIF.InsertIntoSubscriberPatternMap
+func (m *MockDatabaseIF) InsertIntoSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	args := m.Called(ctx, subscriberID, pattern)
+	return args.Error(0)
+}
+
+// RemovePatternFromSubscriberPatternMap mocks on DatabaseIF.RemovePatternFromSubscriberPatternMap
+func (m *MockDatabaseIF) RemovePatternFromSubscriberPatternMap(ctx context.Context, subscriberID int, pattern string) error {
+	args := m.Called(ctx, subscriberID, pattern)
+	return args.Error(0)
+}
+
 // SaveQueues mocks on DatabaseIF.SaveQueues
 func (m *MockDatabaseIF) SaveQueues(ctx context.Context, queue *[]storage.StoreQueue, isLiveQueue bool) error {
 	args := m.Called(ctx, queue, isLiveQueue)
@@ -95,3 +137,105 @@ func (m *MockDatabaseIF) RemoveMessagesFromQueue(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
+
+// FetchDeadQueues mocks on DatabaseIF.FetchDeadQueues
+func (m *MockDatabaseIF) FetchDeadQueues(ctx context.Context) (*storage.DeadQueue, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*storage.DeadQueue), args.Error(1)
+}
+
+// RemoveMessagesFromDLQ mocks on DatabaseIF.RemoveMessagesFromDLQ
+func (m *MockDatabaseIF) RemoveMessagesFromDLQ(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// FetchSubscriberOffsets mocks on DatabaseIF.FetchSubscriberOffsets
+func (m *MockDatabaseIF) FetchSubscriberOffsets(ctx context.Context) (*[]storage.SubscriberOffset, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*[]storage.SubscriberOffset), args.Error(1)
+}
+
+// SaveSubscriberOffsets mocks on DatabaseIF.SaveSubscriberOffsets
+func (m *MockDatabaseIF) SaveSubscriberOffsets(ctx context.Context, offsets *[]storage.SubscriberOffset) error {
+	args := m.Called(ctx, offsets)
+	return args.Error(0)
+}
+
+// RemoveSubscriberOffsets mocks on DatabaseIF.RemoveSubscriberOffsets
+func (m *MockDatabaseIF) RemoveSubscriberOffsets(ctx context.Context) error {
+	args := m.Called(ctx)
+	return args.Error(0)
+}
+
+// InsertTopic mocks on DatabaseIF.InsertTopic
+func (m *MockDatabaseIF) InsertTopic(ctx context.Context, topicID string, topicName string, partitions int, ttl storage.TopicTTL, limits storage.TopicLimits) error {
+	args := m.Called(ctx, topicID, topicName, partitions, ttl, limits)
+	return args.Error(0)
+}
+
+// FetchTopicPartitions mocks on DatabaseIF.FetchTopicPartitions
+func (m *MockDatabaseIF) FetchTopicPartitions(ctx context.Context) (map[string]int, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// RenameTopic mocks on DatabaseIF.RenameTopic
+func (m *MockDatabaseIF) RenameTopic(ctx context.Context, topicID string, topicName string) error {
+	args := m.Called(ctx, topicID, topicName)
+	return args.Error(0)
+}
+
+// DeleteTopic mocks on DatabaseIF.DeleteTopic
+func (m *MockDatabaseIF) DeleteTopic(ctx context.Context, topicID string) error {
+	args := m.Called(ctx, topicID)
+	return args.Error(0)
+}
+
+// GetTopicStats mocks on DatabaseIF.GetTopicStats
+func (m *MockDatabaseIF) GetTopicStats(ctx context.Context, topicID string) (*storage.TopicStats, error) {
+	args := m.Called(ctx, topicID)
+	return args.Get(0).(*storage.TopicStats), args.Error(1)
+}
+
+// GetTopicTTL mocks on DatabaseIF.GetTopicTTL
+func (m *MockDatabaseIF) GetTopicTTL(ctx context.Context, topicID string) (*storage.TopicTTL, error) {
+	args := m.Called(ctx, topicID)
+	return args.Get(0).(*storage.TopicTTL), args.Error(1)
+}
+
+// UpdateTopicTTL mocks on DatabaseIF.UpdateTopicTTL
+func (m *MockDatabaseIF) UpdateTopicTTL(ctx context.Context, topicID string, ttl storage.TopicTTL) error {
+	args := m.Called(ctx, topicID, ttl)
+	return args.Error(0)
+}
+
+// FetchTopicLimits mocks on DatabaseIF.FetchTopicLimits
+func (m *MockDatabaseIF) FetchTopicLimits(ctx context.Context) (map[string]storage.TopicLimits, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string]storage.TopicLimits), args.Error(1)
+}
+
+// UpdateTopicLimits mocks on DatabaseIF.UpdateTopicLimits
+func (m *MockDatabaseIF) UpdateTopicLimits(ctx context.Context, topicID string, limits storage.TopicLimits) error {
+	args := m.Called(ctx, topicID, limits)
+	return args.Error(0)
+}
+
+// InsertClient mocks on DatabaseIF.InsertClient
+func (m *MockDatabaseIF) InsertClient(ctx context.Context, client storage.Client) (int, error) {
+	args := m.Called(ctx, client)
+	return args.Int(0), args.Error(1)
+}
+
+// GetClientByAPIKey mocks on DatabaseIF.GetClientByAPIKey
+func (m *MockDatabaseIF) GetClientByAPIKey(ctx context.Context, apiKeyHash string) (*storage.Client, bool, error) {
+	args := m.Called(ctx, apiKeyHash)
+	return args.Get(0).(*storage.Client), args.Bool(1), args.Error(2)
+}
+
+// GetClientByName mocks on DatabaseIF.GetClientByName
+func (m *MockDatabaseIF) GetClientByName(ctx context.Context, name string) (*storage.Client, bool, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(*storage.Client), args.Bool(1), args.Error(2)
+}
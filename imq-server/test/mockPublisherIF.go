@@ -35,3 +35,9 @@ func (m *MockPublisherIF) PublishMessage(ctx context.Context, in *publisher.Publ
 	args := m.Called(ctx, in)
 	return args.Get(0).(*publisher.PublishMessageResponse), args.Error(1)
 }
+
+// PublishBatch mocks on PublisherIF.PublishBatch
+func (m *MockPublisherIF) PublishBatch(ctx context.Context, in *publisher.PublishBatchRequest) (*publisher.PublishBatchResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*publisher.PublishBatchResponse), args.Error(1)
+}
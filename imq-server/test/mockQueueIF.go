@@ -19,7 +19,111 @@ func (mk *MockQueueIF) SendMessage(ctx context.Context, message queue.SendMessag
 }
 
 // RetrieveMessage mocks on ImqQueueIF.RetrieveMessage
-func (mk *MockQueueIF) RetrieveMessage(ctx context.Context, topicID string) (*queue.Message, error) {
-	args := mk.Called(ctx, topicID)
+func (mk *MockQueueIF) RetrieveMessage(ctx context.Context, topicID string, subscriberID int) (*queue.Message, error) {
+	args := mk.Called(ctx, topicID, subscriberID)
 	return args.Get(0).(*queue.Message), args.Error(1)
 }
+
+// SendMessages mocks on ImqQueueIF.SendMessages
+func (mk *MockQueueIF) SendMessages(ctx context.Context, topicID string, messages []queue.Message) []error {
+	args := mk.Called(ctx, topicID, messages)
+	return args.Get(0).([]error)
+}
+
+// RetrieveMessages mocks on ImqQueueIF.RetrieveMessages
+func (mk *MockQueueIF) RetrieveMessages(ctx context.Context, topicID string, subscriberID int, maxCount int, maxBytes int) ([]queue.Message, error) {
+	args := mk.Called(ctx, topicID, subscriberID, maxCount, maxBytes)
+	return args.Get(0).([]queue.Message), args.Error(1)
+}
+
+// AddSubscriber mocks on ImqQueueIF.AddSubscriber
+func (mk *MockQueueIF) AddSubscriber(topicID string, subscriberID int) {
+	mk.Called(topicID, subscriberID)
+}
+
+// RemoveSubscriber mocks on ImqQueueIF.RemoveSubscriber
+func (mk *MockQueueIF) RemoveSubscriber(topicID string, subscriberID int) {
+	mk.Called(topicID, subscriberID)
+}
+
+// Watch mocks on ImqQueueIF.Watch
+func (mk *MockQueueIF) Watch(topicID string, subscriberID int, fn func(queue.Message) error) {
+	mk.Called(topicID, subscriberID, fn)
+}
+
+// Unwatch mocks on ImqQueueIF.Unwatch
+func (mk *MockQueueIF) Unwatch(topicID string, subscriberID int) {
+	mk.Called(topicID, subscriberID)
+}
+
+// SetFilter mocks on ImqQueueIF.SetFilter
+func (mk *MockQueueIF) SetFilter(topicID string, subscriberID int, filter func(queue.Message) bool) {
+	mk.Called(topicID, subscriberID, filter)
+}
+
+// SetPartitions mocks on ImqQueueIF.SetPartitions
+func (mk *MockQueueIF) SetPartitions(topicID string, partitions int) {
+	mk.Called(topicID, partitions)
+}
+
+// SetLimits mocks on ImqQueueIF.SetLimits
+func (mk *MockQueueIF) SetLimits(topicID string, limits queue.Limits) {
+	mk.Called(topicID, limits)
+}
+
+// JoinGroup mocks on ImqQueueIF.JoinGroup
+func (mk *MockQueueIF) JoinGroup(topicID string, subscriberID int, group string) {
+	mk.Called(topicID, subscriberID, group)
+}
+
+// RemoveGroup mocks on ImqQueueIF.RemoveGroup
+func (mk *MockQueueIF) RemoveGroup(topicID string, group string) {
+	mk.Called(topicID, group)
+}
+
+// DescribeGroup mocks on ImqQueueIF.DescribeGroup
+func (mk *MockQueueIF) DescribeGroup(topicID string, group string) queue.GroupStats {
+	args := mk.Called(topicID, group)
+	return args.Get(0).(queue.GroupStats)
+}
+
+// AckMessage mocks on ImqQueueIF.AckMessage
+func (mk *MockQueueIF) AckMessage(topicID string, subscriberID int, messageID string) error {
+	args := mk.Called(topicID, subscriberID, messageID)
+	return args.Error(0)
+}
+
+// NackMessage mocks on ImqQueueIF.NackMessage
+func (mk *MockQueueIF) NackMessage(topicID string, subscriberID int, messageID string) error {
+	args := mk.Called(topicID, subscriberID, messageID)
+	return args.Error(0)
+}
+
+// ListDeadMessages mocks on ImqQueueIF.ListDeadMessages
+func (mk *MockQueueIF) ListDeadMessages(topicID string) []queue.DeadMessage {
+	args := mk.Called(topicID)
+	return args.Get(0).([]queue.DeadMessage)
+}
+
+// PurgeDeadMessages mocks on ImqQueueIF.PurgeDeadMessages
+func (mk *MockQueueIF) PurgeDeadMessages(topicID string, messageID string) int {
+	args := mk.Called(topicID, messageID)
+	return args.Int(0)
+}
+
+// ReplayDeadMessages mocks on ImqQueueIF.ReplayDeadMessages
+func (mk *MockQueueIF) ReplayDeadMessages(topicID string, messageID string) int {
+	args := mk.Called(topicID, messageID)
+	return args.Int(0)
+}
+
+// DescribeTopic mocks on ImqQueueIF.DescribeTopic
+func (mk *MockQueueIF) DescribeTopic(topicID string) queue.TopicStats {
+	args := mk.Called(topicID)
+	return args.Get(0).(queue.TopicStats)
+}
+
+// RemoveTopic mocks on ImqQueueIF.RemoveTopic
+func (mk *MockQueueIF) RemoveTopic(topicID string) {
+	mk.Called(topicID)
+}
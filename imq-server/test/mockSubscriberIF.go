@@ -41,3 +41,33 @@ func (m *MockSubscriberIF) GetMessageFromTopic(ctx context.Context, in *subscrib
 	args := m.Called(ctx, in)
 	return args.Get(0).(*subscriber.GetMessageFromTopicResponse), args.Error(1)
 }
+
+// GetMessagesFromTopic mocks on SubscriberIF.GetMessagesFromTopic
+func (m *MockSubscriberIF) GetMessagesFromTopic(ctx context.Context, in *subscriber.GetMessagesFromTopicRequest) (*subscriber.GetMessagesFromTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*subscriber.GetMessagesFromTopicResponse), args.Error(1)
+}
+
+// WatchTopic mocks on SubscriberIF.WatchTopic
+func (m *MockSubscriberIF) WatchTopic(ctx context.Context, in *subscriber.WatchTopicRequest, fn func(subscriber.MessagePush) error) (*subscriber.WatchTopicResponse, error) {
+	args := m.Called(ctx, in, fn)
+	return args.Get(0).(*subscriber.WatchTopicResponse), args.Error(1)
+}
+
+// UnwatchTopic mocks on SubscriberIF.UnwatchTopic
+func (m *MockSubscriberIF) UnwatchTopic(ctx context.Context, in *subscriber.UnwatchTopicRequest) (*subscriber.UnwatchTopicResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*subscriber.UnwatchTopicResponse), args.Error(1)
+}
+
+// AckMessage mocks on SubscriberIF.AckMessage
+func (m *MockSubscriberIF) AckMessage(ctx context.Context, in *subscriber.AckMessageRequest) (*subscriber.AckMessageResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*subscriber.AckMessageResponse), args.Error(1)
+}
+
+// NackMessage mocks on SubscriberIF.NackMessage
+func (m *MockSubscriberIF) NackMessage(ctx context.Context, in *subscriber.NackMessageRequest) (*subscriber.NackMessageResponse, error) {
+	args := m.Called(ctx, in)
+	return args.Get(0).(*subscriber.NackMessageResponse), args.Error(1)
+}
@@ -25,17 +25,29 @@ func (m *MockTopicServiceIF) RegisterPublisherToTopic(ctx context.Context, publi
 }
 
 // DeregisterPublisherFromTopic mocks on TopicServiceIF.DeregisterPublisherFromTopic
-func (m *MockTopicServiceIF) DeregisterPublisherFromTopic(ctx context.Context, publisherID int) error {
-	args := m.Called(ctx, publisherID)
+func (m *MockTopicServiceIF) DeregisterPublisherFromTopic(ctx context.Context, publisherID int, topicName string) error {
+	args := m.Called(ctx, publisherID, topicName)
 	return args.Error(0)
 }
 
 // AddMessageToTopic mocks on TopicServiceIF.AddMessageToTopic
-func (m *MockTopicServiceIF) AddMessageToTopic(ctx context.Context, publisherID int, message domain.Message) error {
-	args := m.Called(ctx, publisherID, message)
+func (m *MockTopicServiceIF) AddMessageToTopic(ctx context.Context, publisherID int, topicName string, message domain.Message) error {
+	args := m.Called(ctx, publisherID, topicName, message)
 	return args.Error(0)
 }
 
+// AddMessagesToTopic mocks on TopicServiceIF.AddMessagesToTopic
+func (m *MockTopicServiceIF) AddMessagesToTopic(ctx context.Context, publisherID int, topicName string, messages []domain.Message) ([]error, error) {
+	args := m.Called(ctx, publisherID, topicName, messages)
+	return args.Get(0).([]error), args.Error(1)
+}
+
+// GetMessages mocks on TopicServiceIF.GetMessages
+func (m *MockTopicServiceIF) GetMessages(ctx context.Context, subscriberID int, topicName string, maxCount int, maxBytes int) ([]domain.Message, error) {
+	args := m.Called(ctx, subscriberID, topicName, maxCount, maxBytes)
+	return args.Get(0).([]domain.Message), args.Error(1)
+}
+
 // GetMessage mocks on TopicServiceIF.GetMessage
 func (m *MockTopicServiceIF) GetMessage(ctx context.Context, subscriberID int, topicName string) (*domain.Message, error) {
 	args := m.Called(ctx, subscriberID, topicName)
@@ -43,8 +55,8 @@ func (m *MockTopicServiceIF) GetMessage(ctx context.Context, subscriberID int, t
 }
 
 // RegisterSubscriberToTopic mocks on TopicServiceIF.RegisterSubscriberToTopic
-func (m *MockTopicServiceIF) RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string) error {
-	args := m.Called(ctx, subscriberID, topicName)
+func (m *MockTopicServiceIF) RegisterSubscriberToTopic(ctx context.Context, subscriberID int, topicName string, subscription domain.Subscription) error {
+	args := m.Called(ctx, subscriberID, topicName, subscription)
 	return args.Error(0)
 }
 
@@ -59,3 +71,87 @@ func (m *MockTopicServiceIF) GetRegisteredTopic(ctx context.Context, subscriberI
 	args := m.Called(ctx, subscriberID)
 	return args.Get(0).(*[]string), args.Error(1)
 }
+
+// WatchTopic mocks on TopicServiceIF.WatchTopic
+func (m *MockTopicServiceIF) WatchTopic(ctx context.Context, subscriberID int, topicName string, fn func(domain.Message) error) error {
+	args := m.Called(ctx, subscriberID, topicName, fn)
+	return args.Error(0)
+}
+
+// UnwatchTopic mocks on TopicServiceIF.UnwatchTopic
+func (m *MockTopicServiceIF) UnwatchTopic(ctx context.Context, subscriberID int, topicName string) error {
+	args := m.Called(ctx, subscriberID, topicName)
+	return args.Error(0)
+}
+
+// AckMessage mocks on TopicServiceIF.AckMessage
+func (m *MockTopicServiceIF) AckMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error {
+	args := m.Called(ctx, subscriberID, topicName, messageID)
+	return args.Error(0)
+}
+
+// NackMessage mocks on TopicServiceIF.NackMessage
+func (m *MockTopicServiceIF) NackMessage(ctx context.Context, subscriberID int, topicName string, messageID string) error {
+	args := m.Called(ctx, subscriberID, topicName, messageID)
+	return args.Error(0)
+}
+
+// ListDeadMessages mocks on TopicServiceIF.ListDeadMessages
+func (m *MockTopicServiceIF) ListDeadMessages(ctx context.Context, topicName string) ([]domain.DeadMessage, error) {
+	args := m.Called(ctx, topicName)
+	return args.Get(0).([]domain.DeadMessage), args.Error(1)
+}
+
+// PurgeDeadMessages mocks on TopicServiceIF.PurgeDeadMessages
+func (m *MockTopicServiceIF) PurgeDeadMessages(ctx context.Context, topicName string, messageID string) (int, error) {
+	args := m.Called(ctx, topicName, messageID)
+	return args.Int(0), args.Error(1)
+}
+
+// ReplayDeadMessages mocks on TopicServiceIF.ReplayDeadMessages
+func (m *MockTopicServiceIF) ReplayDeadMessages(ctx context.Context, topicName string, messageID string) (int, error) {
+	args := m.Called(ctx, topicName, messageID)
+	return args.Int(0), args.Error(1)
+}
+
+// CreateTopic mocks on TopicServiceIF.CreateTopic
+func (m *MockTopicServiceIF) CreateTopic(ctx context.Context, topicID string, topicName string, ttl domain.TopicTTL, partitions int, limits domain.TopicLimits) error {
+	args := m.Called(ctx, topicID, topicName, ttl, partitions, limits)
+	return args.Error(0)
+}
+
+// UpdateTopicTTL mocks on TopicServiceIF.UpdateTopicTTL
+func (m *MockTopicServiceIF) UpdateTopicTTL(ctx context.Context, topicName string, ttl domain.TopicTTL) error {
+	args := m.Called(ctx, topicName, ttl)
+	return args.Error(0)
+}
+
+// UpdateTopicLimits mocks on TopicServiceIF.UpdateTopicLimits
+func (m *MockTopicServiceIF) UpdateTopicLimits(ctx context.Context, topicName string, limits domain.TopicLimits) error {
+	args := m.Called(ctx, topicName, limits)
+	return args.Error(0)
+}
+
+// RenameTopic mocks on TopicServiceIF.RenameTopic
+func (m *MockTopicServiceIF) RenameTopic(ctx context.Context, topicName string, newTopicName string) error {
+	args := m.Called(ctx, topicName, newTopicName)
+	return args.Error(0)
+}
+
+// DeleteTopic mocks on TopicServiceIF.DeleteTopic
+func (m *MockTopicServiceIF) DeleteTopic(ctx context.Context, topicName string, force bool) error {
+	args := m.Called(ctx, topicName, force)
+	return args.Error(0)
+}
+
+// DescribeTopic mocks on TopicServiceIF.DescribeTopic
+func (m *MockTopicServiceIF) DescribeTopic(ctx context.Context, topicName string) (*domain.TopicDescription, error) {
+	args := m.Called(ctx, topicName)
+	return args.Get(0).(*domain.TopicDescription), args.Error(1)
+}
+
+// ListConsumerGroups mocks on TopicServiceIF.ListConsumerGroups
+func (m *MockTopicServiceIF) ListConsumerGroups(ctx context.Context, topicName string) ([]domain.ConsumerGroup, error) {
+	args := m.Called(ctx, topicName)
+	return args.Get(0).([]domain.ConsumerGroup), args.Error(1)
+}